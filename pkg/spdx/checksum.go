@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// checksumAlgorithms are the algorithms allowed by the SPDX spec mapped
+// to the length of their hex representation. A zero length means the
+// algorithm produces digests of variable length.
+var checksumAlgorithms = map[string]int{
+	"ADLER32":     8,
+	"BLAKE2b-256": 64,
+	"BLAKE2b-384": 96,
+	"BLAKE2b-512": 128,
+	"BLAKE3":      0,
+	"MD2":         32,
+	"MD4":         32,
+	"MD5":         32,
+	"MD6":         0,
+	"SHA1":        40,
+	"SHA224":      56,
+	"SHA256":      64,
+	"SHA384":      96,
+	"SHA512":      128,
+	"SHA3-256":    64,
+	"SHA3-384":    96,
+	"SHA3-512":    128,
+}
+
+// checksumHashes are the SPDX checksum algorithms that can be computed
+var checksumHashes = map[string]func() hash.Hash{
+	"MD5":    md5.New,
+	"SHA1":   sha1.New,
+	"SHA224": sha256.New224,
+	"SHA256": sha256.New,
+	"SHA384": sha512.New384,
+	"SHA512": sha512.New,
+}
+
+// ChecksumMismatchError is returned when the checksum computed over a
+// file is not the expected one
+type ChecksumMismatchError struct {
+	Name      string // Name of the file
+	Algorithm string // Algorithm of the checksum
+	Expected  string // Expected checksum value
+	Actual    string // Checksum computed over the file
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf(
+		"%s checksum mismatch for %s: expected %s, got %s", e.Algorithm, e.Name, e.Expected, e.Actual,
+	)
+}
+
+// checksumAlgorithmOrder is the order checksums are rendered in, other
+// algorithms follow sorted by name
+var checksumAlgorithmOrder = []string{
+	"MD5", "SHA1", "SHA224", "SHA256", "SHA384", "SHA512", "SHA3-256", "SHA3-384", "SHA3-512",
+}
+
+// sortedChecksumAlgorithms returns the algorithms of checksums in the
+// rendering order, so the output is the same on every run
+func sortedChecksumAlgorithms(checksums map[string]string) []string {
+	rank := func(algorithm string) int {
+		for i, a := range checksumAlgorithmOrder {
+			if a == algorithm {
+				return i
+			}
+		}
+		return len(checksumAlgorithmOrder)
+	}
+	algorithms := []string{}
+	for algorithm := range checksums {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Slice(algorithms, func(i, j int) bool {
+		ri, rj := rank(algorithms[i]), rank(algorithms[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return algorithms[i] < algorithms[j]
+	})
+	return algorithms
+}
+
+// validateChecksum checks that algorithm is supported by SPDX and that
+// value is a hex string of the expected length for it
+func validateChecksum(algorithm, value string) error {
+	length, ok := checksumAlgorithms[algorithm]
+	if !ok {
+		return errors.Errorf("%s is not a valid SPDX checksum algorithm", algorithm)
+	}
+	if _, err := hex.DecodeString(value); err != nil || value == "" {
+		return errors.Errorf("%s checksum value is not a valid hex string", algorithm)
+	}
+	if length != 0 && len(value) != length {
+		return errors.Errorf(
+			"%s checksum must be %d characters long, got %d", algorithm, length, len(value),
+		)
+	}
+	return nil
+}
+
+// checksumsForBytes returns the checksums we compute for files
+// calculated over data
+func checksumsForBytes(data []byte) map[string]string {
+	return map[string]string{
+		"SHA1":   fmt.Sprintf("%x", sha1.Sum(data)),
+		"SHA256": fmt.Sprintf("%x", sha256.Sum256(data)),
+		"SHA512": fmt.Sprintf("%x", sha512.Sum512(data)),
+	}
+}
+
+// errMmapUnsupported is returned by mmapFile on platforms without
+// memory mapped files
+var errMmapUnsupported = errors.New("memory mapped files are not supported on this platform")
+
+// checksumsForMappedFile returns the checksums we compute for files,
+// hashing the file at path through a memory map. It returns false if
+// memory maps are not supported on the platform.
+func checksumsForMappedFile(path string) (map[string]string, bool, error) {
+	data, unmap, err := mmapFile(path)
+	if err == errMmapUnsupported {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, true, errors.Wrap(err, "mapping file in memory")
+	}
+	defer unmap()
+	return checksumsForBytes(data), true, nil
+}
+
+// normalizeChecksums returns the checksums with their values converted to
+// lowercase hex, the form recommended by the SPDX spec
+func normalizeChecksums(checksums map[string]string) map[string]string {
+	normalized := make(map[string]string, len(checksums))
+	for algorithm, value := range checksums {
+		normalized[algorithm] = strings.ToLower(value)
+	}
+	return normalized
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	arMagic          = "!<arch>\n"
+	arHeaderLength   = 60
+	debControlMember = "control.tar"
+	debControlFile   = "control"
+	debXzExt         = ".xz"
+	debZstdExt       = ".zst"
+	debPurlNamespace = "debian"
+	maintainerRe     = `^\s*([^<]*?)\s*<([^>]+)>\s*$`
+)
+
+// FromDeb builds a SPDX package from a debian package (.deb) file. The
+// package data is read from the control file in the package archive.
+func FromDeb(path string) (*Package, error) {
+	control, err := readDebControl(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading debian control file")
+	}
+
+	if control["Package"] == "" {
+		return nil, errors.New("debian control file does not define a package name")
+	}
+
+	pkg := NewPackage()
+	pkg.Options().WorkDir = filepath.Dir(path)
+	if err := pkg.ReadSourceFile(path); err != nil {
+		return nil, errors.Wrap(err, "reading debian package source file")
+	}
+	pkg.Name = control["Package"]
+	pkg.Version = control["Version"]
+	pkg.Supplier.Person = formatMaintainer(control["Maintainer"])
+	pkg.LicenseDeclared = control["License"]
+	pkg.HomePage = control["Homepage"]
+	pkg.SourceInfo = "acquired package info from the control file of " + filepath.Base(path)
+	pkg.AddPackageURL(buildPackageURL(
+		"deb", debPurlNamespace, pkg.Name, pkg.Version,
+		map[string]string{"arch": control["Architecture"]},
+	))
+	return pkg, nil
+}
+
+// readDebControl extracts the control file from a .deb archive and
+// returns its fields in a map. The control tarball can be uncompressed
+// or compressed with gzip, xz or zstd.
+func readDebControl(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening debian package")
+	}
+	defer f.Close()
+
+	// A .deb file is an ar archive, check the magic string first
+	magic := make([]byte, len(arMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return nil, errors.Wrap(err, "reading ar archive magic")
+	}
+	if string(magic) != arMagic {
+		return nil, errors.New("file is not an ar archive")
+	}
+
+	for {
+		header := make([]byte, arHeaderLength)
+		if _, err := io.ReadFull(f, header); err != nil {
+			if err == io.EOF {
+				return nil, errors.New("control archive not found in debian package")
+			}
+			return nil, errors.Wrap(err, "reading ar member header")
+		}
+		name := strings.TrimSuffix(strings.TrimSpace(string(header[0:16])), "/")
+		size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing size of ar member %s", name)
+		}
+
+		if !strings.HasPrefix(name, debControlMember) {
+			// Members are aligned to an even offset
+			if _, err := f.Seek(size+size%2, io.SeekCurrent); err != nil {
+				return nil, errors.Wrap(err, "skipping ar member")
+			}
+			continue
+		}
+
+		logrus.Debugf("Reading debian control data from %s", name)
+		var r io.Reader = io.LimitReader(f, size)
+		switch filepath.Ext(name) {
+		case gzExt:
+			gzf, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, errors.Wrap(err, "creating gzip reader")
+			}
+			defer gzf.Close()
+			r = gzf
+		case debXzExt:
+			xzr, err := xz.NewReader(r)
+			if err != nil {
+				return nil, errors.Wrap(err, "creating xz reader")
+			}
+			r = xzr
+		case debZstdExt:
+			zr, err := zstd.NewReader(r)
+			if err != nil {
+				return nil, errors.Wrap(err, "creating zstd reader")
+			}
+			defer zr.Close()
+			r = zr
+		case ".tar":
+		default:
+			return nil, errors.Errorf("unsupported control archive compression: %s", name)
+		}
+		return readControlFromTar(r)
+	}
+}
+
+// readControlFromTar finds the control file in the control tarball
+// and parses it
+func readControlFromTar(r io.Reader) (map[string]string, error) {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.New("control file not found in control archive")
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "reading control archive")
+		}
+		if filepath.Clean(hdr.Name) != debControlFile {
+			continue
+		}
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, tr); err != nil {
+			return nil, errors.Wrap(err, "extracting control file")
+		}
+		return parseControlFile(&buf), nil
+	}
+}
+
+// parseControlFile reads a deb822 control file into a map. Only the
+// first line of multiline fields is kept as the value.
+func parseControlFile(r io.Reader) map[string]string {
+	fields := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Continuation lines start with whitespace
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		fields[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return fields
+}
+
+// formatMaintainer converts a maintainer string from the
+// "Name <email>" form to the SPDX "Name (email)" form
+func formatMaintainer(maintainer string) string {
+	m := regexp.MustCompile(maintainerRe).FindStringSubmatch(maintainer)
+	if m == nil {
+		return strings.TrimSpace(maintainer)
+	}
+	if m[1] == "" {
+		return m[2]
+	}
+	return m[1] + " (" + m[2] + ")"
+}
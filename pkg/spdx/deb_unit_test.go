@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromDeb(t *testing.T) {
+	_, err := FromDeb("testdata/non-existent.deb")
+	require.NotNil(t, err)
+
+	pkg, err := FromDeb("testdata/hello_1.0.0-1_amd64.deb")
+	require.Nil(t, err)
+	require.NotNil(t, pkg)
+
+	require.Equal(t, "hello", pkg.Name)
+	require.Equal(t, "1.0.0-1", pkg.Version)
+	require.Equal(t, "Jane Doe (jane@example.com)", pkg.Supplier.Person)
+	require.Equal(t, "MIT", pkg.LicenseDeclared)
+	require.Equal(t, "./hello_1.0.0-1_amd64.deb", pkg.FileName)
+	require.NotEmpty(t, pkg.Checksum["SHA256"])
+	require.Len(t, pkg.ExternalRefs, 1)
+	require.Equal(t, "pkg:deb/debian/hello@1.0.0-1?arch=amd64", pkg.ExternalRefs[0].Locator)
+}
+
+func TestFromDebCompressedControl(t *testing.T) {
+	// The control archive can also be compressed with xz or zstd
+	for _, path := range []string{
+		"testdata/hello_1.0.0-1_amd64-xz.deb",
+		"testdata/hello_1.0.0-1_amd64-zst.deb",
+	} {
+		pkg, err := FromDeb(path)
+		require.Nil(t, err, path)
+		require.Equal(t, "hello", pkg.Name)
+		require.Equal(t, "1.0.0-1", pkg.Version)
+		require.Equal(t, "MIT", pkg.LicenseDeclared)
+		require.Equal(t, "pkg:deb/debian/hello@1.0.0-1?arch=amd64", pkg.ExternalRefs[0].Locator)
+	}
+}
+
+func TestFormatMaintainer(t *testing.T) {
+	for _, tc := range []struct {
+		maintainer string
+		expected   string
+	}{
+		{"Jane Doe <jane@example.com>", "Jane Doe (jane@example.com)"},
+		{"<jane@example.com>", "jane@example.com"},
+		{"Jane Doe", "Jane Doe"},
+		{"", ""},
+	} {
+		require.Equal(t, tc.expected, formatMaintainer(tc.maintainer))
+	}
+}
@@ -0,0 +1,350 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	gitignore "github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/pkg/errors"
+)
+
+// SymlinkPolicy controls how symbolic links are handled when
+// reading the files of a directory into a package
+type SymlinkPolicy int
+
+const (
+	// SymlinkIgnore skips symbolic links (default)
+	SymlinkIgnore SymlinkPolicy = iota
+
+	// SymlinkRecord adds links as zero byte files noting their target
+	SymlinkRecord
+
+	// SymlinkFollowWithinRoot reads the target of links pointing to
+	// files inside the directory. Links to directories or to paths
+	// outside the directory are skipped.
+	SymlinkFollowWithinRoot
+)
+
+// ReadDirectory adds all the files in dirPath to the package. File names
+// are recorded relative to the directory. Symbolic links are handled
+// according to the SymlinkPolicy in the package options and files are
+// hashed by as many workers as set in the Concurrency option. Files and
+// directories matching the ExcludeGlobs option are not read.
+func (p *Package) ReadDirectory(dirPath string) error {
+	return p.readDirectory(dirPath, nil, nil)
+}
+
+// readDirectory adds the files in dirPath to the package like
+// ReadDirectory. Files and directories matching patterns are skipped
+// in addition to the ExcludeGlobs, and process, if set, is called by
+// the workers on each file read before it is added.
+func (p *Package) readDirectory(dirPath string, patterns []gitignore.Pattern, process func(*File) error) error {
+	files, err := p.readDirectoryFiles(dirPath, patterns, process)
+	if err != nil {
+		return err
+	}
+	// A package read from a directory is not backed by a single file,
+	// empty directories have no files to analyze
+	p.FilesAnalyzed = len(files) > 0 || len(p.Files) > 0
+	p.FileName = ""
+	p.ArchiveFileName = ""
+	return errors.Wrap(p.AddFiles(files), "adding directory files to package")
+}
+
+// IngestDirectoryGrouped reads the files in dirPath like ReadDirectory,
+// but groups them in a subpackage for each directory up to depth levels
+// below dirPath. Subpackages of nested directories are contained in the
+// subpackage of their parent, and files deeper than depth go to the
+// subpackage of their ancestor at that depth. File names are always
+// relative to dirPath. A depth of zero reads a flat list of files.
+func (p *Package) IngestDirectoryGrouped(dirPath string, depth int) error {
+	if depth <= 0 {
+		return p.ReadDirectory(dirPath)
+	}
+	files, err := p.readDirectoryFiles(dirPath, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	scope := p.ID
+	if scope == "" {
+		scope = p.Name
+	}
+	groups := map[string]*Package{}
+	groupFiles := map[string][]*File{}
+	var group func(dir string) (*Package, error)
+	group = func(dir string) (*Package, error) {
+		if pkg, ok := groups[dir]; ok {
+			return pkg, nil
+		}
+		parent := p
+		if i := strings.LastIndex(dir, "/"); i != -1 {
+			if parent, err = group(dir[:i]); err != nil {
+				return nil, err
+			}
+		}
+		pkg := NewPackage()
+		pkg.Name = dir
+		pkg.ID = fmt.Sprintf("SPDXRef-Package-%x", sha1.Sum([]byte(scope+":"+dir)))
+		if err := parent.AddPackage(pkg); err != nil {
+			return nil, errors.Wrapf(err, "adding package of directory %s", dir)
+		}
+		groups[dir] = pkg
+		return pkg, nil
+	}
+
+	rootFiles := []*File{}
+	for _, f := range files {
+		parts := strings.Split(strings.TrimPrefix(f.Name, "./"), "/")
+		parts = parts[:len(parts)-1]
+		if len(parts) == 0 {
+			rootFiles = append(rootFiles, f)
+			continue
+		}
+		if len(parts) > depth {
+			parts = parts[:depth]
+		}
+		dir := strings.Join(parts, "/")
+		if _, err := group(dir); err != nil {
+			return err
+		}
+		groupFiles[dir] = append(groupFiles[dir], f)
+	}
+
+	// Packages of directories holding only other directories have no
+	// files to analyze
+	for dir, pkg := range groups {
+		pkg.FilesAnalyzed = len(groupFiles[dir]) > 0
+		if err := pkg.AddFiles(groupFiles[dir]); err != nil {
+			return errors.Wrapf(err, "adding files to package of directory %s", dir)
+		}
+	}
+	p.FilesAnalyzed = len(rootFiles) > 0 || len(p.Files) > 0
+	p.FileName = ""
+	p.ArchiveFileName = ""
+	return errors.Wrap(p.AddFiles(rootFiles), "adding directory files to package")
+}
+
+// readDirectoryFiles builds the SPDX files of all the files in dirPath
+// not matching the ExcludeGlobs or patterns, calling process on each
+func (p *Package) readDirectoryFiles(
+	dirPath string, patterns []gitignore.Pattern, process func(*File) error,
+) ([]*File, error) {
+	root, err := filepath.Abs(dirPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting absolute directory path")
+	}
+	// Resolve the root itself in case it is reached through a link
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving directory path")
+	}
+
+	var excluded gitignore.Matcher
+	patterns = append([]gitignore.Pattern{}, patterns...)
+	for _, glob := range p.Options().ExcludeGlobs {
+		patterns = append(patterns, gitignore.ParsePattern(glob, nil))
+	}
+	if len(patterns) > 0 {
+		excluded = gitignore.NewMatcher(patterns)
+	}
+
+	entries := []directoryEntry{}
+	if err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if excluded != nil && path != root {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			// Skip excluded directories whole instead of their files
+			if excluded.Match(strings.Split(filepath.ToSlash(rel), "/"), d.IsDir()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if !d.IsDir() {
+			entries = append(entries, directoryEntry{path: path, d: d})
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "reading directory")
+	}
+
+	files, err := p.readDirectoryEntries(root, entries, process)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading directory files")
+	}
+	return files, nil
+}
+
+// directoryProgressInterval is the number of files read between
+// progress messages when reading a directory
+const directoryProgressInterval = 100
+
+// directoryEntry is a file found while walking a directory
+type directoryEntry struct {
+	path string
+	d    fs.DirEntry
+}
+
+// readDirectoryEntries builds the SPDX files of the directory entries
+// using a pool of workers, which also call process on the files read.
+// Errors from all workers are combined into the returned error.
+func (p *Package) readDirectoryEntries(
+	root string, entries []directoryEntry, process func(*File) error,
+) ([]*File, error) {
+	workers := 1
+	if p.Options().Concurrency > 1 {
+		workers = p.Options().Concurrency
+	}
+
+	results := make([]*File, len(entries))
+	errs := make([]error, len(entries))
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	mtx := sync.Mutex{}
+	done := 0
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				f, err := p.directoryFile(root, entries[i].path, entries[i].d)
+				if err == nil && f != nil && process != nil {
+					err = process(f)
+				}
+				results[i] = f
+				errs[i] = errors.Wrapf(err, "reading %s", entries[i].path)
+
+				mtx.Lock()
+				done++
+				if done%directoryProgressInterval == 0 || done == len(entries) {
+					p.logger().Infof("Read %d/%d directory entries in %s", done, len(entries), root)
+				}
+				mtx.Unlock()
+			}
+		}()
+	}
+	for i := range entries {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	failed := []string{}
+	files := []*File{}
+	for i := range entries {
+		if errs[i] != nil {
+			failed = append(failed, errs[i].Error())
+			continue
+		}
+		// Skipped entries return no file
+		if results[i] != nil {
+			files = append(files, results[i])
+		}
+	}
+	if len(failed) > 0 {
+		return files, errors.Errorf(
+			"%d directory entries could not be read: %s", len(failed), strings.Join(failed, "; "),
+		)
+	}
+	return files, nil
+}
+
+// directoryFile builds the SPDX file for path found while walking the
+// root directory. If the file is to be skipped, it returns nil.
+func (p *Package) directoryFile(root, path string, d fs.DirEntry) (*File, error) {
+	name, err := relativeFileName(root, path)
+	if err != nil {
+		return nil, err
+	}
+
+	sourcePath := path
+	if d.Type()&os.ModeSymlink != 0 {
+		switch p.Options().SymlinkPolicy {
+		case SymlinkRecord:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return nil, errors.Wrap(err, "reading symlink")
+			}
+			f := NewFile()
+			f.Name = name
+			f.Comment = "Symbolic link to " + target
+			f.Checksum = checksumsForBytes([]byte{})
+			return f, nil
+		case SymlinkFollowWithinRoot:
+			target, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				p.logger().Warnf("Skipping broken symlink %s: %v", path, err)
+				return nil, nil
+			}
+			if !pathWithin(root, target) {
+				p.logger().Warnf("Skipping symlink %s, it points outside of %s", path, root)
+				return nil, nil
+			}
+			info, err := os.Stat(target)
+			if err != nil {
+				return nil, errors.Wrap(err, "checking symlink target")
+			}
+			// Directories inside the root are already being read
+			if !info.Mode().IsRegular() {
+				p.logger().Debugf("Skipping symlink %s to non regular file", path)
+				return nil, nil
+			}
+			sourcePath = target
+		default:
+			p.logger().Debugf("Skipping symlink %s", path)
+			return nil, nil
+		}
+	} else if !d.Type().IsRegular() {
+		p.logger().Debugf("Skipping special file %s", path)
+		return nil, nil
+	}
+
+	f := NewFile()
+	f.Options().UseMmap = p.Options().UseMmap
+	if err := f.ReadSourceFile(sourcePath); err != nil {
+		return nil, errors.Wrap(err, "reading file data")
+	}
+	f.Name = name
+	// Let AddFiles derive the ID from the file name, the content
+	// based ID from ReadSourceFile collides on identical files
+	f.ID = ""
+	return f, nil
+}
+
+// pathWithin returns true if path is root or is contained in it.
+// Both paths are expected to be absolute and clean.
+func pathWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
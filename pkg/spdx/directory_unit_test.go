@@ -0,0 +1,358 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestTree creates files in dir from a map of paths to contents
+func writeTestTree(t *testing.T, dir string, files map[string]string) {
+	for path, content := range files {
+		require.Nil(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), os.FileMode(0o755)))
+		require.Nil(t, os.WriteFile(filepath.Join(dir, path), []byte(content), os.FileMode(0o644)))
+	}
+}
+
+// packageFileNames returns the sorted names of the files in a package
+func packageFileNames(pkg *Package) []string {
+	names := []string{}
+	for _, f := range pkg.Files {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestReadDirectorySymlinks(t *testing.T) {
+	base, err := os.MkdirTemp("", "spdx-symlinks-")
+	require.Nil(t, err)
+	defer os.RemoveAll(base)
+
+	root := filepath.Join(base, "root")
+	writeTestTree(t, base, map[string]string{
+		"outside.txt":     "secret",
+		"root/README":     "readme",
+		"root/sub/a.txt":  "a",
+		"root/sub/b.txt":  "b",
+		"root/other/c.go": "package c",
+	})
+	require.Nil(t, os.Symlink(filepath.Join(base, "outside.txt"), filepath.Join(root, "escape")))
+	require.Nil(t, os.Symlink("sub/a.txt", filepath.Join(root, "inside")))
+	require.Nil(t, os.Symlink("sub", filepath.Join(root, "subdir")))
+
+	regular := []string{"./README", "./other/c.go", "./sub/a.txt", "./sub/b.txt"}
+	for _, tc := range []struct {
+		policy   SymlinkPolicy
+		expected []string
+	}{
+		{SymlinkIgnore, regular},
+		{SymlinkFollowWithinRoot, append([]string{"./inside"}, regular...)},
+		{SymlinkRecord, append([]string{"./escape", "./inside"}, append(regular, "./subdir")...)},
+	} {
+		pkg := NewPackage()
+		pkg.Name = "symlinks"
+		pkg.Options().SymlinkPolicy = tc.policy
+		require.Nil(t, pkg.ReadDirectory(root))
+		require.True(t, pkg.FilesAnalyzed)
+		names := packageFileNames(pkg)
+		sort.Strings(tc.expected)
+		require.Equal(t, tc.expected, names)
+
+		for _, f := range pkg.Files {
+			require.NotEmpty(t, f.Checksum["SHA1"])
+			switch f.Name {
+			case "./inside":
+				if tc.policy == SymlinkFollowWithinRoot {
+					// The followed link has the contents of its target
+					require.Equal(t, checksumsForBytes([]byte("a")), f.Checksum)
+				}
+			case "./escape":
+				// The file outside the root is never read
+				require.Equal(t, checksumsForBytes([]byte{}), f.Checksum)
+				require.Contains(t, f.Comment, "outside.txt")
+			}
+		}
+	}
+}
+
+// writeManyFiles creates n files of size bytes in a new temporary directory
+func writeManyFiles(t testing.TB, n, size int) string {
+	dir, err := os.MkdirTemp("", "spdx-directory-")
+	require.Nil(t, err)
+	content := make([]byte, size)
+	for i := 0; i < n; i++ {
+		content[0] = byte(i)
+		path := filepath.Join(dir, fmt.Sprintf("dir%d", i%10), fmt.Sprintf("file%d.bin", i))
+		require.Nil(t, os.MkdirAll(filepath.Dir(path), os.FileMode(0o755)))
+		require.Nil(t, os.WriteFile(path, content, os.FileMode(0o644)))
+	}
+	return dir
+}
+
+func TestReadDirectoryConcurrency(t *testing.T) {
+	dir := writeManyFiles(t, 50, 1024)
+	defer os.RemoveAll(dir)
+
+	docs := []string{}
+	for _, concurrency := range []int{0, 1, 8} {
+		pkg := NewPackage()
+		pkg.Name = "concurrent"
+		pkg.ID = "SPDXRef-Package-concurrent"
+		pkg.Options().Concurrency = concurrency
+		require.Nil(t, pkg.ReadDirectory(dir))
+		require.Len(t, pkg.Files, 50)
+		doc, err := pkg.Render()
+		require.Nil(t, err)
+		docs = append(docs, doc)
+	}
+	require.Equal(t, docs[0], docs[1])
+	require.Equal(t, docs[0], docs[2])
+
+	// Errors from all the workers are returned
+	entries := []directoryEntry{}
+	require.Nil(t, filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			entries = append(entries, directoryEntry{path: path, d: d})
+		}
+		return nil
+	}))
+	require.Nil(t, os.Remove(filepath.Join(dir, "dir3", "file3.bin")))
+	require.Nil(t, os.Remove(filepath.Join(dir, "dir7", "file7.bin")))
+	pkg := NewPackage()
+	pkg.Name = "concurrent"
+	pkg.Options().Concurrency = 8
+	_, err := pkg.readDirectoryEntries(dir, entries, nil)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "2 directory entries could not be read")
+	require.Contains(t, err.Error(), "file3.bin")
+	require.Contains(t, err.Error(), "file7.bin")
+}
+
+func benchmarkReadDirectory(b *testing.B, concurrency int) {
+	dir := writeManyFiles(b, 200, 256*1024)
+	defer os.RemoveAll(dir)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pkg := NewPackage()
+		pkg.Name = "bench"
+		pkg.Options().Concurrency = concurrency
+		if err := pkg.ReadDirectory(dir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadDirectorySerial(b *testing.B) { benchmarkReadDirectory(b, 1) }
+
+func BenchmarkReadDirectoryConcurrent(b *testing.B) { benchmarkReadDirectory(b, 8) }
+
+func TestReadDirectoryPackageFileName(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spdx-directory-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	writeTestTree(t, dir, map[string]string{"README": "readme", "src.tar.gz": "tarball"})
+
+	// A package backed by a file renders its name
+	pkg := NewPackage()
+	pkg.Name = "backed"
+	pkg.ID = "SPDXRef-Package-backed"
+	pkg.Options().WorkDir = dir
+	require.Nil(t, pkg.ReadSourceFile(filepath.Join(dir, "src.tar.gz")))
+	require.Equal(t, "./src.tar.gz", pkg.ArchiveFileName)
+	doc, err := pkg.Render()
+	require.Nil(t, err)
+	require.Contains(t, doc, "PackageFileName: ./src.tar.gz\n")
+
+	// Reading a directory drops it, the package is not a single file
+	require.Nil(t, pkg.ReadDirectory(dir))
+	require.Empty(t, pkg.ArchiveFileName)
+	doc, err = pkg.Render()
+	require.Nil(t, err)
+	require.NotContains(t, doc, "PackageFileName:")
+
+	// Packages setting only FileName still render it
+	pkg = NewPackage()
+	pkg.Name = "directory"
+	pkg.ID = "SPDXRef-Package-directory"
+	pkg.FileName = "stray.tar.gz"
+	doc, err = pkg.Render()
+	require.Nil(t, err)
+	require.Contains(t, doc, "PackageFileName: stray.tar.gz\n")
+
+	// Unless the package is read from a directory
+	require.Nil(t, pkg.ReadDirectory(dir))
+	require.Empty(t, pkg.FileName)
+	doc, err = pkg.Render()
+	require.Nil(t, err)
+	require.NotContains(t, doc, "PackageFileName:")
+
+	// Empty directories have no files to analyze
+	empty, err := os.MkdirTemp("", "spdx-directory-empty-")
+	require.Nil(t, err)
+	defer os.RemoveAll(empty)
+	pkg = NewPackage()
+	pkg.Name = "empty"
+	pkg.ID = "SPDXRef-Package-empty"
+	require.Nil(t, pkg.ReadDirectory(empty))
+	require.False(t, pkg.FilesAnalyzed)
+	_, err = pkg.Render()
+	require.Nil(t, err)
+}
+
+// testLogger records the messages it receives
+type testLogger struct {
+	sync.Mutex
+	messages []string
+}
+
+func (l *testLogger) log(format string, args ...interface{}) {
+	l.Lock()
+	defer l.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Debugf(format string, args ...interface{}) { l.log(format, args...) }
+func (l *testLogger) Infof(format string, args ...interface{})  { l.log(format, args...) }
+func (l *testLogger) Warnf(format string, args ...interface{})  { l.log(format, args...) }
+
+func TestReadDirectoryLogger(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spdx-directory-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	writeTestTree(t, dir, map[string]string{"README": "readme"})
+	require.Nil(t, os.Symlink("README", filepath.Join(dir, "link")))
+
+	logger := &testLogger{}
+	pkg := NewPackage()
+	pkg.Name = "logged"
+	pkg.Options().Logger = logger
+	require.Nil(t, pkg.ReadDirectory(dir))
+	require.Contains(t, logger.messages, "Skipping symlink "+filepath.Join(dir, "link"))
+	require.Contains(t, logger.messages, "Read 2/2 directory entries in "+dir)
+
+	// Without a logger the messages go to logrus
+	pkg = NewPackage()
+	pkg.Name = "default"
+	require.Equal(t, logrus.StandardLogger(), pkg.logger())
+	require.Nil(t, pkg.ReadDirectory(dir))
+
+	// The messages can be discarded
+	pkg.Options().Logger = NoopLogger{}
+	require.Nil(t, pkg.ReadDirectory(dir))
+}
+
+func TestIngestDirectoryGrouped(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spdx-grouped-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	writeTestTree(t, dir, map[string]string{
+		"README":              "readme",
+		"cmd/main.go":         "package main",
+		"cmd/tool/tool.go":    "package tool",
+		"pkg/lib/lib.go":      "package lib",
+		"pkg/lib/sub/deep.go": "package sub",
+	})
+
+	// subpackages returns the subpackages of pkg by name
+	subpackages := func(pkg *Package) map[string]*Package {
+		byName := map[string]*Package{}
+		for _, sub := range pkg.Packages {
+			byName[sub.Name] = sub
+		}
+		return byName
+	}
+
+	pkg := NewPackage()
+	pkg.ID = "SPDXRef-Package-grouped"
+	pkg.Name = "grouped"
+	require.Nil(t, pkg.IngestDirectoryGrouped(dir, 1))
+	require.Equal(t, []string{"./README"}, packageFileNames(pkg))
+	groups := subpackages(pkg)
+	require.Len(t, groups, 2)
+	require.Equal(t, []string{"./cmd/main.go", "./cmd/tool/tool.go"}, packageFileNames(groups["cmd"]))
+	require.Equal(t, []string{"./pkg/lib/lib.go", "./pkg/lib/sub/deep.go"}, packageFileNames(groups["pkg"]))
+	require.Empty(t, groups["cmd"].Packages)
+	out, err := pkg.Render()
+	require.Nil(t, err)
+	require.Contains(t, out, "Relationship: SPDXRef-Package-grouped CONTAINS "+groups["cmd"].ID+"\n")
+
+	// Directories are nested up to depth, those without files are not analyzed
+	pkg = NewPackage()
+	pkg.ID = "SPDXRef-Package-grouped"
+	pkg.Name = "grouped"
+	require.Nil(t, pkg.IngestDirectoryGrouped(dir, 2))
+	groups = subpackages(pkg)
+	require.Equal(t, []string{"./cmd/main.go"}, packageFileNames(groups["cmd"]))
+	require.Equal(t, []string{"./cmd/tool/tool.go"}, packageFileNames(subpackages(groups["cmd"])["cmd/tool"]))
+	require.False(t, groups["pkg"].FilesAnalyzed)
+	require.Equal(t,
+		[]string{"./pkg/lib/lib.go", "./pkg/lib/sub/deep.go"},
+		packageFileNames(subpackages(groups["pkg"])["pkg/lib"]),
+	)
+	_, err = pkg.Render()
+	require.Nil(t, err)
+
+	// Depth zero reads all files in the package
+	pkg = NewPackage()
+	pkg.Name = "flat"
+	require.Nil(t, pkg.IngestDirectoryGrouped(dir, 0))
+	require.Len(t, pkg.Files, 5)
+	require.Empty(t, pkg.Packages)
+}
+
+func TestReadDirectoryExcludeGlobs(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spdx-exclude-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	writeTestTree(t, dir, map[string]string{
+		"main.go":                          "package main",
+		"main.test":                        "binary",
+		"cmd/tool.test":                    "binary",
+		"cmd/tool.go":                      "package cmd",
+		"vendor/github.com/x/y/y.go":       "package y",
+		"web/node_modules/left-pad/pad.js": "module.exports = 1",
+		"web/index.js":                     "require('left-pad')",
+		"docs/vendor/notes.md":             "notes",
+	})
+
+	pkg := NewPackage()
+	pkg.Name = "excluded"
+	pkg.Options().ExcludeGlobs = []string{"*.test", "/vendor/**", "node_modules"}
+	require.Nil(t, pkg.ReadDirectory(dir))
+	require.Equal(t, []string{
+		"./cmd/tool.go", "./docs/vendor/notes.md", "./main.go", "./web/index.js",
+	}, packageFileNames(pkg))
+
+	// Without patterns all files are read
+	pkg = NewPackage()
+	pkg.Name = "all"
+	require.Nil(t, pkg.ReadDirectory(dir))
+	require.Len(t, pkg.Files, 8)
+}
@@ -17,13 +17,17 @@ limitations under the License.
 package spdx
 
 import (
+	"bufio"
 	"bytes"
 	"crypto/sha1"
+	"crypto/sha256"
 	"fmt"
-	"html/template"
+	"io"
 	"log"
 	"os"
-	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/google/uuid"
@@ -40,6 +44,8 @@ DataLicense: CC0-1.0
 {{ end -}}
 {{ if .Namespace }}DocumentNamespace: {{ .Namespace }}
 {{ end -}}
+{{ range .ExternalDocumentRefs }}ExternalDocumentRef: {{ .ID }} {{ .URI }} SHA1: {{ index .Checksum "SHA1" }}
+{{ end -}}
 {{ if .Creator -}}
 {{- if .Creator.Person }}Creator: Person: {{ .Creator.Person }}
 {{ end -}}
@@ -48,15 +54,18 @@ DataLicense: CC0-1.0
 {{ end -}}
 {{- end -}}
 {{ end -}}
-{{ if .Created }}Created: {{ dateFormat .Created }}
-{{ end }}
-
+Created: {{ created }}
+{{ textField "CreatorComment" .CreatorComment }}
 `
 
+// DataLicenseCC0 is the license of the SPDX metadata. The SPDX spec
+// mandates documents to use CC0-1.0, it is the only value accepted.
+const DataLicenseCC0 = "CC0-1.0"
+
 // Document abstracts the SPDX document
 type Document struct {
 	Version     string // SPDX-2.2
-	DataLicense string // CC0-1.0
+	DataLicense string // CC0-1.0, the only data license allowed by SPDX
 	ID          string // SPDXRef-DOCUMENT
 	Name        string // hello-go-src
 	Namespace   string // https://swinslow.net/spdx-examples/example6/hello-go-src-v1
@@ -64,18 +73,60 @@ type Document struct {
 		Person string   // Steve Winslow (steve@swinslow.net)
 		Tool   []string // github.com/spdx/tools-golang/builder
 	}
-	Created  time.Time // 2020-11-24T01:12:27Z
-	Packages map[string]*Package
-	Files    map[string]*File // List of files
+	Created        time.Time // 2020-11-24T01:12:27Z
+	CreatorComment string    // Notes on how the document was produced, eg scan limitations
+	Packages       map[string]*Package
+	Files          map[string]*File // List of files
+	Clock          func() time.Time // Returns the creation time of documents without one
+
+	// Other SPDX documents referenced by this one
+	ExternalDocumentRefs []ExternalDocumentRef
+}
+
+// spdxTimeFormat is the layout of the document creation time
+const spdxTimeFormat = "2006-01-02T15:04:05Z"
+
+// creationTime returns the time the document was created. Documents
+// built without NewDocument get their creation time on the first call,
+// so all the renders of a document agree on it.
+func (d *Document) creationTime() time.Time {
+	if d.Created.IsZero() {
+		d.Created = documentCreationTime(d.Clock)
+	}
+	return d.Created.UTC()
 }
 
-// NewDocument returns a new SPDX document with some defaults preloaded
+// documentCreationTime returns the time returned by clock if it is not
+// nil. Otherwise the time is read from SOURCE_DATE_EPOCH for
+// reproducible builds, falling back to the current time.
+func documentCreationTime(clock func() time.Time) time.Time {
+	if clock != nil {
+		return clock().UTC()
+	}
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		secs, err := strconv.ParseInt(epoch, 10, 64)
+		if err == nil {
+			return time.Unix(secs, 0).UTC()
+		}
+		logrus.Warnf("Ignoring invalid SOURCE_DATE_EPOCH %q: %v", epoch, err)
+	}
+	return time.Now().UTC()
+}
+
+// NewDocument returns a new SPDX document with some defaults preloaded.
+// Its creation time is taken from SOURCE_DATE_EPOCH when it is set, or
+// is the current time.
 func NewDocument() *Document {
+	return NewDocumentWithClock(nil)
+}
+
+// NewDocumentWithClock returns a new document like NewDocument, created
+// at the time returned by clock
+func NewDocumentWithClock(clock func() time.Time) *Document {
 	return &Document{
 		ID:          "SPDXRef-DOCUMENT",
 		Version:     "SPDX-2.2",
-		DataLicense: "CC0-1.0",
-		Created:     time.Now().UTC(),
+		DataLicense: DataLicenseCC0,
 		Creator: struct {
 			Person string
 			Tool   []string
@@ -83,7 +134,50 @@ func NewDocument() *Document {
 			Person: defaultDocumentAuthor,
 			Tool:   []string{"k8s.io/release/pkg/spdx"},
 		},
+		Created: documentCreationTime(clock),
+		Clock:   clock,
+	}
+}
+
+// checkDataLicense returns an error if the document sets a data license
+// other than CC0-1.0. An empty data license is rendered as CC0-1.0.
+func (d *Document) checkDataLicense() error {
+	if d.DataLicense != "" && d.DataLicense != DataLicenseCC0 {
+		return errors.Errorf(
+			"invalid data license %q, SPDX documents must use %s", d.DataLicense, DataLicenseCC0,
+		)
+	}
+	return nil
+}
+
+// describedIDs returns the IDs of the elements the document describes:
+// the primary package if one of its packages is marked as such, or all
+// its top level files and packages otherwise. Marking more than one
+// package as primary is an error.
+func (d *Document) describedIDs() (map[string]struct{}, error) {
+	primary := []string{}
+	for _, id := range sortedPackageIDs(d.Packages) {
+		if d.Packages[id].IsPrimary {
+			primary = append(primary, id)
+		}
+	}
+	if len(primary) > 1 {
+		return nil, errors.Errorf(
+			"document can only have one primary package but %s are marked as primary",
+			strings.Join(primary, ", "),
+		)
+	}
+	if len(primary) == 1 {
+		return map[string]struct{}{primary[0]: {}}, nil
+	}
+	described := map[string]struct{}{}
+	for id := range d.Files {
+		described[id] = struct{}{}
+	}
+	for id := range d.Packages {
+		described[id] = struct{}{}
 	}
+	return described, nil
 }
 
 // AddPackage adds a new empty package to the document
@@ -92,13 +186,9 @@ func (d *Document) AddPackage(pkg *Package) error {
 		d.Packages = map[string]*Package{}
 	}
 
-	if pkg.ID == "" {
+	if pkg.ID == "" && pkg.Name != "" {
 		// If we so not have an ID but have a name generate it fro there
-		reg := regexp.MustCompile("[^a-zA-Z0-9-]+")
-		id := reg.ReplaceAllString(pkg.Name, "")
-		if id != "" {
-			pkg.ID = "SPDXRef-Package-" + id
-		}
+		pkg.ID = packageIDFromName(pkg.Name)
 	}
 	if pkg.ID == "" {
 		return errors.New("package id is needed to add a new package")
@@ -113,23 +203,72 @@ func (d *Document) AddPackage(pkg *Package) error {
 
 // Write outputs the SPDX document into a file
 func (d *Document) Write(path string) error {
-	content, err := d.Render()
+	return d.write(path, io.Discard)
+}
+
+// write outputs the SPDX document into a file, copying the output to tee
+func (d *Document) write(path string, tee io.Writer) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(0o644))
 	if err != nil {
+		return errors.Wrap(err, "opening SPDX file")
+	}
+	w := bufio.NewWriter(io.MultiWriter(f, tee))
+	if err := d.RenderTo(w); err != nil {
+		f.Close()
+		os.Remove(path)
 		return errors.Wrap(err, "rendering SPDX code")
 	}
-	if err := os.WriteFile(path, []byte(content), os.FileMode(0o644)); err != nil {
+	if err := w.Flush(); err != nil {
+		f.Close()
 		return errors.Wrap(err, "writing SPDX code to file")
 	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "closing SPDX file")
+	}
 	logrus.Infof("SPDX SBOM written to %s", path)
 	return nil
 }
 
+// Digest returns the algorithm and the hex encoded SHA256 of the
+// rendered tag-value document, to record it in the provenance of a
+// release. Rendering is deterministic, so the digest only changes when
+// the document data does and matches the document written by Write.
+func (d *Document) Digest() (algorithm, value string, err error) {
+	h := sha256.New()
+	if err := d.RenderTo(h); err != nil {
+		return "", "", errors.Wrap(err, "rendering document to compute its digest")
+	}
+	return "SHA256", fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
 // Render reders the spdx manifest
 func (d *Document) Render() (doc string, err error) {
+	var sb strings.Builder
+	if err := d.RenderTo(&sb); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// RenderTo writes the document to w as it is rendered, so large
+// documents do not need to be held in memory
+func (d *Document) RenderTo(w io.Writer) error {
 	var buf bytes.Buffer
+	created := d.creationTime()
 	funcMap := template.FuncMap{
 		// The name "title" is what the function will be called in the template text.
-		"dateFormat": func(t time.Time) string { return t.UTC().Format("2006-02-01T15:04:05Z") },
+		"created": func() string { return created.Format(spdxTimeFormat) },
+	}
+
+	if err := d.checkDataLicense(); err != nil {
+		return err
+	}
+	if err := d.checkExternalDocumentRefs(); err != nil {
+		return err
+	}
+	described, err := d.describedIDs()
+	if err != nil {
+		return err
 	}
 
 	if d.Name == "" {
@@ -137,48 +276,71 @@ func (d *Document) Render() (doc string, err error) {
 		logrus.Warnf("Document has no name defined, automatically set to " + d.Name)
 	}
 
-	tmpl, err := template.New("document").Funcs(funcMap).Parse(docTemplate)
+	tmpl, err := template.New("document").Funcs(templateFuncs).Funcs(funcMap).Parse(docTemplate)
 	if err != nil {
 		log.Fatalf("parsing: %s", err)
 	}
 
 	// Run the template to verify the output.
 	if err := tmpl.Execute(&buf, d); err != nil {
-		return "", errors.Wrap(err, "executing spdx document template")
+		return errors.Wrap(err, "executing spdx document template")
 	}
 
-	doc = buf.String()
+	// Relationships go at the end and lines end in CRLF if any
+	// described package asks for it
+	state := newRenderState()
+	state.created = d.creationTime()
+	ending := LineEndingLF
+	for _, pkg := range d.Packages {
+		if pkg.Options() != nil && pkg.Options().RelationshipsAtEnd {
+			state.relationshipsAtEnd = true
+		}
+		if pkg.lineEnding() == LineEndingCRLF {
+			ending = LineEndingCRLF
+		}
+	}
+	state.out = lineEndingWriter(w, ending)
+	state.write(buf.String())
 
 	// List files in the document. Files listed directly on the
 	// document do not contain relationships yet.
 	filesDescribed := ""
 	if len(d.Files) > 0 {
-		doc += "\n##### Files independent of packages\n\n"
-		filesDescribed = "\n"
+		state.write("##### Files independent of packages\n\n")
 	}
 
-	for _, file := range d.Files {
-		fileDoc, err := file.Render()
-		if err != nil {
-			return "", errors.Wrap(err, "rendering file "+file.Name)
+	for _, id := range sortedFileIDs(d.Files) {
+		file := d.Files[id]
+		if err := walkFile(state, file, &tagValueWriter{state: state}); err != nil {
+			return errors.Wrap(err, "rendering file "+file.Name)
+		}
+		if _, ok := described[id]; ok {
+			filesDescribed += state.relationship(fmt.Sprintf("Relationship: %s DESCRIBES %s\n\n", d.ID, file.ID))
 		}
-		doc += fileDoc
-		filesDescribed += fmt.Sprintf("Relationship: %s DESCRIBES %s\n\n", d.ID, file.ID)
 	}
-	doc += filesDescribed
+	state.write(filesDescribed)
 
-	// Cycle all packages and get their data
-	for _, pkg := range d.Packages {
-		pkgDoc, err := pkg.Render()
-		if err != nil {
-			return "", errors.Wrap(err, "rendering pkg "+pkg.Name)
+	// Cycle all packages and get their data. Packages shared
+	// by more than one root are only rendered once.
+	for _, id := range sortedPackageIDs(d.Packages) {
+		pkg := d.Packages[id]
+		state.maxDepth = pkg.maxDepth()
+		if err := pkg.render(state); err != nil {
+			return errors.Wrap(err, "rendering pkg "+pkg.Name)
 		}
+		if _, ok := described[id]; ok {
+			state.write(state.relationship(fmt.Sprintf("Relationship: %s DESCRIBES %s\n\n", d.ID, pkg.ID)))
+		}
+	}
+	for _, id := range d.externalDescribedIDs() {
+		state.write(state.relationship(fmt.Sprintf("Relationship: %s DESCRIBES %s\n\n", d.ID, id)))
+	}
 
-		doc += pkgDoc
-		doc += fmt.Sprintf("Relationship: %s DESCRIBES %s\n\n", d.ID, pkg.ID)
+	if state.relationships != "" {
+		state.write("##### Relationships\n\n" + state.relationships + "\n")
 	}
 
-	return doc, err
+	return state.err
 }
 
 // AddFile adds a file contained in the package
@@ -0,0 +1,317 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentMultipleRoots(t *testing.T) {
+	shared := NewPackage()
+	shared.Name = "shared"
+
+	doc := NewDocument()
+	doc.Name = "multiple-roots"
+	for _, name := range []string{"kubectl", "kubelet"} {
+		root := NewPackage()
+		root.Name = name
+		require.Nil(t, root.AddDependency(shared))
+		require.Nil(t, doc.AddPackage(root))
+	}
+
+	for i := 0; i < 2; i++ {
+		out, err := doc.Render()
+		require.Nil(t, err)
+		require.Equal(t, 1, strings.Count(out, "PackageName: shared\n"))
+		require.Contains(t, out, "Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-kubectl\n")
+		require.Contains(t, out, "Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-kubelet\n")
+		require.Contains(t, out, "Relationship: SPDXRef-Package-kubectl DEPENDS_ON SPDXRef-Package-shared\n")
+		require.Contains(t, out, "Relationship: SPDXRef-Package-kubelet DEPENDS_ON SPDXRef-Package-shared\n")
+
+		// Roots are rendered in a deterministic order
+		require.Less(t, strings.Index(out, "PackageName: kubectl"), strings.Index(out, "PackageName: kubelet"))
+	}
+}
+
+func TestDocumentSpacing(t *testing.T) {
+	doc := NewDocument()
+	doc.Name = "spacing"
+	doc.Namespace = "https://example.com/spacing"
+	doc.Created = time.Date(2021, 3, 3, 10, 30, 0, 0, time.UTC)
+
+	loose := NewFile()
+	loose.ID = "SPDXRef-File-loose"
+	loose.Name = "loose.txt"
+	loose.Checksum = map[string]string{"SHA1": fmt.Sprintf("%040x", 9)}
+	doc.Files = map[string]*File{loose.ID: loose}
+
+	root := testPackageWithFiles(t, "MIT", "Apache-2.0")
+	for i, name := range []string{"sub-a", "sub-b"} {
+		sub := NewPackage()
+		sub.Name = name
+		sub.FilesAnalyzed = true
+		f := NewFile()
+		f.Name = name + ".txt"
+		f.Checksum = map[string]string{"SHA1": fmt.Sprintf("%040x", 10+i)}
+		require.Nil(t, sub.AddFile(f))
+		require.Nil(t, root.AddPackage(sub))
+	}
+	require.Nil(t, doc.AddPackage(root))
+
+	out, err := doc.Render()
+	require.Nil(t, err)
+	expected, err := os.ReadFile("testdata/document-spacing.spdx")
+	require.Nil(t, err)
+	require.Equal(t, string(expected), out)
+	require.NotContains(t, out, "\n\n\n")
+}
+
+func TestDocumentDataLicense(t *testing.T) {
+	doc := NewDocument()
+	doc.Name = "data-license"
+	require.Equal(t, DataLicenseCC0, doc.DataLicense)
+	out, err := doc.Render()
+	require.Nil(t, err)
+	require.Contains(t, out, "\nDataLicense: CC0-1.0\n")
+
+	// An empty data license defaults to CC0-1.0
+	doc.DataLicense = ""
+	out, err = doc.Render()
+	require.Nil(t, err)
+	require.Contains(t, out, "\nDataLicense: CC0-1.0\n")
+
+	// Any other data license is rejected
+	doc.DataLicense = "MIT"
+	_, err = doc.Render()
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), `invalid data license "MIT"`)
+	_, err = doc.RenderJSON()
+	require.NotNil(t, err)
+}
+
+func TestDocumentCreated(t *testing.T) {
+	fixed := time.Date(2021, 7, 2, 10, 30, 5, 0, time.FixedZone("CEST", 2*60*60))
+	doc := NewDocumentWithClock(func() time.Time { return fixed })
+	doc.Name = "created"
+	out, err := doc.Render()
+	require.Nil(t, err)
+	require.Contains(t, out, "\nCreated: 2021-07-02T08:30:05Z\n")
+
+	// An explicit creation time takes precedence over the clock
+	doc.Created = time.Date(2020, 11, 24, 1, 12, 27, 0, time.UTC)
+	out, err = doc.Render()
+	require.Nil(t, err)
+	require.Contains(t, out, "\nCreated: 2020-11-24T01:12:27Z\n")
+
+	// Without a clock, SOURCE_DATE_EPOCH is honored
+	require.Nil(t, os.Setenv("SOURCE_DATE_EPOCH", "1625221805"))
+	defer os.Unsetenv("SOURCE_DATE_EPOCH")
+	doc = NewDocument()
+	doc.Name = "created"
+	out, err = doc.Render()
+	require.Nil(t, err)
+	require.Contains(t, out, "\nCreated: 2021-07-02T10:30:05Z\n")
+	require.Nil(t, os.Unsetenv("SOURCE_DATE_EPOCH"))
+
+	// The creation time is set once, all the renders agree on it
+	doc = NewDocument()
+	doc.Name = "created"
+	created := doc.Created
+	require.False(t, created.IsZero())
+	time.Sleep(1100 * time.Millisecond)
+	out, err = doc.Render()
+	require.Nil(t, err)
+	require.Contains(t, out, "\nCreated: "+created.UTC().Format(spdxTimeFormat)+"\n")
+	data, err := doc.RenderJSON()
+	require.Nil(t, err)
+	require.Contains(t, string(data), `"created": "`+created.UTC().Format(spdxTimeFormat)+`"`)
+
+	// Documents built without NewDocument get it on the first render
+	literal := &Document{Name: "literal", Clock: func() time.Time { return fixed }}
+	out, err = literal.Render()
+	require.Nil(t, err)
+	require.Contains(t, out, "\nCreated: 2021-07-02T08:30:05Z\n")
+	require.Equal(t, fixed.UTC(), literal.Created)
+}
+
+func TestDocumentCreatorComment(t *testing.T) {
+	doc := NewDocument()
+	doc.Name = "creator-comment"
+	doc.Namespace = "https://example.com/creator-comment"
+	doc.Created = time.Date(2021, 7, 2, 8, 30, 5, 0, time.UTC)
+	doc.CreatorComment = "Vendored directories were not scanned.\nSee </text> for details."
+	out, err := doc.Render()
+	require.Nil(t, err)
+	require.Contains(t, out, "Created: 2021-07-02T08:30:05Z\n"+
+		"CreatorComment: <text>Vendored directories were not scanned.\n"+
+		"See &lt;/text&gt; for details.\n</text>\n\n")
+
+	// Documents without a comment do not render the tag
+	doc.CreatorComment = ""
+	out, err = doc.Render()
+	require.Nil(t, err)
+	require.NotContains(t, out, "CreatorComment:")
+	require.Contains(t, out, "Created: 2021-07-02T08:30:05Z\n\n")
+
+	// The comment is read back by the parsers
+	doc.CreatorComment = "Vendored directories were not scanned.\nLicenses were not analyzed."
+	out, err = doc.Render()
+	require.Nil(t, err)
+	parsed, err := ParseTagValue(strings.NewReader(out))
+	require.Nil(t, err)
+	require.Equal(t, doc.CreatorComment, parsed.CreatorComment)
+	require.Nil(t, doc.ValidateJSON())
+	data, err := doc.RenderJSON()
+	require.Nil(t, err)
+	fromJSON, err := ParseJSON(strings.NewReader(string(data)))
+	require.Nil(t, err)
+	require.Equal(t, doc.CreatorComment, fromJSON.CreatorComment)
+}
+
+func TestDocumentDigest(t *testing.T) {
+	doc := NewDocument()
+	doc.Name = "digest"
+	doc.Created = time.Date(2021, 7, 2, 8, 30, 5, 0, time.UTC)
+	pkg := testPackageWithFiles(t, "MIT", "Apache-2.0", "MIT")
+	require.Nil(t, doc.AddPackage(pkg))
+
+	algorithm, digest, err := doc.Digest()
+	require.Nil(t, err)
+	require.Equal(t, "SHA256", algorithm)
+	out, err := doc.Render()
+	require.Nil(t, err)
+	require.Equal(t, fmt.Sprintf("%x", sha256.Sum256([]byte(out))), digest)
+	for i := 0; i < 3; i++ {
+		_, again, err := doc.Digest()
+		require.Nil(t, err)
+		require.Equal(t, digest, again)
+	}
+
+	// Any change in the data changes the digest
+	f := NewFile()
+	f.Name = "added.txt"
+	f.Checksum = map[string]string{"SHA1": fmt.Sprintf("%040x", 99)}
+	require.Nil(t, pkg.AddFile(f))
+	_, changed, err := doc.Digest()
+	require.Nil(t, err)
+	require.NotEqual(t, digest, changed)
+}
+
+func TestDocumentDigestMatchesWrite(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spdx-digest-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// A plain document, the creation time is the one set by NewDocument
+	doc := NewDocument()
+	doc.Name = "digest-write"
+	require.Nil(t, doc.AddPackage(testPackageWithFiles(t, "MIT", "Apache-2.0")))
+	path := filepath.Join(dir, "doc.spdx")
+	require.Nil(t, doc.Write(path))
+	data, err := os.ReadFile(path)
+	require.Nil(t, err)
+
+	_, digest, err := doc.Digest()
+	require.Nil(t, err)
+	require.Equal(t, fmt.Sprintf("%x", sha256.Sum256(data)), digest)
+}
+
+func TestDocumentPrimaryPackage(t *testing.T) {
+	doc := NewDocument()
+	doc.Name = "primary"
+	for _, name := range []string{"kubectl", "kubelet", "kubeadm"} {
+		root := NewPackage()
+		root.Name = name
+		root.IsPrimary = name == "kubelet"
+		require.Nil(t, doc.AddPackage(root))
+	}
+
+	out, err := doc.Render()
+	require.Nil(t, err)
+	require.Equal(t, 1, strings.Count(out, " DESCRIBES "))
+	require.Contains(t, out, "Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-kubelet\n")
+	require.Contains(t, out, "PackageName: kubectl\n")
+
+	data, err := doc.RenderJSON()
+	require.Nil(t, err)
+	require.Equal(t, 1, strings.Count(string(data), `"DESCRIBES"`))
+	require.Contains(t, string(data), "\"documentDescribes\": [\n    \"SPDXRef-Package-kubelet\"\n  ]")
+
+	// Only one package can be the primary one
+	doc.Packages["SPDXRef-Package-kubectl"].IsPrimary = true
+	_, err = doc.Render()
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "only have one primary package")
+	_, err = doc.RenderJSON()
+	require.NotNil(t, err)
+}
+
+func TestDocumentWriteTree(t *testing.T) {
+	doc := NewDocument()
+	doc.Name = "tree"
+	doc.Namespace = "https://example.com/tree"
+	doc.Created = time.Date(2021, 7, 2, 10, 30, 5, 0, time.UTC)
+	for _, name := range []string{"kubectl", "kubelet"} {
+		root := NewPackage()
+		root.Name = name
+		require.Nil(t, doc.AddPackage(root))
+	}
+	dir := filepath.Join(t.TempDir(), "sbom")
+	require.Nil(t, doc.WriteTree(dir))
+
+	index, err := os.ReadFile(filepath.Join(dir, TreeIndexFileName))
+	require.Nil(t, err)
+	require.NotContains(t, string(index), "PackageName:")
+	for _, name := range []string{"kubectl", "kubelet"} {
+		fragment, err := os.ReadFile(filepath.Join(dir, "Package-"+name+".spdx"))
+		require.Nil(t, err)
+		require.Contains(t, string(fragment), "DocumentNamespace: https://example.com/tree/Package-"+name+"\n")
+		require.Contains(t, string(fragment), "PackageName: "+name+"\n")
+		require.Contains(t, string(index), fmt.Sprintf(
+			"ExternalDocumentRef: DocumentRef-Package-%s https://example.com/tree/Package-%s SHA1: %x\n",
+			name, name, sha1.Sum(fragment),
+		))
+		require.Contains(t, string(index), fmt.Sprintf(
+			"Relationship: SPDXRef-DOCUMENT DESCRIBES DocumentRef-Package-%s:SPDXRef-Package-%s\n", name, name,
+		))
+	}
+
+	// The references are read back by the parsers
+	parsed, err := ParseTagValue(strings.NewReader(string(index)))
+	require.Nil(t, err)
+	require.Len(t, parsed.ExternalDocumentRefs, 2)
+	require.Equal(t, []string{"SPDXRef-Package-kubectl"}, parsed.ExternalDocumentRefs[0].Describes)
+	require.Nil(t, parsed.ValidateJSON())
+	data, err := parsed.RenderJSON()
+	require.Nil(t, err)
+	fromJSON, err := ParseJSON(strings.NewReader(string(data)))
+	require.Nil(t, err)
+	require.Equal(t, parsed.ExternalDocumentRefs, fromJSON.ExternalDocumentRefs)
+
+	// Fragments need a namespace to be referenced
+	doc.Namespace = ""
+	require.NotNil(t, doc.WriteTree(dir))
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RenderDOT writes the graph of the package and the packages related
+// to it in the Graphviz DOT language. Nodes are labeled with the package
+// name and version and edges with the relationship type.
+func (p *Package) RenderDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph sbom {\n")
+
+	nodes := []string{}
+	edges := []string{}
+	seen := map[string]struct{}{}
+	var walk func(pkg *Package)
+	walk = func(pkg *Package) {
+		if _, ok := seen[pkg.ID]; ok {
+			return
+		}
+		seen[pkg.ID] = struct{}{}
+
+		pkg.RLock()
+		label := pkg.Name
+		if pkg.Version != "" {
+			label += "\n" + pkg.Version
+		}
+		nodes = append(nodes, fmt.Sprintf("  %q [label=%q];\n", pkg.ID, label))
+
+		peers := []*Package{}
+		addEdge := func(relationship string, peer *Package) {
+			edges = append(edges, fmt.Sprintf("  %q -> %q [label=%q];\n", pkg.ID, peer.ID, relationship))
+			peers = append(peers, peer)
+		}
+		for _, id := range sortedPackageIDs(pkg.Packages) {
+			addEdge(RelationshipContains, pkg.Packages[id])
+		}
+		for _, id := range sortedPackageIDs(pkg.Dependencies) {
+			addEdge(RelationshipDependsOn, pkg.Dependencies[id])
+		}
+		for _, r := range pkg.Relationships {
+			if r.Package != nil {
+				addEdge(r.Type, r.Package)
+			}
+		}
+		pkg.RUnlock()
+
+		for _, peer := range peers {
+			walk(peer)
+		}
+	}
+	walk(p)
+
+	for _, node := range nodes {
+		b.WriteString(node)
+	}
+	for _, edge := range edges {
+		b.WriteString(edge)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return errors.Wrap(err, "writing DOT graph")
+}
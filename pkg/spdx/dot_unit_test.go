@@ -0,0 +1,64 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderDOT(t *testing.T) {
+	root := NewPackage()
+	root.Name = "app"
+	root.Version = "1.0"
+	lib := NewPackage()
+	lib.Name = "lib"
+	src := NewPackage()
+	src.Name = "app-src"
+	src.ID = "SPDXRef-Package-app-src"
+	require.Nil(t, root.AddPackage(lib))
+	require.Nil(t, root.AddDependency(src))
+	require.Nil(t, root.MarkGeneratedFrom(src))
+	require.Nil(t, lib.AddDependency(root))
+
+	var buf bytes.Buffer
+	require.Nil(t, root.RenderDOT(&buf))
+	dot := buf.String()
+
+	require.True(t, strings.HasPrefix(dot, "digraph sbom {\n"))
+	require.True(t, strings.HasSuffix(dot, "}\n"))
+	for _, line := range []string{
+		`  "SPDXRef-Package-app" [label="app\n1.0"];`,
+		`  "SPDXRef-Package-lib" [label="lib"];`,
+		`  "SPDXRef-Package-app" -> "SPDXRef-Package-lib" [label="CONTAINS"];`,
+		`  "SPDXRef-Package-app" -> "SPDXRef-Package-app-src" [label="DEPENDS_ON"];`,
+		`  "SPDXRef-Package-app" -> "SPDXRef-Package-app-src" [label="GENERATED_FROM"];`,
+		`  "SPDXRef-Package-lib" -> "SPDXRef-Package-app" [label="DEPENDS_ON"];`,
+	} {
+		require.Contains(t, dot, line+"\n")
+	}
+	// Each package is a single node despite the cycle
+	require.Equal(t, 1, strings.Count(dot, "\n  \"SPDXRef-Package-app\" [label"))
+
+	// Output is deterministic
+	var again bytes.Buffer
+	require.Nil(t, root.RenderDOT(&again))
+	require.Equal(t, dot, again.String())
+}
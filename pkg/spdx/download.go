@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxDownloadSize is the size limit of the package sources
+// downloaded by ReadSourceURL when the options do not set one
+const DefaultMaxDownloadSize int64 = 1 << 30
+
+// ReadSourceURL downloads the package source from a remote http(s) URL
+// into a temporary file and populates the package fields derived from
+// it: checksums, DownloadLocation, SourceFile, FileName and
+// ArchiveFileName. Credentials in the URL are used for the download but
+// removed from DownloadLocation. Downloads larger than MaxDownloadSize
+// in the package options are aborted. The caller is responsible for
+// removing the file in SourceFile when done.
+func (p *Package) ReadSourceURL(ctx context.Context, sourceURL string) error {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return errors.Wrap(err, "parsing source URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.Errorf("unsupported scheme %q in source URL, only http and https are supported", u.Scheme)
+	}
+	maxSize := DefaultMaxDownloadSize
+	if p.Options() != nil && p.Options().MaxDownloadSize != 0 {
+		maxSize = p.Options().MaxDownloadSize
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return errors.Wrap(err, "building download request")
+	}
+	// Tokens can be passed as user names, log the URL without them
+	logged := *u
+	logged.User = nil
+	p.logger().Infof("Downloading package source from %s", logged.String())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "downloading package source")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("downloading package source: server returned %s", resp.Status)
+	}
+	if maxSize > 0 && resp.ContentLength > maxSize {
+		return errors.Errorf(
+			"package source is %d bytes, larger than the %d bytes limit", resp.ContentLength, maxSize,
+		)
+	}
+
+	tmp, err := os.CreateTemp("", "spdx-source-")
+	if err != nil {
+		return errors.Wrap(err, "creating temporary file")
+	}
+	success := false
+	defer func() {
+		tmp.Close()
+		if !success {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	// Read one byte past the limit to detect oversized downloads
+	var body io.Reader = resp.Body
+	if maxSize > 0 {
+		body = io.LimitReader(resp.Body, maxSize+1)
+	}
+	s256 := sha256.New()
+	s512 := sha512.New()
+	n, err := io.Copy(io.MultiWriter(tmp, s256, s512), body)
+	if err != nil {
+		return errors.Wrap(err, "writing package source to disk")
+	}
+	if maxSize > 0 && n > maxSize {
+		return errors.Errorf("package source exceeds the %d bytes limit", maxSize)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "closing temporary file")
+	}
+
+	p.Checksum = map[string]string{
+		"SHA256": fmt.Sprintf("%x", s256.Sum(nil)),
+		"SHA512": fmt.Sprintf("%x", s512.Sum(nil)),
+	}
+	p.SetDownloadLocation(u.String())
+	p.SourceFile = tmp.Name()
+	if name := path.Base(u.Path); name != "." && name != "/" {
+		p.FileName = name
+		p.ArchiveFileName = name
+	}
+	success = true
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSourceURL(t *testing.T) {
+	content := []byte("package source contents\n")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/releases/hello-1.0.tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(content) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	pkg := NewPackage()
+	source := server.URL + "/releases/hello-1.0.tar.gz"
+	require.Nil(t, pkg.ReadSourceURL(context.Background(), source))
+	defer os.Remove(pkg.SourceFile)
+
+	require.Equal(t, source, pkg.DownloadLocation)
+	require.Equal(t, "hello-1.0.tar.gz", pkg.FileName)
+	require.Equal(t, fmt.Sprintf("%x", sha256.Sum256(content)), pkg.Checksum["SHA256"])
+	require.Len(t, pkg.Checksum["SHA512"], 128)
+	data, err := os.ReadFile(pkg.SourceFile)
+	require.Nil(t, err)
+	require.Equal(t, content, data)
+
+	// Missing files fail
+	pkg = NewPackage()
+	require.NotNil(t, pkg.ReadSourceURL(context.Background(), server.URL+"/missing.tar.gz"))
+	require.Empty(t, pkg.SourceFile)
+
+	// Downloads over the limit fail
+	pkg = NewPackage()
+	pkg.Options().MaxDownloadSize = 10
+	require.NotNil(t, pkg.ReadSourceURL(context.Background(), source))
+	require.Empty(t, pkg.Checksum)
+
+	// Credentials are used but not recorded
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || !(user == "user" && pass == "secret" || user == "TOKEN" && pass == "") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write(content) // nolint: errcheck
+	}))
+	defer authServer.Close()
+	authURL, err := url.Parse(authServer.URL + "/hello-1.0.tar.gz")
+	require.Nil(t, err)
+	authURL.User = url.UserPassword("user", "secret")
+	logger := &testLogger{}
+	pkg = NewPackage()
+	pkg.Options().Logger = logger
+	require.Nil(t, pkg.ReadSourceURL(context.Background(), authURL.String()))
+	defer os.Remove(pkg.SourceFile)
+	require.Equal(t, authServer.URL+"/hello-1.0.tar.gz", pkg.DownloadLocation)
+	require.NotEmpty(t, pkg.DownloadLocationComment)
+	for _, message := range logger.messages {
+		require.NotContains(t, message, "secret")
+	}
+
+	// So are tokens passed as user names
+	authURL.User = url.User("TOKEN")
+	logger = &testLogger{}
+	pkg = NewPackage()
+	pkg.Options().Logger = logger
+	require.Nil(t, pkg.ReadSourceURL(context.Background(), authURL.String()))
+	defer os.Remove(pkg.SourceFile)
+	require.Equal(t, authServer.URL+"/hello-1.0.tar.gz", pkg.DownloadLocation)
+	require.NotEmpty(t, pkg.DownloadLocationComment)
+	for _, message := range logger.messages {
+		require.NotContains(t, message, "TOKEN")
+	}
+
+	// Only http and https are supported
+	for _, source := range []string{"file:///etc/passwd", "ftp://example.com/hello.tar.gz", "hello.tar.gz"} {
+		pkg = NewPackage()
+		err := pkg.ReadSourceURL(context.Background(), source)
+		require.NotNil(t, err, source)
+		require.Contains(t, err.Error(), "unsupported scheme", source)
+	}
+
+	// Canceled contexts abort the download
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pkg = NewPackage()
+	require.NotNil(t, pkg.ReadSourceURL(ctx, source))
+}
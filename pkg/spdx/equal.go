@@ -0,0 +1,298 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"reflect"
+)
+
+// Equal returns true if the package and other hold the same data. All
+// the exported fields are compared, including the files, by ID and
+// content, and the trees of subpackages and dependencies, recursively.
+// Map ordering, locks, options and file providers are ignored, and empty
+// lists and maps are equal to nil ones. Relationships are compared by
+// type and peer ID.
+func (p *Package) Equal(other *Package) bool {
+	return packagesEqual(p, other, map[[2]*Package]struct{}{})
+}
+
+// packagesEqual compares two packages. Pairs of packages already being
+// compared up the tree are considered equal, so cycles terminate.
+func packagesEqual(a, b *Package, visiting map[[2]*Package]struct{}) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	pair := [2]*Package{a, b}
+	if _, ok := visiting[pair]; ok {
+		return true
+	}
+	visiting[pair] = struct{}{}
+
+	a.RLock()
+	defer a.RUnlock()
+	b.RLock()
+	defer b.RUnlock()
+
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	for i := 0; i < va.NumField(); i++ {
+		field := va.Type().Field(i)
+		// Skip the unexported fields and the embedded mutex
+		if field.PkgPath != "" || field.Anonymous {
+			continue
+		}
+		switch field.Name {
+		case "FileProvider":
+			continue
+		case "Files":
+			if !fileMapsEqual(a.Files, b.Files, visiting) {
+				return false
+			}
+		case "Packages", "Dependencies":
+			if !packageMapsEqual(
+				va.Field(i).Interface().(map[string]*Package),
+				vb.Field(i).Interface().(map[string]*Package),
+				visiting,
+			) {
+				return false
+			}
+		case "Relationships":
+			if !relationshipsEqual(a.Relationships, b.Relationships) {
+				return false
+			}
+		default:
+			if !valuesEqual(va.Field(i), vb.Field(i)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func packageMapsEqual(a, b map[string]*Package, visiting map[[2]*Package]struct{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for id, pa := range a {
+		pb, ok := b[id]
+		if !ok || !packagesEqual(pa, pb, visiting) {
+			return false
+		}
+	}
+	return true
+}
+
+func fileMapsEqual(a, b map[string]*File, visiting map[[2]*Package]struct{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for id, fa := range a {
+		fb, ok := b[id]
+		if !ok || !filesEqual(fa, fb) {
+			return false
+		}
+	}
+	return true
+}
+
+// filesEqual compares the exported fields of two files
+func filesEqual(a, b *File) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	for i := 0; i < va.NumField(); i++ {
+		field := va.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if field.Name == "Relationships" {
+			if !relationshipsEqual(a.Relationships, b.Relationships) {
+				return false
+			}
+			continue
+		}
+		if !valuesEqual(va.Field(i), vb.Field(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// relationshipsEqual compares two lists of relationships by type and
+// peer ID, in order
+func relationshipsEqual(a, b []*Relationship) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type || a[i].peerID() != b[i].peerID() {
+			return false
+		}
+	}
+	return true
+}
+
+// valuesEqual compares two field values, considering empty and nil
+// lists and maps equal
+func valuesEqual(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Map, reflect.Slice:
+		if a.Len() == 0 && b.Len() == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// Clone returns a deep copy of the package, including the files,
+// subpackages, dependencies and relationship peers it links to.
+// Elements reachable through more than one path, or through cycles,
+// are copied once. The FileProvider, if any, is shared with the copy.
+func (p *Package) Clone() *Package {
+	return p.clone(map[*Package]*Package{}, map[*File]*File{})
+}
+
+func (p *Package) clone(packages map[*Package]*Package, files map[*File]*File) *Package {
+	if c, ok := packages[p]; ok {
+		return c
+	}
+	c := NewPackage()
+	packages[p] = c
+
+	p.RLock()
+	defer p.RUnlock()
+
+	// Copy the exported fields, then replace the ones holding
+	// references with copies
+	copyExportedFields(c, p)
+	if p.options != nil {
+		options := *p.options
+		c.options = &options
+	}
+	c.LicenseInfoFromFiles = cloneStrings(p.LicenseInfoFromFiles)
+	c.AttributionText = cloneStrings(p.AttributionText)
+	c.Checksum = cloneStringMap(p.Checksum)
+	c.ExternalRefs = append([]ExternalRef(nil), p.ExternalRefs...)
+	c.Files = nil
+	if p.Files != nil {
+		c.Files = make(map[string]*File, len(p.Files))
+		for id, f := range p.Files {
+			c.Files[id] = f.clone(packages, files)
+			c.hashes.add(id, c.Files[id].Checksum["SHA1"])
+		}
+	}
+	c.Packages = clonePackageMap(p.Packages, packages, files)
+	c.Dependencies = clonePackageMap(p.Dependencies, packages, files)
+	c.Relationships = cloneRelationships(p.Relationships, packages, files)
+	return c
+}
+
+// copyExportedFields makes a shallow copy of the exported fields of src
+// into dst, leaving the mutex alone
+func copyExportedFields(dst, src *Package) {
+	vs, vd := reflect.ValueOf(src).Elem(), reflect.ValueOf(dst).Elem()
+	for i := 0; i < vs.NumField(); i++ {
+		field := vs.Type().Field(i)
+		if field.PkgPath == "" && !field.Anonymous {
+			vd.Field(i).Set(vs.Field(i))
+		}
+	}
+}
+
+func (f *File) clone(packages map[*Package]*Package, files map[*File]*File) *File {
+	if c, ok := files[f]; ok {
+		return c
+	}
+	c := &File{}
+	*c = *f
+	files[f] = c
+	if f.options != nil {
+		options := *f.options
+		c.options = &options
+	}
+	c.LicenseInfoInFile = cloneStrings(f.LicenseInfoInFile)
+	c.FileType = cloneStrings(f.FileType)
+	c.AttributionText = cloneStrings(f.AttributionText)
+	c.Checksum = cloneStringMap(f.Checksum)
+	c.Relationships = cloneRelationships(f.Relationships, packages, files)
+	if f.Snippets != nil {
+		c.Snippets = make([]*Snippet, len(f.Snippets))
+		for i, s := range f.Snippets {
+			snippet := *s
+			snippet.LicenseInfoInSnippet = cloneStrings(s.LicenseInfoInSnippet)
+			c.Snippets[i] = &snippet
+		}
+	}
+	return c
+}
+
+func clonePackageMap(
+	list map[string]*Package, packages map[*Package]*Package, files map[*File]*File,
+) map[string]*Package {
+	if list == nil {
+		return nil
+	}
+	c := make(map[string]*Package, len(list))
+	for id, pkg := range list {
+		c[id] = pkg.clone(packages, files)
+	}
+	return c
+}
+
+func cloneRelationships(
+	list []*Relationship, packages map[*Package]*Package, files map[*File]*File,
+) []*Relationship {
+	if list == nil {
+		return nil
+	}
+	c := make([]*Relationship, 0, len(list))
+	for _, r := range list {
+		cr := *r
+		if r.Package != nil {
+			cr.Package = r.Package.clone(packages, files)
+		}
+		if r.File != nil {
+			cr.File = r.File.clone(packages, files)
+		}
+		c = append(c, &cr)
+	}
+	return c
+}
+
+func cloneStrings(list []string) []string {
+	if list == nil {
+		return nil
+	}
+	return append([]string{}, list...)
+}
+
+func cloneStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// License is an SPDX license expression built from license identifiers
+// with And and Or. The builder parenthesizes compound operands joined
+// by a different operator, so the grouping is always explicit.
+type License struct {
+	expr string
+	op   string // Top level operator of the expression, empty for simple ones
+}
+
+// NewLicense returns a license expression of a single license identifier
+func NewLicense(id string) License {
+	return License{expr: strings.TrimSpace(id)}
+}
+
+// NewLicenseWithException returns a license expression of a single
+// license identifier with an exception applied. Exceptions are only
+// valid on license identifiers, so there is no way to add them to
+// compound expressions.
+func NewLicenseWithException(id, exception string) License {
+	return License{
+		expr: strings.TrimSpace(id) + " WITH " + strings.TrimSpace(exception), op: "WITH",
+	}
+}
+
+// String returns the SPDX expression of the license
+func (l License) String() string {
+	return l.expr
+}
+
+// And returns an expression requiring the license and all of others
+func (l License) And(others ...License) License {
+	return l.combine("AND", others)
+}
+
+// Or returns an expression offering a choice between the license and others
+func (l License) Or(others ...License) License {
+	return l.combine("OR", others)
+}
+
+// combine joins the license and others with op
+func (l License) combine(op string, others []License) License {
+	if len(others) == 0 {
+		return l
+	}
+	parts := []string{l.operand(op)}
+	for _, other := range others {
+		parts = append(parts, other.operand(op))
+	}
+	return License{expr: strings.Join(parts, " "+op+" "), op: op}
+}
+
+// operand returns the expression to be used as an operand of op,
+// parenthesized when it is joined by a different AND or OR operator
+func (l License) operand(op string) string {
+	if l.op == "" || l.op == "WITH" || l.op == op {
+		return l.expr
+	}
+	return "(" + l.expr + ")"
+}
+
+// licenseOperators are the operators of license expressions
+var licenseOperators = map[string]struct{}{
+	"AND": {}, "OR": {}, "WITH": {},
+}
+
+// LicenseNode is a node of a parsed license expression, as produced by
+// license scanners. Leaf nodes hold a license and optionally an
+// exception, compound nodes join their operands with AND or OR.
+type LicenseNode struct {
+	License   string         // License identifier of leaf nodes
+	Exception string         // Exception applied to the license of leaf nodes
+	Operator  string         // AND or OR for compound nodes
+	Operands  []*LicenseNode // Operands of compound nodes
+}
+
+// Expression returns the canonical license expression of the tree
+func (n *LicenseNode) Expression() (License, error) {
+	if n.Operator == "" {
+		if strings.TrimSpace(n.License) == "" {
+			return License{}, errors.New("license expression node has no license")
+		}
+		if n.Exception != "" {
+			return NewLicenseWithException(n.License, n.Exception), nil
+		}
+		return NewLicense(n.License), nil
+	}
+
+	op := strings.ToUpper(n.Operator)
+	if op != "AND" && op != "OR" {
+		return License{}, errors.Errorf("invalid license expression operator %q", n.Operator)
+	}
+	if len(n.Operands) == 0 {
+		return License{}, errors.Errorf("%s license expression node has no operands", op)
+	}
+	operands := make([]License, len(n.Operands))
+	for i, operand := range n.Operands {
+		l, err := operand.Expression()
+		if err != nil {
+			return License{}, err
+		}
+		operands[i] = l
+	}
+	return operands[0].combine(op, operands[1:]), nil
+}
+
+// FromSPDXExpression sets the concluded and declared licenses of the
+// package from parsed license expressions, storing them in their
+// canonical form. Nil trees leave the corresponding field unchanged.
+func (p *Package) FromSPDXExpression(concluded, declared *LicenseNode) error {
+	exprs := make([]string, 2)
+	for i, tree := range []*LicenseNode{concluded, declared} {
+		if tree == nil {
+			continue
+		}
+		l, err := tree.Expression()
+		if err != nil {
+			return errors.Wrap(err, "building license expression")
+		}
+		if err := ValidateLicenseExpression(l.String()); err != nil {
+			return err
+		}
+		exprs[i] = l.String()
+	}
+	if concluded != nil {
+		p.LicenseConcluded = exprs[0]
+	}
+	if declared != nil {
+		p.LicenseDeclared = exprs[1]
+	}
+	return nil
+}
+
+// SetLicenseDeclaredExpr sets the declared license of the package from
+// an expression built with License, the expression has to be valid
+func (p *Package) SetLicenseDeclaredExpr(l License) error {
+	if err := ValidateLicenseExpression(l.String()); err != nil {
+		return errors.Wrap(err, "validating declared license")
+	}
+	p.LicenseDeclared = l.String()
+	return nil
+}
+
+var (
+	// licenseExprTokenRe splits license expressions in parentheses and words
+	licenseExprTokenRe = regexp.MustCompile(`[()]|[^\s()]+`)
+	// licenseIDRe matches license identifiers and references
+	licenseIDRe = regexp.MustCompile(
+		`^(DocumentRef-[A-Za-z0-9.-]+:)?LicenseRef-[A-Za-z0-9.-]+$|^[A-Za-z0-9.-]+\+?$`,
+	)
+	// licenseExceptionRe matches license exception identifiers
+	licenseExceptionRe = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+)
+
+// ValidateLicenseExpression checks that expr follows the grammar of
+// SPDX license expressions. Empty expressions, NONE and NOASSERTION
+// are accepted.
+func ValidateLicenseExpression(expr string) error {
+	if expr == "" || expr == NONE || expr == NOASSERTION {
+		return nil
+	}
+	parser := &licenseExprParser{tokens: licenseExprTokenRe.FindAllString(expr, -1)}
+	if err := parser.compound(); err != nil {
+		return errors.Wrapf(err, "parsing license expression %q", expr)
+	}
+	if parser.pos < len(parser.tokens) {
+		return errors.Errorf(
+			"parsing license expression %q: unexpected %q", expr, parser.tokens[parser.pos],
+		)
+	}
+	return nil
+}
+
+// licenseExprParser is a recursive descent parser of license expressions
+type licenseExprParser struct {
+	tokens []string
+	pos    int
+}
+
+// next returns the next token without consuming it
+func (lp *licenseExprParser) next() string {
+	if lp.pos < len(lp.tokens) {
+		return lp.tokens[lp.pos]
+	}
+	return ""
+}
+
+// compound parses expressions joined by OR
+func (lp *licenseExprParser) compound() error {
+	if err := lp.and(); err != nil {
+		return err
+	}
+	for lp.next() == "OR" {
+		lp.pos++
+		if err := lp.and(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// and parses expressions joined by AND
+func (lp *licenseExprParser) and() error {
+	if err := lp.primary(); err != nil {
+		return err
+	}
+	for lp.next() == "AND" {
+		lp.pos++
+		if err := lp.primary(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// primary parses a parenthesized expression or a license identifier
+// with an optional exception
+func (lp *licenseExprParser) primary() error {
+	token := lp.next()
+	switch token {
+	case "":
+		return errors.New("unexpected end of expression")
+	case "(":
+		lp.pos++
+		if err := lp.compound(); err != nil {
+			return err
+		}
+		if lp.next() != ")" {
+			return errors.New("missing closing parenthesis")
+		}
+		lp.pos++
+		if lp.next() == "WITH" {
+			return errors.New("exceptions can only be applied to license identifiers")
+		}
+		return nil
+	case ")", "AND", "OR", "WITH", NONE, NOASSERTION:
+		return errors.Errorf("unexpected %q", token)
+	}
+	if !licenseIDRe.MatchString(token) {
+		return errors.Errorf("invalid license identifier %q", token)
+	}
+	lp.pos++
+	if lp.next() == "WITH" {
+		lp.pos++
+		exception := lp.next()
+		_, isOperator := licenseOperators[exception]
+		if !licenseExceptionRe.MatchString(exception) || isOperator {
+			return errors.Errorf("invalid license exception %q", exception)
+		}
+		lp.pos++
+	}
+	return nil
+}
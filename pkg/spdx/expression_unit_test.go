@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLicenseBuilder(t *testing.T) {
+	mit, apache, bsd := NewLicense("MIT"), NewLicense("Apache-2.0"), NewLicense("BSD-3-Clause")
+	for _, tc := range []struct {
+		license  License
+		expected string
+	}{
+		{mit.Or(apache).And(bsd), "(MIT OR Apache-2.0) AND BSD-3-Clause"},
+		{mit.And(apache).Or(bsd), "(MIT AND Apache-2.0) OR BSD-3-Clause"},
+		{mit.And(apache.Or(bsd)), "MIT AND (Apache-2.0 OR BSD-3-Clause)"},
+		{mit.Or(apache, bsd), "MIT OR Apache-2.0 OR BSD-3-Clause"},
+		{NewLicenseWithException("GPL-2.0-only", "Classpath-exception-2.0").Or(mit), "GPL-2.0-only WITH Classpath-exception-2.0 OR MIT"},
+		{mit.And(), "MIT"},
+	} {
+		require.Equal(t, tc.expected, tc.license.String())
+		require.Nil(t, ValidateLicenseExpression(tc.license.String()), tc.expected)
+	}
+
+	pkg := NewPackage()
+	pkg.Name = "declared"
+	require.Nil(t, pkg.SetLicenseDeclaredExpr(mit.Or(apache).And(bsd)))
+	require.Equal(t, "(MIT OR Apache-2.0) AND BSD-3-Clause", pkg.LicenseDeclared)
+	require.Nil(t, pkg.Validate())
+
+	// Invalid expressions leave the declared license unchanged
+	require.NotNil(t, pkg.SetLicenseDeclaredExpr(NewLicense("MIT License").Or(apache)))
+	require.Equal(t, "(MIT OR Apache-2.0) AND BSD-3-Clause", pkg.LicenseDeclared)
+}
+
+func TestValidateLicenseExpression(t *testing.T) {
+	for _, expr := range []string{
+		"", NONE, NOASSERTION, "MIT", "GPL-2.0+", "LicenseRef-custom",
+		"DocumentRef-other:LicenseRef-custom", "((MIT))", "MIT AND (Apache-2.0 OR BSD-3-Clause)",
+	} {
+		require.Nil(t, ValidateLicenseExpression(expr), expr)
+	}
+	for _, expr := range []string{
+		"MIT AND", "OR MIT", "(MIT", "MIT)", "MIT Apache-2.0", "MIT WITH", "MIT WITH AND",
+		"(MIT OR Apache-2.0) WITH Classpath-exception-2.0", "MIT AND NONE", "MIT/Apache-2.0",
+	} {
+		require.NotNil(t, ValidateLicenseExpression(expr), expr)
+	}
+}
+
+func TestFromSPDXExpression(t *testing.T) {
+	// MIT OR (Apache-2.0 AND BSD-2-Clause)
+	tree := &LicenseNode{
+		Operator: "or",
+		Operands: []*LicenseNode{
+			{License: " MIT "},
+			{Operator: "AND", Operands: []*LicenseNode{
+				{License: "Apache-2.0"}, {License: "BSD-2-Clause"},
+			}},
+		},
+	}
+	pkg := NewPackage()
+	pkg.LicenseDeclared = "MIT"
+	require.Nil(t, pkg.FromSPDXExpression(tree, nil))
+	require.Equal(t, "MIT OR (Apache-2.0 AND BSD-2-Clause)", pkg.LicenseConcluded)
+	require.Equal(t, "MIT", pkg.LicenseDeclared)
+
+	require.Nil(t, pkg.FromSPDXExpression(nil, &LicenseNode{
+		License: "GPL-2.0-only", Exception: "Classpath-exception-2.0",
+	}))
+	require.Equal(t, "GPL-2.0-only WITH Classpath-exception-2.0", pkg.LicenseDeclared)
+
+	// Invalid trees leave the package untouched
+	for _, invalid := range []*LicenseNode{
+		{},
+		{Operator: "XOR", Operands: []*LicenseNode{{License: "MIT"}}},
+		{Operator: "AND"},
+		{License: "MIT/Apache-2.0"},
+	} {
+		require.NotNil(t, pkg.FromSPDXExpression(invalid, invalid))
+	}
+	require.Equal(t, "MIT OR (Apache-2.0 AND BSD-2-Clause)", pkg.LicenseConcluded)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	externalDocumentRefPrefix = "DocumentRef-"
+	externalDocumentIDRe      = `^DocumentRef-[a-zA-Z0-9.-]+$`
+
+	// TreeIndexFileName is the name of the main document written by
+	// Document.WriteTree
+	TreeIndexFileName = "index.spdx"
+)
+
+// ExternalDocumentRef is a reference to another SPDX document. Elements
+// of the external document are referred to as <ID>:<element ID>.
+type ExternalDocumentRef struct {
+	ID        string            // DocumentRef-kubernetes
+	URI       string            // Namespace of the external document
+	Checksum  map[string]string // Checksum of the external document, SPDX requires a SHA1
+	Describes []string          // IDs of the elements of the external document described by this one
+}
+
+// validate checks the reference can be rendered
+func (r *ExternalDocumentRef) validate() error {
+	if !regexp.MustCompile(externalDocumentIDRe).MatchString(r.ID) {
+		return errors.Errorf("invalid external document ID %q", r.ID)
+	}
+	if r.URI == "" {
+		return errors.Errorf("external document %s has no URI", r.ID)
+	}
+	if err := validateChecksum("SHA1", r.Checksum["SHA1"]); err != nil {
+		return errors.Wrapf(err, "external document %s", r.ID)
+	}
+	return nil
+}
+
+// checkExternalDocumentRefs validates the external documents referenced
+// by the document
+func (d *Document) checkExternalDocumentRefs() error {
+	seen := map[string]struct{}{}
+	for i := range d.ExternalDocumentRefs {
+		ref := &d.ExternalDocumentRefs[i]
+		if err := ref.validate(); err != nil {
+			return err
+		}
+		if _, ok := seen[ref.ID]; ok {
+			return errors.Errorf("duplicate external document ID %s", ref.ID)
+		}
+		seen[ref.ID] = struct{}{}
+	}
+	return nil
+}
+
+// externalDescribedIDs returns the references to the elements of
+// external documents described by the document
+func (d *Document) externalDescribedIDs() []string {
+	ids := []string{}
+	for _, ref := range d.ExternalDocumentRefs {
+		for _, id := range ref.Describes {
+			ids = append(ids, ref.ID+":"+id)
+		}
+	}
+	return ids
+}
+
+// addExternalDescribed records an element of an external document
+// described by the document, such as DocumentRef-kubernetes:SPDXRef-Package-kubectl.
+// It returns false if the ID does not refer to a known external document.
+func (d *Document) addExternalDescribed(id string) bool {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], externalDocumentRefPrefix) {
+		return false
+	}
+	for i := range d.ExternalDocumentRefs {
+		if d.ExternalDocumentRefs[i].ID == parts[0] {
+			d.ExternalDocumentRefs[i].Describes = append(d.ExternalDocumentRefs[i].Describes, parts[1])
+			return true
+		}
+	}
+	return false
+}
+
+// WriteTree writes the document split in several files in dir: one
+// document for each of its root packages, named after the package ID,
+// and a main document, index.spdx, which references them as external
+// documents. The fragments use the namespace of the document followed
+// by the package ID. Files listed directly in the document are kept
+// in the main document, and packages reachable from more than one
+// root are written in every fragment they appear in.
+func (d *Document) WriteTree(dir string) error {
+	if d.Namespace == "" {
+		return errors.New("document needs a namespace to reference its fragments")
+	}
+	described, err := d.describedIDs()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, os.FileMode(0o755)); err != nil {
+		return errors.Wrap(err, "creating document directory")
+	}
+
+	// All the documents in the tree share the creation time
+	created := d.creationTime()
+	main := *d
+	main.Created = created
+	main.Packages = nil
+	main.ExternalDocumentRefs = append([]ExternalDocumentRef{}, d.ExternalDocumentRefs...)
+	for _, id := range sortedPackageIDs(d.Packages) {
+		name := strings.TrimPrefix(id, "SPDXRef-")
+		fragment := &Document{
+			Version:     d.Version,
+			DataLicense: d.DataLicense,
+			ID:          "SPDXRef-DOCUMENT",
+			Name:        d.Packages[id].Name,
+			Namespace:   strings.TrimSuffix(d.Namespace, "/") + "/" + name,
+			Creator:     d.Creator,
+			Created:     created,
+			Packages:    map[string]*Package{id: d.Packages[id]},
+		}
+		h := sha1.New()
+		if err := fragment.write(filepath.Join(dir, name+".spdx"), h); err != nil {
+			return errors.Wrapf(err, "writing document of package %s", id)
+		}
+		ref := ExternalDocumentRef{
+			ID:       externalDocumentRefPrefix + name,
+			URI:      fragment.Namespace,
+			Checksum: map[string]string{"SHA1": fmt.Sprintf("%x", h.Sum(nil))},
+		}
+		if _, ok := described[id]; ok {
+			ref.Describes = []string{id}
+		}
+		main.ExternalDocumentRefs = append(main.ExternalDocumentRefs, ref)
+	}
+	return main.Write(filepath.Join(dir, TreeIndexFileName))
+}
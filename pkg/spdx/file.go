@@ -17,12 +17,17 @@ limitations under the License.
 package spdx
 
 import (
+	"bufio"
 	"bytes"
 	"crypto/sha1"
-	"html/template"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -34,29 +39,44 @@ var fileTemplate = `{{ if .Name }}FileName: {{ .Name }}
 {{ end -}}
 {{ if .ID }}SPDXID: {{ .ID }}
 {{ end -}}
+{{ range .FileType }}FileType: {{ . }}
+{{ end -}}
 {{- if .Checksum -}}
-{{- range $key, $value := .Checksum -}}
-{{ if . }}FileChecksum: {{ $key }}: {{ $value }}
+{{- range $key := checksumAlgorithms .Checksum -}}
+{{ with index $.Checksum $key }}FileChecksum: {{ $key }}: {{ . }}
 {{ end -}}
 {{- end -}}
 {{- end -}}
 LicenseConcluded: {{ if .LicenseConcluded }}{{ .LicenseConcluded }}{{ else }}NOASSERTION{{ end }}
-LicenseInfoInFile: {{ if .LicenseInfoInFile }}{{ .LicenseInfoInFile }}{{ else }}NOASSERTION{{ end }}
-FileCopyrightText: {{ if .CopyrightText }}<text>{{ .CopyrightText }}
+{{ range .LicenseInfoInFile }}LicenseInfoInFile: {{ . }}
+{{ else }}LicenseInfoInFile: NOASSERTION
+{{ end -}}
+FileCopyrightText: {{ if .CopyrightText }}<text>{{ escapeText .CopyrightText }}
 </text>{{ else }}NOASSERTION{{ end }}
-
+{{ textField "FileNotice" .Notice -}}
+{{ range .AttributionText }}{{ textField "FileAttributionText" . }}{{ end -}}
+{{ textField "FileComment" .Comment }}
 `
 
 // File abstracts a file contained in a package
 type File struct {
-	Name              string // string /Makefile
-	FileName          string // Name of the file
-	ID                string // SPDXRef-Makefile
-	LicenseConcluded  string // GPL-3.0-or-later
-	LicenseInfoInFile string // GPL-3.0-or-later
-	CopyrightText     string // NOASSERTION
-	SourceFile        string // Source file to read from (not part of the spec)
+	Name              string   // string /Makefile
+	FileName          string   // Name of the file
+	ID                string   // SPDXRef-Makefile
+	LicenseConcluded  string   // GPL-3.0-or-later
+	LicenseInfoInFile []string // GPL-3.0-or-later
+	CopyrightText     string   // NOASSERTION
+	SourceFile        string   // Source file to read from (not part of the spec)
+	FileType          []string // SOURCE, BINARY, ARCHIVE, TEXT, etc
+	Comment           string   // Free form comment about the file
+	Size              int64    // Size of the file in bytes (rendered in the comment)
+	Lines             int      // Number of lines of text files (rendered in the comment)
+	AttributionText   []string // Notices required to be reproduced with the file
+	Notice            string   // License notice text found in the file
 	Checksum          map[string]string
+	Relationships     []*Relationship // Relationships to other files or packages
+	GitBlobSHA1       string          // git object ID of the file (not an SPDX checksum)
+	Snippets          []*Snippet      // Parts of the file licensed differently
 
 	options *FileOptions // Options
 }
@@ -72,9 +92,19 @@ func (f *File) Options() *FileOptions {
 	return f.options
 }
 
+// SetLicenseInfoInFile replaces the license information of the file
+// with a single license.
+//
+// Deprecated: LicenseInfoInFile is a list, set it directly or append
+// the licenses found in the file.
+func (f *File) SetLicenseInfoInFile(license string) {
+	f.LicenseInfoInFile = []string{license}
+}
+
 // FileOptions
 type FileOptions struct {
 	WorkDir string
+	UseMmap bool // Hash the file through a memory map where supported
 }
 
 // ReadChecksums receives a path to a file and calculates its checksums
@@ -82,6 +112,16 @@ func (f *File) ReadChecksums(filePath string) error {
 	if f.Checksum == nil {
 		f.Checksum = map[string]string{}
 	}
+	if f.Options() != nil && f.Options().UseMmap {
+		checksums, ok, err := checksumsForMappedFile(filePath)
+		if err != nil {
+			return errors.Wrap(err, "getting file checksums")
+		}
+		if ok {
+			f.Checksum = checksums
+			return nil
+		}
+	}
 	file, err := os.Open(filePath)
 	if err != nil {
 		return errors.Wrap(err, "opening file for reading: "+filePath)
@@ -103,16 +143,127 @@ func (f *File) ReadChecksums(filePath string) error {
 		return errors.Wrap(err, "getting file checksums")
 	}
 
-	f.Checksum = map[string]string{
+	f.Checksum = normalizeChecksums(map[string]string{
 		"SHA1":   s1,
 		"SHA256": s256,
 		"SHA512": s512,
+	})
+	return nil
+}
+
+// fileView is the data passed to the file template
+type fileView struct {
+	*File
+	Comment string
+}
+
+// renderComment returns the file comment, followed by the
+// size and line count of the file when they are known
+func (f *File) renderComment() string {
+	stats := []string{}
+	if f.Size > 0 {
+		stats = append(stats, fmt.Sprintf("Size: %d bytes", f.Size))
+	}
+	if f.Lines > 0 {
+		stats = append(stats, fmt.Sprintf("Lines: %d", f.Lines))
+	}
+	comment := f.Comment
+	if len(stats) > 0 {
+		if comment != "" {
+			comment += "\n"
+		}
+		comment += strings.Join(stats, ", ")
+	}
+	return comment
+}
+
+// fileStatsRe matches the line with the size and line count appended
+// to the file comment by renderComment
+var fileStatsRe = regexp.MustCompile(`^(?:Size: (\d+) bytes(?:, |$))?(?:Lines: (\d+))?$`)
+
+// setParsedComment sets the comment read from a document, moving the
+// size and line count rendered at its end back to their fields
+func (f *File) setParsedComment(comment string) {
+	text, last := "", comment
+	if i := strings.LastIndex(comment, "\n"); i >= 0 {
+		text, last = comment[:i], comment[i+1:]
+	}
+	m := fileStatsRe.FindStringSubmatch(last)
+	if m == nil || (m[1] == "" && m[2] == "") {
+		f.Comment = comment
+		return
+	}
+	size, err := strconv.ParseInt(valueOr(m[1], "0"), 10, 64)
+	if err != nil {
+		f.Comment = comment
+		return
+	}
+	lines, err := strconv.Atoi(valueOr(m[2], "0"))
+	if err != nil {
+		f.Comment = comment
+		return
+	}
+	f.Comment, f.Size, f.Lines = text, size, lines
+}
+
+// readFileStats records the size of a file and, if it is a text
+// file, the number of lines in it
+func (f *File) readFileStats(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "opening file to read its size")
+	}
+	defer file.Close()
+
+	var size int64
+	var lines int
+	binary := false
+	lastByte := byte('\n')
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			size += int64(n)
+			lines += bytes.Count(buf[:n], []byte{'\n'})
+			binary = binary || bytes.IndexByte(buf[:n], 0) != -1
+			lastByte = buf[n-1]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "reading file")
+		}
+	}
+	// Count the last line if it is not terminated
+	if lastByte != '\n' {
+		lines++
+	}
+	f.Size = size
+	f.Lines = 0
+	if !binary {
+		f.Lines = lines
 	}
 	return nil
 }
 
 // Render renders the document fragment of a file
 func (f *File) Render() (docFragment string, err error) {
+	var sb strings.Builder
+	state := newRenderState()
+	state.out = &sb
+	if err := walkFile(state, f, &tagValueWriter{state: state}); err != nil {
+		return "", err
+	}
+	if state.err != nil {
+		return "", state.err
+	}
+	return sb.String(), nil
+}
+
+// render renders the body of the file, its relationships are rendered
+// by the tree traversal
+func (f *File) render() (docFragment string, err error) {
 	// If we have not yet checksummed the file, do it now:
 	if f.Checksum == nil || len(f.Checksum) == 0 {
 		if f.SourceFile != "" {
@@ -126,18 +277,158 @@ func (f *File) Render() (docFragment string, err error) {
 		}
 	}
 	var buf bytes.Buffer
-	tmpl, err := template.New("file").Parse(fileTemplate)
+	tmpl, err := template.New("file").Funcs(templateFuncs).Parse(fileTemplate)
 	if err != nil {
 		return "", errors.Wrap(err, "parsing file template")
 	}
 
 	// Run the template to verify the output.
-	if err := tmpl.Execute(&buf, f); err != nil {
+	if err := tmpl.Execute(&buf, &fileView{File: f, Comment: f.renderComment()}); err != nil {
 		return "", errors.Wrap(err, "executing spdx file template")
 	}
 
-	docFragment = buf.String()
-	return docFragment, nil
+	snippets, err := f.renderSnippets()
+	if err != nil {
+		return "", errors.Wrap(err, "rendering file snippets")
+	}
+	buf.WriteString(snippets)
+	return buf.String(), nil
+}
+
+// GitBlobSHA computes the git blob object ID of the file, the sha1 of
+// its contents prefixed by a "blob <size>\0" header, as computed by
+// git hash-object. The file is read from workDir joined with its name
+// or, if workDir is empty, from its SourceFile. The result is stored in
+// GitBlobSHA1 and not used for the SPDX checksums.
+func (f *File) GitBlobSHA(workDir string) (string, error) {
+	path := f.SourceFile
+	if workDir != "" {
+		path = filepath.Join(workDir, f.Name)
+	}
+	if path == "" {
+		return "", errors.New("unable to compute git blob sha, file has no source")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrap(err, "opening file for reading: "+path)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", errors.Wrap(err, "getting file size")
+	}
+
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", info.Size())
+	if _, err := io.Copy(h, file); err != nil {
+		return "", errors.Wrap(err, "hashing file contents")
+	}
+	f.GitBlobSHA1 = fmt.Sprintf("%x", h.Sum(nil))
+	return f.GitBlobSHA1, nil
+}
+
+// VerifyAgainst computes the checksum of the file with algorithm and
+// compares it to expected, returning a *ChecksumMismatchError if they
+// differ. The file is read from workDir joined with its name or, if
+// workDir is empty, from its SourceFile. The checksums of the file are
+// not modified.
+func (f *File) VerifyAgainst(workDir, algorithm, expected string) error {
+	expected = strings.ToLower(expected)
+	if err := validateChecksum(algorithm, expected); err != nil {
+		return errors.Wrap(err, "checking expected checksum")
+	}
+	newHash, ok := checksumHashes[algorithm]
+	if !ok {
+		return errors.Errorf("computing %s checksums is not supported", algorithm)
+	}
+	path := f.SourceFile
+	if workDir != "" {
+		path = filepath.Join(workDir, f.Name)
+	}
+	if path == "" {
+		return errors.New("unable to verify checksum, file has no source")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "opening file for reading: "+path)
+	}
+	defer file.Close()
+
+	h := newHash()
+	if _, err := io.Copy(h, file); err != nil {
+		return errors.Wrap(err, "hashing file contents")
+	}
+	if actual := fmt.Sprintf("%x", h.Sum(nil)); actual != expected {
+		return &ChecksumMismatchError{
+			Name: f.Name, Algorithm: algorithm, Expected: expected, Actual: actual,
+		}
+	}
+	return nil
+}
+
+// noticeFileNames are the names of files holding license notices
+var noticeFileNames = map[string]struct{}{
+	"notice": {}, "notice.txt": {}, "notice.md": {},
+}
+
+// ReadNotice records the contents of path as the file notice if it is
+// a license notice file, such as those required by Apache-2.0
+func (f *File) ReadNotice(path string) error {
+	if _, ok := noticeFileNames[strings.ToLower(filepath.Base(path))]; !ok {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "reading notice file")
+	}
+	f.Notice = strings.TrimSpace(string(data))
+	return nil
+}
+
+// maxCopyrightScan is how much of a file ReadCopyright reads looking
+// for copyright notices
+const maxCopyrightScan = 64 * 1024
+
+// copyrightRe matches the copyright notices found in source file
+// headers, such as "// Copyright (c) 2021 The Kubernetes Authors."
+var copyrightRe = regexp.MustCompile(`(?i)^[\s/#*;!-]*(copyright\s*(\(c\)|©)?\s*\d{4}.*?)[\s*/]*$`)
+
+// ReadCopyright sets the copyright text of the file from the copyright
+// notices in the beginning of the file at path. Binary files and files
+// without notices are left unchanged.
+func (f *File) ReadCopyright(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "opening file to read its copyright")
+	}
+	defer file.Close()
+
+	notices := []string{}
+	seen := map[string]struct{}{}
+	scanner := bufio.NewScanner(io.LimitReader(file, maxCopyrightScan))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.IndexByte(line, 0) != -1 {
+			return nil
+		}
+		m := copyrightRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if _, ok := seen[m[1]]; !ok {
+			seen[m[1]] = struct{}{}
+			notices = append(notices, m[1])
+		}
+	}
+	// Lines longer than the scanner buffer end the scan, the notices
+	// are expected in the file header anyway
+	if err := scanner.Err(); err != nil && err != bufio.ErrTooLong {
+		return errors.Wrap(err, "reading file")
+	}
+	if len(notices) > 0 {
+		f.CopyrightText = strings.Join(notices, "\n")
+	}
+	return nil
 }
 
 // ReadSourceFile reads the source file for the package and populates
@@ -151,6 +442,10 @@ func (f *File) ReadSourceFile(path string) error {
 		return errors.Wrap(err, "reading file checksums")
 	}
 
+	if err := f.readFileStats(path); err != nil {
+		return errors.Wrap(err, "reading file size")
+	}
+
 	f.SourceFile = path
 	f.Name = strings.TrimPrefix(
 		path, f.Options().WorkDir+string(filepath.Separator),
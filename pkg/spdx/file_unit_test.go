@@ -0,0 +1,354 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFileType(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spdx-filetype-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	elf := append([]byte("\x7fELF\x02\x01\x01"), make([]byte, 64)...)
+	// A DOS header with e_lfanew pointing to the PE signature
+	pe := make([]byte, 0x90)
+	copy(pe, "MZ")
+	pe[0x3c] = 0x80
+	copy(pe[0x80:], "PE\x00\x00")
+	// Text starting with MZ is not an executable
+	mz := []byte("MZ" + strings.Repeat(" ", 0x3a) + "PE offset is not valid " + strings.Repeat("text ", 20))
+	for _, tc := range []struct {
+		name     string
+		data     []byte
+		expected []string
+	}{
+		{"build", []byte("#!/bin/bash\necho hello\n"), []string{FileTypeText, FileTypeSource}},
+		{"hello", elf, []string{FileTypeBinary}},
+		{"hello.exe", pe, []string{FileTypeBinary}},
+		{"MZ.txt", mz, []string{FileTypeText}},
+		{"mz-notes", []byte("MZ notes\n"), []string{FileTypeText}},
+		{"README", []byte("This is a plain text file\n"), []string{FileTypeText}},
+		{"main.go", []byte("package main\n"), []string{FileTypeText, FileTypeSource}},
+		{"Makefile", []byte("all:\n\tgo build\n"), []string{FileTypeText, FileTypeSource}},
+		{"sources.zip", []byte("PK\x03\x04\x14\x00"), []string{FileTypeArchive}},
+		{"data.bin", []byte{0x01, 0x00, 0x02, 0x03}, []string{FileTypeBinary}},
+		// A multibyte character cut at the end of the sniffed bytes
+		{"long.txt", []byte(strings.Repeat("a", fileTypeSniffLength-1) + "é"), []string{FileTypeText}},
+	} {
+		require.Nil(t, os.WriteFile(filepath.Join(dir, tc.name), tc.data, os.FileMode(0o644)))
+		f := NewFile()
+		f.Name = tc.name
+		require.Nil(t, f.DetectFileType(dir))
+		require.Equal(t, tc.expected, f.FileType, tc.name)
+	}
+
+	f := NewFile()
+	f.Name = "non-existent"
+	require.NotNil(t, f.DetectFileType(dir))
+}
+
+func TestFileSizeAndLines(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spdx-filesize-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "text.txt")
+	require.Nil(t, os.WriteFile(path, []byte("one\ntwo\nthree"), os.FileMode(0o644)))
+
+	f := NewFile()
+	f.Options().WorkDir = dir
+	require.Nil(t, f.ReadSourceFile(path))
+	require.Equal(t, int64(13), f.Size)
+	require.Equal(t, 3, f.Lines)
+
+	doc, err := f.Render()
+	require.Nil(t, err)
+	require.Contains(t, doc, "FileComment: <text>Size: 13 bytes, Lines: 3\n</text>\n")
+
+	// The values are read back from the tag-value and JSON documents
+	f.Comment = "Generated"
+	pkg := NewPackage()
+	pkg.Name = "stats"
+	pkg.FilesAnalyzed = true
+	require.Nil(t, pkg.AddFile(f))
+	spdxDoc := NewDocument()
+	spdxDoc.Name = "stats"
+	require.Nil(t, spdxDoc.AddPackage(pkg))
+	out, err := spdxDoc.Render()
+	require.Nil(t, err)
+	parsed, err := ParseTagValue(strings.NewReader(out))
+	require.Nil(t, err)
+	data, err := spdxDoc.RenderJSON()
+	require.Nil(t, err)
+	require.Contains(t, string(data), `"comment": "Generated\nSize: 13 bytes, Lines: 3"`)
+	fromJSON, err := ParseJSON(bytes.NewReader(data))
+	require.Nil(t, err)
+	for _, d := range []*Document{parsed, fromJSON} {
+		read := d.Packages[pkg.ID].Files[f.ID]
+		require.Equal(t, "Generated", read.Comment)
+		require.Equal(t, int64(13), read.Size)
+		require.Equal(t, 3, read.Lines)
+	}
+	f.Comment = ""
+
+	// Binary files only record their size
+	path = filepath.Join(dir, "binary")
+	require.Nil(t, os.WriteFile(path, []byte{0x7f, 0x00, '\n', 0x01}, os.FileMode(0o644)))
+	f = NewFile()
+	f.Name = "binary"
+	require.Nil(t, f.DetectFileType(dir))
+	require.Equal(t, int64(4), f.Size)
+	require.Equal(t, 0, f.Lines)
+}
+
+func TestRenderLicenseInfoInFile(t *testing.T) {
+	f := NewFile()
+	f.ID = "SPDXRef-File-multi"
+	f.Name = "./multi.go"
+	f.Checksum = map[string]string{"SHA1": fmt.Sprintf("%040x", 1)}
+	f.LicenseInfoInFile = []string{"Apache-2.0", "MIT"}
+
+	doc, err := f.Render()
+	require.Nil(t, err)
+	require.Contains(t, doc, "LicenseInfoInFile: Apache-2.0\nLicenseInfoInFile: MIT\n")
+
+	pkg := NewPackage()
+	pkg.Name = "multi"
+	pkg.ID = "SPDXRef-Package-multi"
+	pkg.FilesAnalyzed = true
+	require.Nil(t, pkg.AddFile(f))
+	doc, err = pkg.Render()
+	require.Nil(t, err)
+	require.Contains(t, doc, "PackageLicenseInfoFromFiles: Apache-2.0\nPackageLicenseInfoFromFiles: MIT\n")
+
+	f.SetLicenseInfoInFile("MIT")
+	require.Equal(t, []string{"MIT"}, f.LicenseInfoInFile)
+
+	// Files without license information render NOASSERTION
+	f.LicenseInfoInFile = nil
+	doc, err = f.Render()
+	require.Nil(t, err)
+	require.Contains(t, doc, "LicenseInfoInFile: NOASSERTION\n")
+}
+
+func TestGitBlobSHA(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spdx-gitblob-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "hello.txt")
+	require.Nil(t, os.WriteFile(path, []byte("hello\n"), os.FileMode(0o644)))
+
+	f := NewFile()
+	f.Options().WorkDir = dir
+	require.Nil(t, f.ReadSourceFile(path))
+
+	// printf 'hello\n' | git hash-object --stdin
+	expected := "ce013625030ba8dba906f756967f9e9ca394464a"
+	sha, err := f.GitBlobSHA(dir)
+	require.Nil(t, err)
+	require.Equal(t, expected, sha)
+	require.Equal(t, expected, f.GitBlobSHA1)
+	// The SPDX sha1 is the plain checksum of the contents
+	require.Equal(t, "f572d396fae9206628714fb2ce00f72e94f2258f", f.Checksum["SHA1"])
+
+	// Without a working directory the source file is read
+	sha, err = f.GitBlobSHA("")
+	require.Nil(t, err)
+	require.Equal(t, expected, sha)
+
+	_, err = NewFile().GitBlobSHA("")
+	require.NotNil(t, err)
+}
+
+func TestVerifyAgainst(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spdx-verify-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello\n"), os.FileMode(0o644)))
+
+	f := NewFile()
+	f.Name = "hello.txt"
+	sha256 := "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+	require.Nil(t, f.VerifyAgainst(dir, "SHA256", sha256))
+	require.Nil(t, f.VerifyAgainst(dir, "SHA256", strings.ToUpper(sha256)))
+	require.Nil(t, f.VerifyAgainst(dir, "MD5", "b1946ac92492d2347c6235b4d2611184"))
+	require.Empty(t, f.Checksum)
+
+	// A different digest returns a mismatch error
+	wrong := fmt.Sprintf("%064x", 1)
+	err = f.VerifyAgainst(dir, "SHA256", wrong)
+	require.NotNil(t, err)
+	mismatch := &ChecksumMismatchError{}
+	require.True(t, errors.As(err, &mismatch))
+	require.Equal(t, &ChecksumMismatchError{
+		Name: "hello.txt", Algorithm: "SHA256", Expected: wrong, Actual: sha256,
+	}, mismatch)
+	require.Equal(t, "SHA256 checksum mismatch for hello.txt: expected "+wrong+", got "+sha256, err.Error())
+
+	// Invalid expected values and algorithms that can not be computed
+	require.NotNil(t, f.VerifyAgainst(dir, "SHA256", sha256[:40]))
+	require.NotNil(t, f.VerifyAgainst(dir, "BLAKE3", "abcd"))
+	require.NotNil(t, f.VerifyAgainst(filepath.Join(dir, "missing"), "SHA256", sha256))
+}
+
+func TestRenderFileAttributionText(t *testing.T) {
+	f := NewFile()
+	f.ID = "SPDXRef-File-vendored"
+	f.Name = "./vendor/lib.c"
+	f.Checksum = map[string]string{"SHA1": fmt.Sprintf("%040x", 1)}
+	f.LicenseInfoInFile = []string{"MIT"}
+	f.AttributionText = []string{
+		"Copyright (c) 2010 Example Authors",
+		"Portions </text> from another project",
+	}
+
+	doc, err := f.Render()
+	require.Nil(t, err)
+	first := "FileAttributionText: <text>Copyright (c) 2010 Example Authors\n</text>\n"
+	second := "FileAttributionText: <text>Portions &lt;/text&gt; from another project\n</text>\n"
+	require.Contains(t, doc, first+second)
+	require.Greater(t, strings.Index(doc, first), strings.Index(doc, "LicenseInfoInFile: MIT\n"))
+	require.NotContains(t, doc, "\n\n\n")
+
+	f.AttributionText = nil
+	doc, err = f.Render()
+	require.Nil(t, err)
+	require.NotContains(t, doc, "FileAttributionText")
+}
+
+func TestRenderFileNotice(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spdx-notice-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	notice := "Example Project\nCopyright 2021 The Example Authors\n\nThis product includes </text> software."
+	path := filepath.Join(dir, "NOTICE")
+	require.Nil(t, os.WriteFile(path, []byte(notice+"\n"), os.FileMode(0o644)))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), os.FileMode(0o644)))
+
+	f := NewFile()
+	f.Options().WorkDir = dir
+	require.Nil(t, f.ReadSourceFile(path))
+	require.Nil(t, f.ReadNotice(path))
+	require.Equal(t, notice, f.Notice)
+
+	doc, err := f.Render()
+	require.Nil(t, err)
+	require.Contains(t, doc, "FileNotice: <text>Example Project\nCopyright 2021 The Example Authors\n\n"+
+		"This product includes &lt;/text&gt; software.\n</text>\n")
+
+	// Other files do not get a notice
+	other := NewFile()
+	require.Nil(t, other.ReadNotice(filepath.Join(dir, "main.go")))
+	require.Empty(t, other.Notice)
+	doc, err = other.Render()
+	require.Nil(t, err)
+	require.NotContains(t, doc, "FileNotice")
+}
+
+func TestReadChecksumsMmap(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spdx-mmap-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	data := make([]byte, 3*1024*1024+17)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	for name, content := range map[string][]byte{"large.bin": data, "empty.txt": {}} {
+		path := filepath.Join(dir, name)
+		require.Nil(t, os.WriteFile(path, content, 0o644))
+
+		standard := NewFile()
+		require.Nil(t, standard.ReadChecksums(path))
+		mapped := NewFile()
+		mapped.Options().UseMmap = true
+		require.Nil(t, mapped.ReadChecksums(path))
+		require.Equal(t, standard.Checksum, mapped.Checksum, name)
+
+		pkg := NewPackage()
+		require.Nil(t, pkg.ReadSourceFile(path))
+		mappedPkg := NewPackage()
+		mappedPkg.Options().UseMmap = true
+		require.Nil(t, mappedPkg.ReadSourceFile(path))
+		require.Equal(t, pkg.Checksum, mappedPkg.Checksum, name)
+		require.Equal(t, pkg.FileName, mappedPkg.FileName)
+	}
+
+	mapped := NewFile()
+	mapped.Options().UseMmap = true
+	require.NotNil(t, mapped.ReadChecksums(filepath.Join(dir, "missing.bin")))
+}
+
+func benchmarkReadChecksums(b *testing.B, useMmap bool) {
+	dir, err := os.MkdirTemp("", "spdx-mmap-")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "large.bin")
+	if err := os.WriteFile(path, make([]byte, 64*1024*1024), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(64 * 1024 * 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f := NewFile()
+		f.Options().UseMmap = useMmap
+		if err := f.ReadChecksums(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadChecksums(b *testing.B) { benchmarkReadChecksums(b, false) }
+
+func BenchmarkReadChecksumsMmap(b *testing.B) { benchmarkReadChecksums(b, true) }
+
+func TestReadCopyright(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spdx-copyright-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, tc := range []struct {
+		content  string
+		expected string
+	}{
+		{"/*\nCopyright 2021 The Kubernetes Authors.\n*/\n", "Copyright 2021 The Kubernetes Authors."},
+		{"# Copyright (c) 2019-2021 Jane Doe\n# Copyright (c) 2019-2021 Jane Doe\n", "Copyright (c) 2019-2021 Jane Doe"},
+		{" * Copyright © 2020 Example Corp. */\n// copyright 2021 Jane Doe\n", "Copyright © 2020 Example Corp.\ncopyright 2021 Jane Doe"},
+		{"The copyright holders are listed in AUTHORS\n", ""},
+		{"\x00\x01Copyright 2021 Binary\n", ""},
+	} {
+		path := filepath.Join(dir, "file")
+		require.Nil(t, os.WriteFile(path, []byte(tc.content), 0o644))
+		f := NewFile()
+		require.Nil(t, f.ReadCopyright(path))
+		require.Equal(t, tc.expected, f.CopyrightText, tc.content)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// SPDX file types
+const (
+	FileTypeSource        = "SOURCE"
+	FileTypeBinary        = "BINARY"
+	FileTypeArchive       = "ARCHIVE"
+	FileTypeApplication   = "APPLICATION"
+	FileTypeAudio         = "AUDIO"
+	FileTypeImage         = "IMAGE"
+	FileTypeText          = "TEXT"
+	FileTypeVideo         = "VIDEO"
+	FileTypeDocumentation = "DOCUMENTATION"
+	FileTypeSPDX          = "SPDX"
+	FileTypeOther         = "OTHER"
+
+	// Number of bytes read from the file to detect its type
+	fileTypeSniffLength = 512
+)
+
+// fileMagic maps the leading bytes of known formats to their file type
+var fileMagic = []struct {
+	offset   int
+	magic    []byte
+	fileType string
+}{
+	{0, []byte("\x7fELF"), FileTypeBinary},
+	{0, []byte{0xfe, 0xed, 0xfa, 0xce}, FileTypeBinary}, // Mach-O 32 bit
+	{0, []byte{0xfe, 0xed, 0xfa, 0xcf}, FileTypeBinary}, // Mach-O 64 bit
+	{0, []byte{0xce, 0xfa, 0xed, 0xfe}, FileTypeBinary}, // Mach-O 32 bit, little endian
+	{0, []byte{0xcf, 0xfa, 0xed, 0xfe}, FileTypeBinary}, // Mach-O 64 bit, little endian
+	{0, []byte("\x00asm"), FileTypeBinary},              // WebAssembly
+	{0, []byte("PK\x03\x04"), FileTypeArchive},          // zip, jar
+	{0, []byte{0x1f, 0x8b}, FileTypeArchive},            // gzip
+	{0, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, FileTypeArchive},
+	{0, []byte{0x28, 0xb5, 0x2f, 0xfd}, FileTypeArchive}, // zstd
+	{0, []byte("!<arch>\n"), FileTypeArchive},            // ar, deb
+	{0, []byte{0xed, 0xab, 0xee, 0xdb}, FileTypeArchive}, // rpm
+	{257, []byte("ustar"), FileTypeArchive},              // tar
+	{0, []byte("\x89PNG\r\n\x1a\n"), FileTypeImage},
+	{0, []byte{0xff, 0xd8, 0xff}, FileTypeImage}, // jpeg
+	{0, []byte("GIF8"), FileTypeImage},
+	{0, []byte("%PDF-"), FileTypeDocumentation},
+	{0, []byte("SPDXVersion: "), FileTypeSPDX},
+}
+
+// sourceExtensions are the extensions of text files considered source code
+var sourceExtensions = map[string]struct{}{
+	".c": {}, ".cc": {}, ".cpp": {}, ".cs": {}, ".go": {}, ".h": {}, ".hpp": {},
+	".java": {}, ".js": {}, ".jsx": {}, ".kt": {}, ".m": {}, ".mk": {}, ".php": {},
+	".pl": {}, ".proto": {}, ".py": {}, ".rb": {}, ".rs": {}, ".s": {}, ".scala": {},
+	".sh": {}, ".swift": {}, ".ts": {}, ".tsx": {},
+}
+
+// sourceFileNames are names of extensionless files considered source code
+var sourceFileNames = map[string]struct{}{
+	"Makefile": {}, "GNUmakefile": {}, "Dockerfile": {}, "Containerfile": {},
+	"BUILD": {}, "BUILD.bazel": {}, "WORKSPACE": {}, "Rakefile": {}, "Gemfile": {},
+}
+
+// DetectFileType reads the leading bytes of the file and sets the SPDX
+// file types from its contents. The file is read from its Name
+// relative to workDir, or from SourceFile if the name is not set.
+func (f *File) DetectFileType(workDir string) error {
+	path := filepath.Join(workDir, f.Name)
+	if f.Name == "" {
+		path = f.SourceFile
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "opening file to detect its type")
+	}
+	defer file.Close()
+
+	head := make([]byte, fileTypeSniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return errors.Wrap(err, "reading file header")
+	}
+	f.FileType = detectFileType(filepath.Base(path), head[:n])
+	return errors.Wrap(f.readFileStats(path), "reading file size")
+}
+
+// isPEExecutable returns true if head starts a Windows PE executable:
+// an MZ header whose e_lfanew field points to the PE signature
+func isPEExecutable(head []byte) bool {
+	if len(head) < 0x40 || !bytes.HasPrefix(head, []byte("MZ")) {
+		return false
+	}
+	offset := int64(binary.LittleEndian.Uint32(head[0x3c:0x40]))
+	if offset < 0x40 || offset+4 > int64(len(head)) {
+		return false
+	}
+	return bytes.Equal(head[offset:offset+4], []byte("PE\x00\x00"))
+}
+
+// detectFileType returns the SPDX file types from the file name and
+// the first bytes of its contents
+func detectFileType(name string, head []byte) []string {
+	// Scripts are text source files
+	if bytes.HasPrefix(head, []byte("#!")) {
+		return []string{FileTypeText, FileTypeSource}
+	}
+	if isPEExecutable(head) {
+		return []string{FileTypeBinary}
+	}
+
+	for _, m := range fileMagic {
+		if len(head) >= m.offset+len(m.magic) &&
+			bytes.Equal(head[m.offset:m.offset+len(m.magic)], m.magic) {
+			return []string{m.fileType}
+		}
+	}
+
+	// Files with NUL bytes or invalid UTF-8 are considered binary. The
+	// header may cut a multibyte character, so we drop an incomplete
+	// rune at the end before checking.
+	check := head
+	if len(head) == fileTypeSniffLength {
+		for i := len(check) - 1; i >= 0 && i >= len(check)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(check[i]) {
+				if !utf8.FullRune(check[i:]) {
+					check = check[:i]
+				}
+				break
+			}
+		}
+	}
+	if bytes.IndexByte(check, 0) != -1 || !utf8.Valid(check) {
+		return []string{FileTypeBinary}
+	}
+
+	if _, ok := sourceFileNames[name]; ok {
+		return []string{FileTypeText, FileTypeSource}
+	}
+	if _, ok := sourceExtensions[strings.ToLower(filepath.Ext(name))]; ok {
+		return []string{FileTypeText, FileTypeSource}
+	}
+	return []string{FileTypeText}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/release-utils/command"
+)
+
+// goBuildInfoModule is a module listed in the build info of a binary
+type goBuildInfoModule struct {
+	Path    string
+	Version string
+	Sum     string
+	Replace *goBuildInfoModule
+}
+
+// FromGoBinary builds a SPDX package from the build info embedded in a
+// Go binary, as printed by go version -m. The main module is the root
+// package, with the checksums of the binary, and each module linked in
+// the binary becomes one of its dependencies. The go toolchain is needed
+// to read the build info.
+func FromGoBinary(path string) (*Package, error) {
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		return nil, errors.New("unable to read go build info, go executable not found")
+	}
+	output, err := command.New(gobin, "version", "-m", path).RunSilentSuccessOutput()
+	if err != nil {
+		return nil, errors.Wrap(err, "reading go build info of binary")
+	}
+	main, deps, err := parseGoBuildInfo(output.Output())
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing go build info")
+	}
+
+	pkg := NewPackage()
+	pkg.Name = main.Path
+	pkg.ID = goPackageID(main.Path, "")
+	// Modules built from a local checkout have no version
+	if main.Version != "(devel)" {
+		pkg.Version = main.Version
+	}
+	pkg.SourceInfo = "acquired package info from the go build info of the binary"
+	pkg.AddPackageURL(goPackageURL(main.Path, pkg.Version))
+	if err := pkg.ReadSourceFile(path); err != nil {
+		return nil, errors.Wrap(err, "reading binary checksums")
+	}
+
+	for _, mod := range deps {
+		dep := goDependency(mod)
+		if pkg.HasDependency(dep.ID) {
+			continue
+		}
+		if err := pkg.AddDependency(dep); err != nil {
+			return nil, errors.Wrapf(err, "adding go module %s", mod.Path)
+		}
+	}
+	return pkg, nil
+}
+
+// parseGoBuildInfo reads the main module and the dependencies from the
+// output of go version -m. Each line holds tab separated fields, module
+// replacements are listed right after the module they replace.
+func parseGoBuildInfo(output string) (main *goBuildInfoModule, deps []*goBuildInfoModule, err error) {
+	var last *goBuildInfoModule
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		mod := &goBuildInfoModule{Path: fields[1]}
+		if len(fields) > 2 {
+			mod.Version = fields[2]
+		}
+		if len(fields) > 3 {
+			mod.Sum = fields[3]
+		}
+		switch fields[0] {
+		case "mod":
+			main = mod
+			last = nil
+		case "dep":
+			deps = append(deps, mod)
+			last = mod
+		case "=>":
+			if last == nil {
+				return nil, nil, errors.Errorf("replacement %s does not follow a module", mod.Path)
+			}
+			last.Replace = mod
+			last = nil
+		}
+	}
+	if main == nil {
+		return nil, nil, errors.New("binary has no main module in its build info")
+	}
+	return main, deps, nil
+}
+
+// goDependency builds the package of a module linked in a binary.
+// Modules replaced by other modules are listed as the replacement,
+// those replaced by local directories keep their required version.
+func goDependency(mod *goBuildInfoModule) *Package {
+	dep := NewPackage()
+	if r := mod.Replace; r != nil && r.Version != "" {
+		dep.Comment = "Replaces " + mod.Path + "@" + mod.Version
+		mod = r
+	} else if r != nil {
+		dep.Comment = "Replaced by the local directory " + r.Path
+	}
+	dep.Name = mod.Path
+	dep.ID = goPackageID(mod.Path, mod.Version)
+	dep.Version = mod.Version
+	dep.SourceInfo = "acquired package info from the go build info of the binary"
+	// The h1: hash is a digest of the module file tree, not of
+	// an artifact, so it has no SPDX checksum algorithm
+	if mod.Sum != "" {
+		if dep.Comment != "" {
+			dep.Comment += "\n"
+		}
+		dep.Comment += "Go module hash: " + mod.Sum
+	}
+	dep.AddPackageURL(goPackageURL(mod.Path, mod.Version))
+	return dep
+}
+
+// goPackageID returns the SPDX ID of a go module at version
+func goPackageID(path, version string) string {
+	reg := regexp.MustCompile(validNameCharsRe)
+	id := "SPDXRef-Package-go-" + strings.Trim(reg.ReplaceAllString(path, "-"), "-")
+	if version != "" {
+		id += "-" + strings.Trim(reg.ReplaceAllString(version, "-"), "-")
+	}
+	return id
+}
+
+// goPackageURL returns the purl of a go module, its path segments are
+// the purl namespace and name
+func goPackageURL(path, version string) string {
+	namespace, name := "", path
+	if i := strings.LastIndex(path, "/"); i != -1 {
+		namespace, name = path[:i], path[i+1:]
+	}
+	return buildPackageURL("golang", namespace, name, version, nil)
+}
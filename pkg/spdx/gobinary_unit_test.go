@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"archive/zip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeGoModuleProxy writes a file based module proxy serving the
+// module at version with the files passed
+func writeGoModuleProxy(t *testing.T, dir, module, version string, files map[string]string) {
+	modDir := filepath.Join(dir, filepath.FromSlash(module), "@v")
+	require.Nil(t, os.MkdirAll(modDir, os.FileMode(0o755)))
+	require.Nil(t, os.WriteFile(filepath.Join(modDir, "list"), []byte(version+"\n"), os.FileMode(0o644)))
+	require.Nil(t, os.WriteFile(
+		filepath.Join(modDir, version+".info"),
+		[]byte(`{"Version":"`+version+`","Time":"2021-07-01T00:00:00Z"}`), os.FileMode(0o644),
+	))
+	require.Nil(t, os.WriteFile(filepath.Join(modDir, version+".mod"), []byte(files["go.mod"]), os.FileMode(0o644)))
+
+	f, err := os.Create(filepath.Join(modDir, version+".zip"))
+	require.Nil(t, err)
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(module + "@" + version + "/" + name)
+		require.Nil(t, err)
+		_, err = w.Write([]byte(content))
+		require.Nil(t, err)
+	}
+	require.Nil(t, zw.Close())
+}
+
+func TestFromGoBinary(t *testing.T) {
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not found")
+	}
+	dir, err := os.MkdirTemp("", "spdx-gobinary-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	proxy := filepath.Join(dir, "proxy")
+	writeGoModuleProxy(t, proxy, "example.com/greeting", "v1.2.0", map[string]string{
+		"go.mod":      "module example.com/greeting\n\ngo 1.16\n",
+		"greeting.go": "package greeting\n\nconst Hello = \"hello\"\n",
+	})
+	writeTestTree(t, filepath.Join(dir, "hello"), map[string]string{
+		"go.mod":  "module example.com/hello\n\ngo 1.16\n\nrequire example.com/greeting v1.2.0\n",
+		"main.go": "package main\n\nimport \"example.com/greeting\"\n\nfunc main() { println(greeting.Hello) }\n",
+	})
+
+	binary := filepath.Join(dir, "hello-bin")
+	build := exec.Command(gobin, "build", "-o", binary, ".")
+	build.Dir = filepath.Join(dir, "hello")
+	build.Env = append(os.Environ(),
+		"GOPROXY=file://"+filepath.ToSlash(proxy),
+		"GOMODCACHE="+filepath.Join(dir, "modcache"),
+		"GOFLAGS=-mod=mod -modcacherw",
+		"GOSUMDB=off",
+		"GOWORK=off",
+		"GOTOOLCHAIN=local",
+	)
+	out, err := build.CombinedOutput()
+	require.Nil(t, err, string(out))
+
+	pkg, err := FromGoBinary(binary)
+	require.Nil(t, err)
+	require.Equal(t, "example.com/hello", pkg.Name)
+	require.Len(t, pkg.Checksum, 2)
+	require.Equal(t, "./hello-bin", pkg.FileName)
+
+	dep := pkg.Dependencies["SPDXRef-Package-go-example-com-greeting-v1-2-0"]
+	require.NotNil(t, dep)
+	require.Equal(t, "v1.2.0", dep.Version)
+	require.Contains(t, dep.Comment, "Go module hash: h1:")
+	require.Equal(t, "pkg:golang/example.com/greeting@v1.2.0", dep.ExternalRefs[0].Locator)
+	_, err = pkg.Render()
+	require.Nil(t, err)
+}
+
+func TestParseGoBuildInfo(t *testing.T) {
+	main, deps, err := parseGoBuildInfo("/usr/bin/bom: go1.16.6\n" +
+		"\tpath\tk8s.io/release/cmd/bom\n" +
+		"\tmod\tk8s.io/release\t(devel)\t\n" +
+		"\tdep\tgithub.com/pkg/errors\tv0.9.1\th1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4=\n" +
+		"\tdep\tk8s.io/utils\tv0.0.0-20210111153108-fddb29f9d009\n" +
+		"\t=>\t../utils\t\n" +
+		"\tdep\tgolang.org/x/mod\tv0.4.2\n" +
+		"\t=>\tgolang.org/x/mod\tv0.5.0\th1:UG21uOlmZabA4fW5i7ZX6bjw1xELEGg/ZLgZq9auk/Q=\n",
+	)
+	require.Nil(t, err)
+	require.Equal(t, "k8s.io/release", main.Path)
+	require.Len(t, deps, 3)
+
+	dep := goDependency(deps[1])
+	require.Equal(t, "v0.0.0-20210111153108-fddb29f9d009", dep.Version)
+	require.Equal(t, "Replaced by the local directory ../utils", dep.Comment)
+	dep = goDependency(deps[2])
+	require.Equal(t, "v0.5.0", dep.Version)
+	require.Equal(t, "SPDXRef-Package-go-golang-org-x-mod-v0-5-0", dep.ID)
+	require.Contains(t, dep.Comment, "Replaces golang.org/x/mod@v0.4.2")
+
+	_, _, err = parseGoBuildInfo("\tdep\tgithub.com/pkg/errors\tv0.9.1\n")
+	require.NotNil(t, err)
+}
@@ -99,6 +99,7 @@ func (pkg *GoPackage) ToSPDXPackage() (*Package, error) {
 	spdxPackage.LicenseConcluded = pkg.LicenseID
 	spdxPackage.Version = strings.TrimSuffix(pkg.Revision, "+incompatible")
 	spdxPackage.CopyrightText = pkg.CopyrightText
+	spdxPackage.SourceInfo = "acquired package info from the go module requirements"
 	return spdxPackage, nil
 }
 
@@ -91,13 +91,24 @@ func (di *spdxDefaultImplementation) ExtractTarballTmp(tarPath string) (tmpDir s
 			continue
 		}
 
+		// Only regular files are extracted, links could point or
+		// write outside of the extraction directory
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			logrus.Debugf("Skipping extraction of non regular file %s", hdr.Name)
+			continue
+		}
+
+		targetFile := filepath.Join(tmpDir, hdr.Name)
+		if !pathWithin(tmpDir, targetFile) {
+			return tmpDir, errors.Errorf("tarball entry %s points outside of the extraction directory", hdr.Name)
+		}
+
 		if err := os.MkdirAll(
-			filepath.Join(tmpDir, filepath.Dir(hdr.Name)), os.FileMode(0o755),
+			filepath.Dir(targetFile), os.FileMode(0o755),
 		); err != nil {
 			return tmpDir, errors.Wrap(err, "creating image directory structure")
 		}
 
-		targetFile := filepath.Join(tmpDir, hdr.Name)
 		f, err := os.Create(targetFile)
 		if err != nil {
 			return tmpDir, errors.Wrap(err, "creating image layer file")
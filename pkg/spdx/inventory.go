@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// inventory is the intermediate package model written by
+// WriteInventoryJSON, a flat list of the packages in the tree meant to
+// be handed to scanners and not a replacement for the SPDX document
+type inventory struct {
+	Packages []inventoryPackage `json:"packages"`
+}
+
+// inventoryPackage describes a package in the inventory
+type inventoryPackage struct {
+	Name      string            `json:"name"`                // Package name
+	Version   string            `json:"version,omitempty"`   // Package version
+	Type      string            `json:"type"`                // Purl type (golang, npm, deb...) or unknown
+	Purl      string            `json:"purl,omitempty"`      // First purl of the package
+	CPEs      []string          `json:"cpes,omitempty"`      // CPE names from the security external refs
+	Licenses  []string          `json:"licenses,omitempty"`  // Concluded and declared license expressions
+	Locations []string          `json:"locations,omitempty"` // Names of the package file and the files it contains
+	Checksums map[string]string `json:"checksums,omitempty"` // Checksums of the package by algorithm
+}
+
+// inventoryUnknownType is the type of packages without a purl
+const inventoryUnknownType = "unknown"
+
+// WriteInventoryJSON writes the package, its subpackages and dependencies
+// to w as a simple JSON inventory with one entry per unique package.
+// Packages are listed in the order they are first found walking the
+// tree, subpackages and dependencies sorted by ID.
+func (p *Package) WriteInventoryJSON(w io.Writer) error {
+	inv := inventory{Packages: []inventoryPackage{}}
+	p.forEachUniquePackage(func(pkg *Package) {
+		inv.Packages = append(inv.Packages, pkg.inventoryPackage())
+	})
+
+	data, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshalling package inventory")
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return errors.Wrap(err, "writing package inventory")
+	}
+	return nil
+}
+
+// inventoryPackage returns the inventory entry of the package, the
+// caller must hold its lock
+func (p *Package) inventoryPackage() inventoryPackage {
+	entry := inventoryPackage{
+		Name:      p.Name,
+		Version:   p.Version,
+		Type:      inventoryUnknownType,
+		Purl:      p.packageURL(),
+		Checksums: p.Checksum,
+	}
+	if purlType, _, _, _, ok := parsePackageURL(entry.Purl); ok && purlType != "" {
+		entry.Type = purlType
+	}
+	for _, ref := range p.ExternalRefs {
+		if ref.Type == "cpe23Type" || ref.Type == "cpe22Type" {
+			entry.CPEs = append(entry.CPEs, ref.Locator)
+		}
+	}
+	for _, l := range []string{p.LicenseConcluded, p.LicenseDeclared} {
+		if l == "" || l == NONE || l == NOASSERTION {
+			continue
+		}
+		if len(entry.Licenses) == 0 || entry.Licenses[0] != l {
+			entry.Licenses = append(entry.Licenses, l)
+		}
+	}
+	if p.FileName != "" {
+		entry.Locations = append(entry.Locations, p.FileName)
+	}
+	names := []string{}
+	for _, f := range p.Files {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	entry.Locations = append(entry.Locations, names...)
+	return entry
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteInventoryJSON(t *testing.T) {
+	root := NewPackage()
+	root.ID = "SPDXRef-Package-root"
+	root.Name = "root"
+	root.Version = "1.0.0"
+	root.FileName = "./root-1.0.0.tar.gz"
+	root.LicenseDeclared = "Apache-2.0"
+	root.Checksum = map[string]string{"SHA256": "abc"}
+	root.AddPackageURL("pkg:golang/example.com/root@1.0.0")
+	root.AddCPE("cpe:2.3:a:example:root:1.0.0:*:*:*:*:*:*:*")
+	root.FilesAnalyzed = true
+	f := NewFile()
+	f.Name = "./main.go"
+	f.Checksum = map[string]string{"SHA1": "0000000000000000000000000000000000000001"}
+	require.Nil(t, root.AddFile(f))
+
+	// dep is reached twice, it is listed once
+	sub := NewPackage()
+	sub.ID = "SPDXRef-Package-sub"
+	sub.Name = "sub"
+	dep, err := root.AddDependencyByCoordinates("npm", "left-pad", "1.3.0")
+	require.Nil(t, err)
+	dep.LicenseConcluded = "MIT"
+	dep.LicenseDeclared = "MIT"
+	require.Nil(t, root.AddPackage(sub))
+	require.Nil(t, sub.AddDependency(dep))
+
+	var buf bytes.Buffer
+	require.Nil(t, root.WriteInventoryJSON(&buf))
+
+	// The model is a list of flat package objects
+	raw := map[string][]map[string]interface{}{}
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &raw))
+	require.Len(t, raw["packages"], 3)
+	for _, entry := range raw["packages"] {
+		for key := range entry {
+			require.Contains(t, []string{
+				"name", "version", "type", "purl", "cpes", "licenses", "locations", "checksums",
+			}, key)
+		}
+	}
+
+	inv := inventory{}
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &inv))
+	require.Equal(t, inventoryPackage{
+		Name:      "root",
+		Version:   "1.0.0",
+		Type:      "golang",
+		Purl:      "pkg:golang/example.com/root@1.0.0",
+		CPEs:      []string{"cpe:2.3:a:example:root:1.0.0:*:*:*:*:*:*:*"},
+		Licenses:  []string{"Apache-2.0"},
+		Locations: []string{"./root-1.0.0.tar.gz", "./main.go"},
+		Checksums: map[string]string{"SHA256": "abc"},
+	}, inv.Packages[0])
+	require.Equal(t, inventoryPackage{
+		Name:     "left-pad",
+		Version:  "1.3.0",
+		Type:     "npm",
+		Purl:     "pkg:npm/left-pad@1.3.0",
+		Licenses: []string{"MIT"},
+	}, inv.Packages[1])
+	require.Equal(t, inventoryPackage{Name: "sub", Type: inventoryUnknownType}, inv.Packages[2])
+
+	// A dependency back to the root closes a cycle, the walk ends
+	require.Nil(t, dep.AddDependency(root))
+	buf.Reset()
+	require.Nil(t, root.WriteInventoryJSON(&buf))
+	inv = inventory{}
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &inv))
+	require.Len(t, inv.Packages, 3)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"regexp"
+)
+
+// deprecatedLicenses maps deprecated SPDX license identifiers and common
+// misspellings found in package metadata to their current form
+var deprecatedLicenses = map[string]string{
+	"AGPL-1.0":                         "AGPL-1.0-only",
+	"AGPL-3.0":                         "AGPL-3.0-only",
+	"AGPL-3.0+":                        "AGPL-3.0-or-later",
+	"GFDL-1.1":                         "GFDL-1.1-only",
+	"GFDL-1.1+":                        "GFDL-1.1-or-later",
+	"GFDL-1.2":                         "GFDL-1.2-only",
+	"GFDL-1.2+":                        "GFDL-1.2-or-later",
+	"GFDL-1.3":                         "GFDL-1.3-only",
+	"GFDL-1.3+":                        "GFDL-1.3-or-later",
+	"GPL-1.0":                          "GPL-1.0-only",
+	"GPL-1.0+":                         "GPL-1.0-or-later",
+	"GPL-2.0":                          "GPL-2.0-only",
+	"GPL-2.0+":                         "GPL-2.0-or-later",
+	"GPL-3.0":                          "GPL-3.0-only",
+	"GPL-3.0+":                         "GPL-3.0-or-later",
+	"LGPL-2.0":                         "LGPL-2.0-only",
+	"LGPL-2.0+":                        "LGPL-2.0-or-later",
+	"LGPL-2.1":                         "LGPL-2.1-only",
+	"LGPL-2.1+":                        "LGPL-2.1-or-later",
+	"LGPL-3.0":                         "LGPL-3.0-only",
+	"LGPL-3.0+":                        "LGPL-3.0-or-later",
+	"GPL-2.0-with-autoconf-exception":  "GPL-2.0-only WITH Autoconf-exception-2.0",
+	"GPL-2.0-with-bison-exception":     "GPL-2.0-only WITH Bison-exception-2.2",
+	"GPL-2.0-with-classpath-exception": "GPL-2.0-only WITH Classpath-exception-2.0",
+	"GPL-2.0-with-font-exception":      "GPL-2.0-only WITH Font-exception-2.0",
+	"GPL-2.0-with-GCC-exception":       "GPL-2.0-only WITH GCC-exception-2.0",
+	"GPL-3.0-with-autoconf-exception":  "GPL-3.0-only WITH Autoconf-exception-3.0",
+	"GPL-3.0-with-GCC-exception":       "GPL-3.0-only WITH GCC-exception-3.1",
+	"BSD-2-Clause-FreeBSD":             "BSD-2-Clause",
+	"BSD-2-Clause-NetBSD":              "BSD-2-Clause",
+	"eCos-2.0":                         "GPL-2.0-or-later WITH eCos-exception-2.0",
+	"Nunit":                            "zlib-acknowledgement",
+	"StandardML-NJ":                    "SMLNJ",
+	"wxWindows":                        "GPL-2.0-or-later WITH WxWindows-exception-3.1",
+	"Apache2":                          "Apache-2.0",
+	"Apache2.0":                        "Apache-2.0",
+	"Apache-2":                         "Apache-2.0",
+	"ASL-2.0":                          "Apache-2.0",
+	"MPL2.0":                           "MPL-2.0",
+	"GPLv2":                            "GPL-2.0-only",
+	"GPLv2+":                           "GPL-2.0-or-later",
+	"GPLv3":                            "GPL-3.0-only",
+	"GPLv3+":                           "GPL-3.0-or-later",
+	"LGPLv2":                           "LGPL-2.0-only",
+	"LGPLv2+":                          "LGPL-2.0-or-later",
+	"LGPLv3":                           "LGPL-3.0-only",
+	"LGPLv3+":                          "LGPL-3.0-or-later",
+}
+
+// licenseTokenRe matches the identifiers and operators of a license expression
+var licenseTokenRe = regexp.MustCompile(`[^\s()]+`)
+
+// NormalizeLicense replaces the deprecated license identifiers in a
+// license expression with their current equivalents. It returns the
+// new expression and true if any identifier was replaced.
+func NormalizeLicense(expr string) (string, bool) {
+	changed := false
+	normalized := licenseTokenRe.ReplaceAllStringFunc(expr, func(token string) string {
+		if current, ok := deprecatedLicenses[token]; ok {
+			changed = true
+			return current
+		}
+		return token
+	})
+	return normalized, changed
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeLicense(t *testing.T) {
+	for _, tc := range []struct {
+		expr     string
+		expected string
+		changed  bool
+	}{
+		{"GPL-3.0+", "GPL-3.0-or-later", true},
+		{"GPL-3.0", "GPL-3.0-only", true},
+		{"Apache2.0", "Apache-2.0", true},
+		{"MIT", "MIT", false},
+		{"GPL-3.0-or-later", "GPL-3.0-or-later", false},
+		{"(MIT OR LGPL-2.1+) AND Apache2.0", "(MIT OR LGPL-2.1-or-later) AND Apache-2.0", true},
+		{"GPL-2.0-with-classpath-exception", "GPL-2.0-only WITH Classpath-exception-2.0", true},
+		{"", "", false},
+	} {
+		normalized, changed := NormalizeLicense(tc.expr)
+		require.Equal(t, tc.expected, normalized)
+		require.Equal(t, tc.changed, changed)
+	}
+}
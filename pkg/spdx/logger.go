@@ -0,0 +1,45 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import "github.com/sirupsen/logrus"
+
+// Logger receives the progress messages of long running package
+// operations such as reading directories or downloading sources.
+// A *logrus.Logger can be used as a Logger.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+// NoopLogger discards all messages, set it in the package options to
+// silence the progress messages
+type NoopLogger struct{}
+
+func (NoopLogger) Debugf(string, ...interface{}) {}
+func (NoopLogger) Infof(string, ...interface{})  {}
+func (NoopLogger) Warnf(string, ...interface{})  {}
+
+// logger returns the logger set in the package options or the logrus
+// standard logger
+func (p *Package) logger() Logger {
+	if p.Options() == nil || p.Options().Logger == nil {
+		return logrus.StandardLogger()
+	}
+	return p.Options().Logger
+}
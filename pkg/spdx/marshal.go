@@ -0,0 +1,170 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonPackage is the JSON representation of a package used for
+// debugging. Packages already serialized elsewhere in the tree are
+// written with their ID only.
+type jsonPackage struct {
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name,omitempty"`
+	Version              string            `json:"version,omitempty"`
+	FileName             string            `json:"fileName,omitempty"`
+	ArchiveFileName      string            `json:"archiveFileName,omitempty"`
+	SourceFile           string            `json:"sourceFile,omitempty"`
+	Scope                DependencyScope   `json:"scope,omitempty"`
+	DownloadLocation     string            `json:"downloadLocation,omitempty"`
+	DownloadComment      string            `json:"downloadLocationComment,omitempty"`
+	FilesAnalyzed        bool              `json:"filesAnalyzed,omitempty"`
+	VerificationCode     string            `json:"verificationCode,omitempty"`
+	LicenseConcluded     string            `json:"licenseConcluded,omitempty"`
+	LicenseInfoFromFiles []string          `json:"licenseInfoFromFiles,omitempty"`
+	LicenseDeclared      string            `json:"licenseDeclared,omitempty"`
+	LicenseComments      string            `json:"licenseComments,omitempty"`
+	CopyrightText        string            `json:"copyrightText,omitempty"`
+	Summary              string            `json:"summary,omitempty"`
+	Description          string            `json:"description,omitempty"`
+	Comment              string            `json:"comment,omitempty"`
+	SourceInfo           string            `json:"sourceInfo,omitempty"`
+	AttributionText      []string          `json:"attributionText,omitempty"`
+	Supplier             *jsonParty        `json:"supplier,omitempty"`
+	Originator           *jsonParty        `json:"originator,omitempty"`
+	Checksum             map[string]string `json:"checksum,omitempty"`
+	ExternalRefs         []ExternalRef     `json:"externalRefs,omitempty"`
+	Files                []*jsonFile       `json:"files,omitempty"`
+	Packages             []*jsonPackage    `json:"packages,omitempty"`
+	Dependencies         []*jsonPackage    `json:"dependencies,omitempty"`
+}
+
+// jsonFile is the JSON representation of a file in a package
+type jsonFile struct {
+	ID                string            `json:"id"`
+	Name              string            `json:"name,omitempty"`
+	SourceFile        string            `json:"sourceFile,omitempty"`
+	FileType          []string          `json:"fileType,omitempty"`
+	LicenseConcluded  string            `json:"licenseConcluded,omitempty"`
+	LicenseInfoInFile []string          `json:"licenseInfoInFile,omitempty"`
+	CopyrightText     string            `json:"copyrightText,omitempty"`
+	Comment           string            `json:"comment,omitempty"`
+	Size              int64             `json:"size,omitempty"`
+	Lines             int               `json:"lines,omitempty"`
+	AttributionText   []string          `json:"attributionText,omitempty"`
+	Notice            string            `json:"notice,omitempty"`
+	Checksum          map[string]string `json:"checksum,omitempty"`
+	GitBlobSHA1       string            `json:"gitBlobSHA1,omitempty"`
+}
+
+// jsonParty is the JSON representation of a supplier or originator
+type jsonParty struct {
+	Person       string `json:"person,omitempty"`
+	Organization string `json:"organization,omitempty"`
+}
+
+// newJSONParty returns the party data or nil if it is empty
+func newJSONParty(person, organization string) *jsonParty {
+	if person == "" && organization == "" {
+		return nil
+	}
+	return &jsonParty{Person: person, Organization: organization}
+}
+
+// String returns a short summary of the package
+func (p *Package) String() string {
+	p.RLock()
+	defer p.RUnlock()
+	return fmt.Sprintf(
+		"%s (%s %s, %d files, %d packages, %d dependencies)",
+		p.ID, p.Name, p.Version, len(p.Files), len(p.Packages), len(p.Dependencies),
+	)
+}
+
+// MarshalJSON serializes the package and the packages it contains or
+// depends on. Files and packages are sorted by ID. Packages reachable
+// more than once, including through cycles, are fully serialized only
+// the first time.
+func (p *Package) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.jsonPackage(map[string]struct{}{}))
+}
+
+// jsonPackage builds the JSON representation of the package tree
+func (p *Package) jsonPackage(seen map[string]struct{}) *jsonPackage {
+	if _, ok := seen[p.ID]; ok {
+		return &jsonPackage{ID: p.ID}
+	}
+	seen[p.ID] = struct{}{}
+
+	p.RLock()
+	defer p.RUnlock()
+	jp := &jsonPackage{
+		ID:                   p.ID,
+		Name:                 p.Name,
+		Version:              p.Version,
+		FileName:             p.FileName,
+		ArchiveFileName:      p.ArchiveFileName,
+		SourceFile:           p.SourceFile,
+		Scope:                p.Scope,
+		DownloadLocation:     p.DownloadLocation,
+		DownloadComment:      p.DownloadLocationComment,
+		FilesAnalyzed:        p.FilesAnalyzed,
+		VerificationCode:     p.VerificationCode,
+		LicenseConcluded:     p.LicenseConcluded,
+		LicenseInfoFromFiles: p.LicenseInfoFromFiles,
+		LicenseDeclared:      p.LicenseDeclared,
+		LicenseComments:      p.LicenseComments,
+		CopyrightText:        p.CopyrightText,
+		Summary:              p.Summary,
+		Description:          p.Description,
+		Comment:              p.Comment,
+		SourceInfo:           p.SourceInfo,
+		AttributionText:      p.AttributionText,
+		Supplier:             newJSONParty(p.Supplier.Person, p.Supplier.Organization),
+		Originator:           newJSONParty(p.Originator.Person, p.Originator.Organization),
+		Checksum:             p.Checksum,
+		ExternalRefs:         p.ExternalRefs,
+	}
+	for _, id := range sortedFileIDs(p.Files) {
+		f := p.Files[id]
+		jp.Files = append(jp.Files, &jsonFile{
+			ID:                f.ID,
+			Name:              f.Name,
+			SourceFile:        f.SourceFile,
+			FileType:          f.FileType,
+			LicenseConcluded:  f.LicenseConcluded,
+			LicenseInfoInFile: f.LicenseInfoInFile,
+			CopyrightText:     f.CopyrightText,
+			Comment:           f.Comment,
+			Size:              f.Size,
+			Lines:             f.Lines,
+			AttributionText:   f.AttributionText,
+			Notice:            f.Notice,
+			Checksum:          f.Checksum,
+			GitBlobSHA1:       f.GitBlobSHA1,
+		})
+	}
+	for _, id := range sortedPackageIDs(p.Packages) {
+		jp.Packages = append(jp.Packages, p.Packages[id].jsonPackage(seen))
+	}
+	for _, id := range sortedPackageIDs(p.Dependencies) {
+		jp.Dependencies = append(jp.Dependencies, p.Dependencies[id].jsonPackage(seen))
+	}
+	return jp
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageMarshalJSON(t *testing.T) {
+	root := testPackageWithFiles(t, "MIT")
+	root.Version = "1.0"
+	sub := NewPackage()
+	sub.Name = "sub"
+	require.Nil(t, root.AddPackage(sub))
+	dep := NewPackage()
+	dep.Name = "dep"
+	require.Nil(t, sub.AddDependency(dep))
+	// Close a cycle back to the root
+	require.Nil(t, dep.AddDependency(root))
+
+	data, err := json.Marshal(root)
+	require.Nil(t, err)
+
+	doc := map[string]interface{}{}
+	require.Nil(t, json.Unmarshal(data, &doc))
+	require.Equal(t, "SPDXRef-Package-test-package", doc["id"])
+	require.Equal(t, "1.0", doc["version"])
+	require.NotContains(t, doc, "RWMutex")
+	require.NotContains(t, doc, "options")
+	require.Len(t, doc["files"], 1)
+
+	packages := doc["packages"].([]interface{})
+	require.Len(t, packages, 1)
+	subDoc := packages[0].(map[string]interface{})
+	require.Equal(t, "SPDXRef-Package-sub", subDoc["id"])
+	depDoc := subDoc["dependencies"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "SPDXRef-Package-dep", depDoc["id"])
+
+	// The cycle is cut with a reference to the root ID
+	cycle := depDoc["dependencies"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"id": "SPDXRef-Package-test-package"}, cycle)
+
+	// Output is stable
+	again, err := json.Marshal(root)
+	require.Nil(t, err)
+	require.Equal(t, data, again)
+}
+
+func TestPackageString(t *testing.T) {
+	pkg := testPackageWithFiles(t, "MIT", "MIT")
+	pkg.Version = "1.0"
+	require.Equal(
+		t, "SPDXRef-Package-test-package (test-package 1.0, 2 files, 0 packages, 0 dependencies)",
+		fmt.Sprintf("%v", pkg),
+	)
+}
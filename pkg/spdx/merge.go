@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// MergeStrategy selects what MergeDocumentsWithStrategy does with
+// elements found with the same SPDX ID but different content
+type MergeStrategy int
+
+const (
+	// MergeConflictError fails the merge on conflicting elements (default)
+	MergeConflictError MergeStrategy = iota
+
+	// MergeKeepFirst keeps the element of the first document listing it
+	MergeKeepFirst
+)
+
+// MergeDocuments combines docs into a new document, failing if the
+// documents hold different elements under the same SPDX ID. See
+// MergeDocumentsWithStrategy.
+func MergeDocuments(docs ...*Document) (*Document, error) {
+	return MergeDocumentsWithStrategy(MergeConflictError, docs...)
+}
+
+// MergeDocumentsWithStrategy combines docs into a new document with a
+// new namespace. The packages, files and relationships of the documents
+// are copied and elements with the same SPDX ID are kept once, conflicts
+// are resolved according to strategy. All the packages described by the
+// documents are described by the merged one. The documents are not
+// modified.
+func MergeDocumentsWithStrategy(strategy MergeStrategy, docs ...*Document) (*Document, error) {
+	if len(docs) == 0 {
+		return nil, errors.New("no documents to merge")
+	}
+	m := &documentMerger{
+		strategy: strategy,
+		packages: map[string]*Package{},
+		clones:   map[*Package]*Package{},
+		files:    map[*File]*File{},
+	}
+	merged := NewDocument()
+	merged.Packages = map[string]*Package{}
+	names := []string{}
+	tools := map[string]struct{}{}
+	for _, tool := range merged.Creator.Tool {
+		tools[tool] = struct{}{}
+	}
+	refs := map[string]ExternalDocumentRef{}
+	for i, doc := range docs {
+		if doc == nil {
+			return nil, errors.Errorf("document #%d is nil", i)
+		}
+		if doc.Name != "" {
+			names = append(names, doc.Name)
+		}
+		// The version and author are taken from the first document
+		if i == 0 && doc.Version != "" {
+			merged.Version = doc.Version
+		}
+		if i == 0 && doc.Creator.Person != "" {
+			merged.Creator.Person = doc.Creator.Person
+		}
+		for _, tool := range doc.Creator.Tool {
+			if _, ok := tools[tool]; !ok {
+				tools[tool] = struct{}{}
+				merged.Creator.Tool = append(merged.Creator.Tool, tool)
+			}
+		}
+
+		for _, ref := range doc.ExternalDocumentRefs {
+			if existing, ok := refs[ref.ID]; ok {
+				if !reflect.DeepEqual(existing, ref) && strategy == MergeConflictError {
+					return nil, errors.Errorf("external document %s is referenced with different data", ref.ID)
+				}
+				continue
+			}
+			refs[ref.ID] = ref
+			merged.ExternalDocumentRefs = append(merged.ExternalDocumentRefs, ref)
+		}
+
+		for _, id := range sortedFileIDs(doc.Files) {
+			f := doc.Files[id].clone(m.clones, m.files)
+			if existing, ok := merged.Files[id]; ok {
+				if !filesEqual(existing, f) && strategy == MergeConflictError {
+					return nil, errors.Errorf("merging document #%d: file %s has different content", i, id)
+				}
+				continue
+			}
+			if err := merged.AddFile(f); err != nil {
+				return nil, errors.Wrapf(err, "merging document #%d", i)
+			}
+		}
+
+		for _, id := range sortedPackageIDs(doc.Packages) {
+			root := doc.Packages[id].clone(m.clones, m.files)
+			// All roots are described, none of them is the primary one
+			root.IsPrimary = false
+			pkg, err := m.add(root)
+			if err != nil {
+				return nil, errors.Wrapf(err, "merging document #%d", i)
+			}
+			merged.Packages[id] = pkg
+		}
+	}
+
+	merged.Name = "merged"
+	if len(names) > 0 {
+		merged.Name = strings.Join(names, "+")
+	}
+	reg := regexp.MustCompile(validNameCharsRe)
+	merged.Namespace = fmt.Sprintf(
+		"https://spdx.org/spdxdocs/%s-%s",
+		strings.Trim(reg.ReplaceAllString(merged.Name, "-"), "-"), uuid.New().String(),
+	)
+	return merged, nil
+}
+
+// documentMerger indexes the packages of the documents being merged
+type documentMerger struct {
+	strategy MergeStrategy
+	packages map[string]*Package // Merged packages by SPDX ID
+	clones   map[*Package]*Package
+	files    map[*File]*File
+}
+
+// add indexes pkg and the packages it links to, replacing the links to
+// packages already indexed under the same ID by the indexed ones. It
+// returns the indexed package with the ID of pkg.
+func (m *documentMerger) add(pkg *Package) (*Package, error) {
+	if existing, ok := m.packages[pkg.ID]; ok {
+		if existing != pkg && m.strategy == MergeConflictError && !existing.Equal(pkg) {
+			return nil, errors.Errorf("package %s has different content in the documents", pkg.ID)
+		}
+		return existing, nil
+	}
+	m.packages[pkg.ID] = pkg
+	for _, list := range []map[string]*Package{pkg.Packages, pkg.Dependencies} {
+		for _, id := range sortedPackageIDs(list) {
+			indexed, err := m.add(list[id])
+			if err != nil {
+				return nil, err
+			}
+			list[id] = indexed
+		}
+	}
+	for _, r := range pkg.Relationships {
+		if r.Package == nil {
+			continue
+		}
+		indexed, err := m.add(r.Package)
+		if err != nil {
+			return nil, err
+		}
+		r.Package = indexed
+	}
+	return pkg, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testMergeDocument returns the parsed document describing a package
+// named name as its primary package, which depends on shared
+func testMergeDocument(t *testing.T, name, sharedVersion string) *Document {
+	doc := NewDocument()
+	doc.Name = name
+	doc.Namespace = "https://example.com/" + name
+	doc.Creator.Tool = []string{"k8s.io/release/pkg/spdx"}
+	root := NewPackage()
+	root.Name = name
+	shared := NewPackage()
+	shared.Name = "shared"
+	shared.Version = sharedVersion
+	require.Nil(t, root.AddDependency(shared))
+	require.Nil(t, doc.AddPackage(root))
+
+	out, err := doc.Render()
+	require.Nil(t, err)
+	parsed, err := ParseTagValue(strings.NewReader(out))
+	require.Nil(t, err)
+	parsed.Packages["SPDXRef-Package-"+name].IsPrimary = true
+	return parsed
+}
+
+func TestMergeDocuments(t *testing.T) {
+	app := testMergeDocument(t, "app", "1.0.0")
+	base := testMergeDocument(t, "base", "1.0.0")
+
+	merged, err := MergeDocuments(app, base)
+	require.Nil(t, err)
+	require.Len(t, merged.Packages, 2)
+	require.Equal(t, "app+base", merged.Name)
+	require.True(t, strings.HasPrefix(merged.Namespace, "https://spdx.org/spdxdocs/app-base-"))
+	require.NotEqual(t, app.Namespace, merged.Namespace)
+	require.Equal(t, []string{"k8s.io/release/pkg/spdx"}, merged.Creator.Tool)
+
+	// Both roots point to the same shared package
+	shared := merged.Packages["SPDXRef-Package-app"].Dependencies["SPDXRef-Package-shared"]
+	require.NotNil(t, shared)
+	require.True(t, shared == merged.Packages["SPDXRef-Package-base"].Dependencies["SPDXRef-Package-shared"])
+
+	out, err := merged.Render()
+	require.Nil(t, err)
+	for _, id := range []string{"SPDXRef-Package-app", "SPDXRef-Package-base", "SPDXRef-Package-shared"} {
+		require.Equal(t, 1, strings.Count(out, "SPDXID: "+id+"\n"), id)
+	}
+	require.Contains(t, out, "Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-app\n")
+	require.Contains(t, out, "Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-base\n")
+	require.Contains(t, out, "Relationship: SPDXRef-Package-base DEPENDS_ON SPDXRef-Package-shared\n")
+	require.Nil(t, merged.Validate())
+
+	// The merged documents are not modified
+	require.True(t, app.Packages["SPDXRef-Package-app"].IsPrimary)
+	require.False(t, shared == app.Packages["SPDXRef-Package-app"].Dependencies["SPDXRef-Package-shared"])
+
+	// Conflicting packages fail the merge unless the first one is kept
+	other := testMergeDocument(t, "other", "2.0.0")
+	_, err = MergeDocuments(app, other)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "package SPDXRef-Package-shared has different content")
+	merged, err = MergeDocumentsWithStrategy(MergeKeepFirst, app, other)
+	require.Nil(t, err)
+	require.Equal(t, "1.0.0", merged.Packages["SPDXRef-Package-other"].Dependencies["SPDXRef-Package-shared"].Version)
+
+	_, err = MergeDocuments()
+	require.NotNil(t, err)
+}
@@ -0,0 +1,54 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+// +build linux darwin freebsd netbsd openbsd
+
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// mmapFile maps the contents of the file at path in memory, read only.
+// The returned function releases the mapping.
+func mmapFile(path string) (data []byte, unmap func() error, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "opening file")
+	}
+	// The mapping remains valid after closing the file
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "checking file size")
+	}
+	// Empty files cannot be mapped
+	if info.Size() == 0 {
+		return []byte{}, func() error { return nil }, nil
+	}
+	if int64(int(info.Size())) != info.Size() {
+		return nil, nil, errors.Errorf("file is too large to be mapped: %d bytes", info.Size())
+	}
+	data, err = syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "mapping file")
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
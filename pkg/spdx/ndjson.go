@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// ndjsonPackage is the record written for each package in the
+// newline delimited JSON export
+type ndjsonPackage struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name,omitempty"`
+	Version   string            `json:"version,omitempty"`
+	Checksums map[string]string `json:"checksums,omitempty"`
+	Purl      string            `json:"purl,omitempty"`
+	Parents   []ndjsonEdge      `json:"parents,omitempty"`
+}
+
+// ndjsonEdge links a package record to one of its parents
+type ndjsonEdge struct {
+	ID           string `json:"id"`
+	Relationship string `json:"relationship"`
+}
+
+// WriteNDJSON writes the package and all its subpackages and dependencies
+// to w as newline delimited JSON, one object per unique package. Each
+// object lists the packages that contain or depend on it.
+func (p *Package) WriteNDJSON(w io.Writer) error {
+	// First pass: index the packages in the tree and their parents
+	order := []*Package{}
+	parents := map[string][]ndjsonEdge{}
+	p.forEachUniquePackage(func(pkg *Package) {
+		order = append(order, pkg)
+		for relationship, list := range map[string]map[string]*Package{
+			"CONTAINS":   pkg.Packages,
+			"DEPENDS_ON": pkg.Dependencies,
+		} {
+			for id := range list {
+				parents[id] = append(parents[id], ndjsonEdge{ID: pkg.ID, Relationship: relationship})
+			}
+		}
+	})
+
+	// Second pass: write the records
+	enc := json.NewEncoder(w)
+	for _, pkg := range order {
+		edges := parents[pkg.ID]
+		sort.Slice(edges, func(i, j int) bool {
+			if edges[i].ID == edges[j].ID {
+				return edges[i].Relationship < edges[j].Relationship
+			}
+			return edges[i].ID < edges[j].ID
+		})
+		pkg.RLock()
+		record := ndjsonPackage{
+			ID:        pkg.ID,
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Checksums: pkg.Checksum,
+			Purl:      pkg.packageURL(),
+			Parents:   edges,
+		}
+		err := enc.Encode(record)
+		pkg.RUnlock()
+		if err != nil {
+			return errors.Wrapf(err, "writing json record for package %s", pkg.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteNDJSON(t *testing.T) {
+	// root contains a and depends on b, a also depends on b and
+	// b closes a cycle by depending on root
+	root := NewPackage()
+	root.ID = "SPDXRef-Package-root"
+	root.Name = "root"
+	root.Version = "1.0.0"
+	root.Checksum = map[string]string{"SHA256": "abc"}
+	root.AddPackageURL("pkg:golang/example.com/root@1.0.0")
+
+	a := NewPackage()
+	a.ID = "SPDXRef-Package-a"
+	a.Name = "a"
+	b := NewPackage()
+	b.ID = "SPDXRef-Package-b"
+	b.Name = "b"
+
+	require.Nil(t, root.AddPackage(a))
+	require.Nil(t, root.AddDependency(b))
+	require.Nil(t, a.AddDependency(b))
+	require.Nil(t, b.AddDependency(root))
+
+	var buf bytes.Buffer
+	require.Nil(t, root.WriteNDJSON(&buf))
+
+	records := map[string]ndjsonPackage{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		record := ndjsonPackage{}
+		require.Nil(t, json.Unmarshal(scanner.Bytes(), &record))
+		_, ok := records[record.ID]
+		require.False(t, ok, "package %s written twice", record.ID)
+		records[record.ID] = record
+	}
+	require.Len(t, records, 3)
+
+	require.Equal(t, "1.0.0", records[root.ID].Version)
+	require.Equal(t, "abc", records[root.ID].Checksums["SHA256"])
+	require.Equal(t, "pkg:golang/example.com/root@1.0.0", records[root.ID].Purl)
+	require.Equal(t, []ndjsonEdge{{ID: b.ID, Relationship: "DEPENDS_ON"}}, records[root.ID].Parents)
+
+	require.Equal(t, []ndjsonEdge{{ID: root.ID, Relationship: "CONTAINS"}}, records[a.ID].Parents)
+	require.Equal(t, []ndjsonEdge{
+		{ID: a.ID, Relationship: "DEPENDS_ON"},
+		{ID: root.ID, Relationship: "DEPENDS_ON"},
+	}, records[b.ID].Parents)
+}
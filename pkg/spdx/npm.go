@@ -0,0 +1,205 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const npmModulesDir = "node_modules/"
+
+// npmIDInvalidChars matches the characters of package names and
+// versions that are not valid in SPDX IDs
+var npmIDInvalidChars = regexp.MustCompile(validNameCharsRe)
+
+// npmIntegrityAlgorithms maps the hash names used in the integrity
+// strings of npm lockfiles to SPDX checksum algorithms
+var npmIntegrityAlgorithms = map[string]string{
+	"sha1":   "SHA1",
+	"sha256": "SHA256",
+	"sha384": "SHA384",
+	"sha512": "SHA512",
+}
+
+// npmLockFile is the data read from a package-lock.json file
+type npmLockFile struct {
+	Name            string                    `json:"name"`
+	Version         string                    `json:"version"`
+	LockfileVersion int                       `json:"lockfileVersion"`
+	Packages        map[string]npmLockPackage `json:"packages"`
+}
+
+// npmLockPackage is an entry of the packages map of a lockfile
+type npmLockPackage struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Resolved    string `json:"resolved"`
+	Integrity   string `json:"integrity"`
+	License     string `json:"license"`
+	Link        bool   `json:"link"`
+	Dev         bool   `json:"dev"`
+	Optional    bool   `json:"optional"`
+	DevOptional bool   `json:"devOptional"`
+}
+
+// FromNPMLock builds a SPDX package from an npm package-lock.json file.
+// The project is the root package and each of the resolved packages in
+// the lockfile becomes one of its dependencies. Only lockfiles version 2
+// and later are supported, as they list the packages in a map.
+func FromNPMLock(path string) (*Package, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading npm lockfile")
+	}
+	lock := &npmLockFile{}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, errors.Wrap(err, "parsing npm lockfile")
+	}
+	if lock.LockfileVersion < 2 || lock.Packages == nil {
+		return nil, errors.Errorf(
+			"unsupported npm lockfile version %d, the packages map is needed", lock.LockfileVersion,
+		)
+	}
+
+	root := lock.Packages[""]
+	if root.Name == "" {
+		root.Name = lock.Name
+	}
+	if root.Version == "" {
+		root.Version = lock.Version
+	}
+	if root.Name == "" {
+		return nil, errors.New("npm lockfile does not define a package name")
+	}
+	sourceInfo := "acquired package info from " + filepath.Base(path)
+	pkg := NewPackage()
+	pkg.Name = root.Name
+	pkg.Version = root.Version
+	pkg.LicenseDeclared = root.License
+	pkg.SourceInfo = sourceInfo
+	pkg.AddPackageURL(npmPackageURL(pkg.Name, pkg.Version))
+
+	// Sort the paths to add the dependencies in the same order every time
+	paths := []string{}
+	for p := range lock.Packages {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		entry := lock.Packages[p]
+		if p == "" {
+			continue
+		}
+		// Links point to workspace packages, their entries are listed
+		// separately under their own path
+		if entry.Link || entry.Version == "" {
+			logrus.Debugf("Skipping npm lockfile entry %s without a resolved version", p)
+			continue
+		}
+		dep, err := npmDependency(p, &entry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading npm lockfile entry %s", p)
+		}
+		// The same version can be installed in more than one path
+		if pkg.HasDependency(dep.ID) {
+			continue
+		}
+		dep.SourceInfo = sourceInfo
+		if err := pkg.AddDependency(dep); err != nil {
+			return nil, errors.Wrapf(err, "adding npm dependency %s", dep.Name)
+		}
+	}
+	return pkg, nil
+}
+
+// npmDependency builds the package of a lockfile entry installed at path
+func npmDependency(path string, entry *npmLockPackage) (*Package, error) {
+	name := entry.Name
+	if name == "" {
+		name = path
+		if i := strings.LastIndex(path, npmModulesDir); i != -1 {
+			name = path[i+len(npmModulesDir):]
+		}
+	}
+	idPart := func(s string) string {
+		return strings.Trim(npmIDInvalidChars.ReplaceAllString(s, "-"), "-")
+	}
+
+	dep := NewPackage()
+	dep.ID = "SPDXRef-Package-npm-" + idPart(name) + "-" + idPart(entry.Version)
+	dep.Name = name
+	dep.Version = entry.Version
+	dep.LicenseDeclared = entry.License
+	if entry.Resolved != "" {
+		dep.SetDownloadLocation(entry.Resolved)
+	}
+	switch {
+	case entry.Dev, entry.DevOptional:
+		dep.Scope = ScopeDev
+	case entry.Optional:
+		dep.Scope = ScopeOptional
+	}
+	if err := addNPMIntegrity(dep, entry.Integrity); err != nil {
+		return nil, err
+	}
+	dep.AddPackageURL(npmPackageURL(name, entry.Version))
+	return dep, nil
+}
+
+// addNPMIntegrity adds the checksums in a subresource integrity string,
+// a space separated list of algorithm-base64digest pairs, to pkg
+func addNPMIntegrity(pkg *Package, integrity string) error {
+	for _, hash := range strings.Fields(integrity) {
+		parts := strings.SplitN(hash, "-", 2)
+		if len(parts) != 2 {
+			return errors.Errorf("invalid integrity hash %q", hash)
+		}
+		algorithm, ok := npmIntegrityAlgorithms[parts[0]]
+		if !ok {
+			logrus.Warnf("Ignoring unsupported integrity hash algorithm %s", parts[0])
+			continue
+		}
+		digest, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return errors.Wrapf(err, "decoding %s integrity hash", parts[0])
+		}
+		if err := pkg.AddChecksum(algorithm, hex.EncodeToString(digest)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// npmPackageURL returns the purl of an npm package. The scope of scoped
+// packages is the namespace.
+func npmPackageURL(name, version string) string {
+	namespace := ""
+	if i := strings.Index(name, "/"); strings.HasPrefix(name, "@") && i != -1 {
+		namespace, name = name[:i], name[i+1:]
+	}
+	return buildPackageURL("npm", namespace, name, version, nil)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromNPMLock(t *testing.T) {
+	_, err := FromNPMLock("testdata/non-existent.json")
+	require.NotNil(t, err)
+
+	pkg, err := FromNPMLock("testdata/package-lock.json")
+	require.Nil(t, err)
+	require.Equal(t, "hello-npm", pkg.Name)
+	require.Equal(t, "1.2.0", pkg.Version)
+	require.Equal(t, "pkg:npm/hello-npm@1.2.0", pkg.ExternalRefs[0].Locator)
+
+	// left-pad is installed twice with the same version
+	require.Len(t, pkg.Dependencies, 4)
+	dep := pkg.Dependencies["SPDXRef-Package-npm-left-pad-1-3-0"]
+	require.NotNil(t, dep)
+	require.Equal(t, "1.3.0", dep.Version)
+	require.Equal(t,
+		"5d1720961877a7694702ee20160ef98b9a30677feeb6d219875d622a3f96d9fa"+
+			"9ce08bbc3afc081e40d27ed6b0e20511a34580d9f4f6a20bb04dae070a12f027",
+		dep.Checksum["SHA512"],
+	)
+	require.Equal(t, "https://registry.npmjs.org/left-pad/-/left-pad-1.3.0.tgz", dep.DownloadLocation)
+	require.Equal(t, DependencyScope(""), dep.Scope)
+
+	scoped := pkg.Dependencies["SPDXRef-Package-npm-babel-core-7-22-9"]
+	require.NotNil(t, scoped)
+	require.Equal(t, "@babel/core", scoped.Name)
+	require.Equal(t, "pkg:npm/%40babel/core@7.22.9", scoped.ExternalRefs[0].Locator)
+
+	require.Equal(t, ScopeDev, pkg.Dependencies["SPDXRef-Package-npm-jest-29-6-1"].Scope)
+	require.Equal(t, ScopeOptional, pkg.Dependencies["SPDXRef-Package-npm-fsevents-2-3-2"].Scope)
+
+	pkg.ID = "SPDXRef-Package-hello-npm"
+	out, err := pkg.Render()
+	require.Nil(t, err)
+	require.Contains(t, out,
+		"Relationship: SPDXRef-Package-npm-jest-29-6-1 DEV_DEPENDENCY_OF SPDXRef-Package-hello-npm\n",
+	)
+
+	// Version 1 lockfiles do not have the packages map
+	v1 := filepath.Join(t.TempDir(), "package-lock.json")
+	require.Nil(t, os.WriteFile(v1, []byte(`{"name": "old", "lockfileVersion": 1}`), 0o644))
+	_, err = FromNPMLock(v1)
+	require.NotNil(t, err)
+}
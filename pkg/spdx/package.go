@@ -20,47 +20,128 @@ import (
 	"bytes"
 	"crypto/sha1"
 	"fmt"
-	"html/template"
+	"io"
+	"net/url"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
 
 	"github.com/pkg/errors"
+	"k8s.io/release/pkg/license"
 	"sigs.k8s.io/release-utils/hash"
 	"sigs.k8s.io/release-utils/util"
 )
 
-var packageTemplate = `##### Package: {{ .Name }}
-
-{{ if .Name }}PackageName: {{ .Name }}
+// packageTagTemplates are the template snippets rendering each of the
+// package tags, they are assembled in the order of the tag lists below
+var packageTagTemplates = map[string]string{
+	"PackageName": `{{ if .Name }}PackageName: {{ .Name }}
 {{ end -}}
-{{ if .ID }}SPDXID: {{ .ID }}
+`,
+	"SPDXID": `{{ if .ID }}SPDXID: {{ .ID }}
 {{ end -}}
-{{- if .Checksum -}}
-{{- range $key, $value := .Checksum -}}
-{{ if . }}PackageChecksum: {{ $key }}: {{ $value }}
+`,
+	"PackageChecksum": `{{- if .Checksum -}}
+{{- range $key := checksumAlgorithms .Checksum -}}
+{{ with index $.Checksum $key }}PackageChecksum: {{ $key }}: {{ . }}
 {{ end -}}
 {{- end -}}
 {{- end -}}
-PackageDownloadLocation: {{ if .DownloadLocation }}{{ .DownloadLocation }}{{ else }}NONE{{ end }}
-FilesAnalyzed: {{ .FilesAnalyzed }}
-{{ if .VerificationCode }}PackageVerificationCode: {{ .VerificationCode }}
+`,
+	"PackageDownloadLocation": `PackageDownloadLocation: {{ if .DownloadLocation }}{{ .DownloadLocation }}{{ else }}NONE{{ end }}
+`,
+	"FilesAnalyzed": `FilesAnalyzed: {{ .FilesAnalyzed }}
+`,
+	"PackageVerificationCode": `{{ if .VerificationCode }}PackageVerificationCode: {{ .VerificationCode }}
+{{ end -}}
+`,
+	"PackageHomePage": `{{ if .HomePage }}PackageHomePage: {{ .HomePage }}
+{{ end -}}
+`,
+	"PackageSourceInfo": `{{ textField "PackageSourceInfo" .SourceInfo -}}
+`,
+	"PackageLicenseConcluded": `PackageLicenseConcluded: {{ if .LicenseConcluded }}{{ .LicenseConcluded }}{{ else }}NOASSERTION{{ end }}
+`,
+	"PackageFileName": `{{ if .ArchiveFileName }}PackageFileName: {{ .ArchiveFileName }}
 {{ end -}}
-PackageLicenseConcluded: {{ if .LicenseConcluded }}{{ .LicenseConcluded }}{{ else }}NOASSERTION{{ end }}
-{{ if .FileName }}PackageFileName: {{ .FileName }}
+`,
+	"PackageLicenseInfoFromFiles": `{{ if .LicenseInfoFromFiles }}{{- range $key, $value := .LicenseInfoFromFiles -}}PackageLicenseInfoFromFiles: {{ $value }}
 {{ end -}}
-{{ if .LicenseInfoFromFiles }}{{- range $key, $value := .LicenseInfoFromFiles -}}PackageLicenseInfoFromFiles: {{ $value }}
 {{ end -}}
+`,
+	"PackageVersion": `{{ if .Version }}PackageVersion: {{ .Version }}
 {{ end -}}
-{{ if .Version }}PackageVersion: {{ .Version }}
+`,
+	"PackageSupplier": `{{ with party .Supplier.Person .Supplier.Organization }}PackageSupplier: {{ . }}
 {{ end -}}
-PackageLicenseDeclared: {{ if .LicenseDeclared }}{{ .LicenseDeclared }}{{ else }}NOASSERTION{{ end }}
-PackageCopyrightText: {{ if .CopyrightText }}<text>{{ .CopyrightText }}
+`,
+	"PackageOriginator": `{{ with party .Originator.Person .Originator.Organization }}PackageOriginator: {{ . }}
+{{ end -}}
+`,
+	"PackageLicenseDeclared": `PackageLicenseDeclared: {{ if .LicenseDeclared }}{{ .LicenseDeclared }}{{ else }}NOASSERTION{{ end }}
+`,
+	"PackageLicenseComments": `{{ textField "PackageLicenseComments" .LicenseComments -}}
+`,
+	"PackageCopyrightText": `PackageCopyrightText: {{ if .CopyrightText }}<text>{{ escapeText .CopyrightText }}
 </text>{{ else }}NOASSERTION{{ end }}
+`,
+	"PackageSummary": `{{ textField "PackageSummary" .Summary -}}
+`,
+	"PackageDescription": `{{ textField "PackageDescription" .Description -}}
+`,
+	"PackageComment": `{{ textField "PackageComment" .Comment -}}
+`,
+	"ExternalRef": `{{ range .ExternalRefs }}ExternalRef: {{ .Category }} {{ .Type }} {{ .Locator }}
+{{ end -}}
+`,
+	"PackageAttributionText": `{{ range .AttributionText }}{{ textField "PackageAttributionText" . }}{{ end }}
+`,
+}
+
+// packageTags is the default order of the package tags
+var packageTags = []string{
+	"PackageName", "SPDXID", "PackageChecksum", "PackageDownloadLocation",
+	"FilesAnalyzed", "PackageVerificationCode", "PackageHomePage",
+	"PackageSourceInfo", "PackageLicenseConcluded", "PackageFileName",
+	"PackageLicenseInfoFromFiles", "PackageVersion", "PackageSupplier",
+	"PackageOriginator", "PackageLicenseDeclared", "PackageLicenseComments",
+	"PackageCopyrightText", "PackageSummary", "PackageDescription",
+	"PackageComment", "ExternalRef", "PackageAttributionText",
+}
+
+// packageSpecTags orders the same tags as packageTags following the
+// package information section of the SPDX 2.2 spec
+var packageSpecTags = []string{
+	"PackageName", "SPDXID", "PackageVersion", "PackageFileName",
+	"PackageSupplier", "PackageOriginator", "PackageDownloadLocation",
+	"FilesAnalyzed", "PackageVerificationCode", "PackageChecksum",
+	"PackageHomePage", "PackageSourceInfo", "PackageLicenseConcluded",
+	"PackageLicenseInfoFromFiles", "PackageLicenseDeclared",
+	"PackageLicenseComments", "PackageCopyrightText", "PackageSummary",
+	"PackageDescription", "PackageComment", "ExternalRef",
+	"PackageAttributionText",
+}
+
+var (
+	packageTemplate          = buildPackageTemplate(packageTags)
+	packageSpecOrderTemplate = buildPackageTemplate(packageSpecTags)
+)
 
-`
+// buildPackageTemplate assembles the package template rendering the
+// tags in order
+func buildPackageTemplate(tags []string) string {
+	var sb strings.Builder
+	sb.WriteString("##### Package: {{ .Name }}\n\n")
+	for _, tag := range tags {
+		sb.WriteString(packageTagTemplates[tag])
+	}
+	return sb.String()
+}
 
 // Package groups a set of files
 type Package struct {
@@ -69,17 +150,29 @@ type Package struct {
 	Name                 string   // hello-go-src
 	ID                   string   // SPDXRef-Package-hello-go-src
 	DownloadLocation     string   // git@github.com:swinslow/spdx-examples.git#example6/content/src
-	VerificationCode     string   // 6486e016b01e9ec8a76998cefd0705144d869234
+	VerificationCode     string   // Read by the parsers, it is computed from the files at render time and setting it has no effect
 	LicenseConcluded     string   // LicenseID o NOASSERTION
-	LicenseInfoFromFiles []string // GPL-3.0-or-later
+	LicenseInfoFromFiles []string // Read by the parsers, it is computed from the files at render time and setting it has no effect
 	LicenseDeclared      string   // GPL-3.0-or-later
 	LicenseComments      string   // record any relevant background information or analysis that went in to arriving at the Concluded License
 	CopyrightText        string   // string NOASSERTION
+	Summary              string   // Short description of the package
+	Description          string   // Detailed description of the package
+	Comment              string   // Free form comment about the package
+	SourceInfo           string   // Where the information about the package was obtained from
+	HomePage             string   // https://kubernetes.io, NONE or NOASSERTION
+	AttributionText      []string // Notices required to be reproduced with the package
 	Version              string   // Package version
-	FileName             string   // Name of the package
+	FileName             string   // Name of the package file, rendered when ArchiveFileName is not set
+	ArchiveFileName      string   // Name of the file backing the package, rendered as PackageFileName
 	SourceFile           string   // Source file for the package (taball for images, rpm, deb, etc)
 
-	// Supplier: the actual distribution source for the package/directory
+	// How to resolve the download location, rendered in the package comment
+	DownloadLocationComment string
+
+	// Supplier: the actual distribution source for the package/directory.
+	// Setting either field to NOASSERTION renders the supplier as unknown
+	// instead of omitting it.
 	Supplier struct {
 		Person       string // person name and optional (<email>)
 		Organization string // organization name and optional (<email>)
@@ -91,12 +184,26 @@ type Package struct {
 		Organization string // organization name and optional (<email>)
 	}
 	// Subpackages contained
-	Packages     map[string]*Package // Sub packages conatined in this pkg
-	Files        map[string]*File    // List of files
-	Checksum     map[string]string   // Checksum of the package
-	Dependencies map[string]*Package // Packages marked as dependencies
+	Packages      map[string]*Package // Sub packages conatined in this pkg
+	Files         map[string]*File    // List of files
+	Checksum      map[string]string   // Checksum of the package
+	Dependencies  map[string]*Package // Packages marked as dependencies
+	ExternalRefs  []ExternalRef       // List of references to external resources (purls, cpes, etc)
+	Relationships []*Relationship     // Other relationships to packages or files
+	Scope         DependencyScope     // When the package is needed as a dependency (runtime if empty)
+	FileProvider  FileProvider        // Reads the files when rendering instead of Files
+	IsPrimary     bool                // A document with a primary package only DESCRIBES that package
 
 	options *PackageOptions // Options
+	hashes  fileHashes      // Sorted checksums of the files, see AddFiles
+}
+
+// ExternalRef is a reference from the package to an external
+// source of information about it, such as a purl or a CPE
+type ExternalRef struct {
+	Category string `json:"category"` // SECURITY | PACKAGE-MANAGER | PERSISTENT-ID | OTHER
+	Type     string `json:"type"`     // cpe22Type, cpe23Type, maven-central, npm, nuget, bower, purl, swh, other
+	Locator  string `json:"locator"`  // pkg:deb/debian/bash@5.0-4?arch=amd64
 }
 
 func NewPackage() (p *Package) {
@@ -107,19 +214,206 @@ func NewPackage() (p *Package) {
 }
 
 type PackageOptions struct {
-	WorkDir string // Working directory to read files from
+	WorkDir         string        // Working directory to read files from
+	SymlinkPolicy   SymlinkPolicy // What to do with symlinks when reading directories
+	MaxDownloadSize int64         // Maximum size in bytes of remote sources, 0 means DefaultMaxDownloadSize and negative no limit
+	Concurrency     int           // Number of files hashed in parallel when reading directories
+	// Patterns to extract the version from source file names, the
+	// first capture group holds the version
+	VersionPatterns []*regexp.Regexp
+	// Logger receives progress messages, defaults to the logrus
+	// standard logger. Use NoopLogger to discard them.
+	Logger Logger
+	// Render all relationships in a block after the package and file
+	// bodies instead of after each element. Documents render this way
+	// when any of the packages they describe has the option set.
+	RelationshipsAtEnd bool
+	// Maximum nesting of packages when rendering, defaults to 256
+	MaxDepth int
+	// Add the CPE derived from purls passed to AddPackageURL, when
+	// one can be guessed with confidence
+	DeriveCPEs bool
+	// Return an error instead of logging a warning when files with the
+	// same name but different content are added to the package
+	StrictFileNames bool
+	// Hash files through memory maps on the platforms supporting them,
+	// which saves syscalls on large files. Files must not be truncated
+	// while they are hashed.
+	UseMmap bool
+	// Detects the licenses of the files added with AddFileFromPath
+	LicenseReader *license.Reader
+	// Read the copyright notices of the files added with AddFileFromPath
+	DetectCopyright bool
+	// Values treated as unknown by TrimEmptyFields, compared without
+	// case. DefaultPlaceholders is used when empty.
+	Placeholders []string
+	// How the IDs of files added without one are generated
+	FileIDScheme FileIDScheme
+	// Prepend a "Generated by <tool> <version> at <time>" line to the
+	// PackageComment, noting what produced the package data
+	EmitProvenanceComment bool
+	ProvenanceTool        string           // Defaults to k8s.io/release/pkg/spdx
+	ProvenanceVersion     string           // Version of the tool, omitted if empty
+	Clock                 func() time.Time // Returns the provenance time outside of documents, defaults to now
+	// Checksum algorithms rendered for the package, all the checksums
+	// in the Checksum map are rendered when empty
+	RenderAlgorithms []string
+	// Sentinel rendered in PackageLicenseInfoFromFiles when none of
+	// the analyzed files has license information
+	UnknownFileLicensePolicy UnknownFileLicensePolicy
+	// Fields ValidateStrict requires to have a value other than NONE
+	// or NOASSERTION. DefaultStrictFields is used when empty.
+	StrictFields []string
+	// Gitignore style patterns of the files skipped when reading
+	// directories, matched against their path relative to the directory.
+	// A ** segment matches any number of directories, eg vendor/**
+	ExcludeGlobs []string
+	// Render the package tags in the order of the SPDX spec, so the
+	// output diffs cleanly against the spec examples and other tools
+	SpecTagOrder bool
+	// Add a note on the dependency scope of the package, if set, to
+	// the PackageComment. ScopeComments overrides the notes of
+	// DefaultScopeComments per scope.
+	EmitScopeComment bool
+	ScopeComments    map[DependencyScope]string
+	// Line terminator of the rendered tag-value output, documents use
+	// CRLF when any of the packages they describe sets it
+	LineEnding LineEnding
 }
 
+// LineEnding selects the line terminator of the tag-value output
+type LineEnding int
+
+const (
+	// LineEndingLF ends lines with a line feed (default)
+	LineEndingLF LineEnding = iota
+
+	// LineEndingCRLF ends lines with a carriage return and a line
+	// feed, as expected by some Windows tools. Line breaks inside
+	// <text> blocks are converted too.
+	LineEndingCRLF
+)
+
+// UnknownFileLicensePolicy selects how packages whose files carry no
+// license information express it in PackageLicenseInfoFromFiles
+type UnknownFileLicensePolicy int
+
+const (
+	// UnknownFileLicenseNone asserts the files have no license (default)
+	UnknownFileLicenseNone UnknownFileLicensePolicy = iota
+
+	// UnknownFileLicenseNoAssertion states the licenses of the files
+	// were not determined
+	UnknownFileLicenseNoAssertion
+)
+
+// FileIDScheme selects the data hashed to generate file IDs
+type FileIDScheme int
+
+const (
+	// FileIDPackageScoped hashes the package ID, or its name, with the
+	// file name (default). IDs change when the package is renamed.
+	FileIDPackageScoped FileIDScheme = iota
+
+	// FileIDPath hashes only the file name, its path relative to the
+	// document root, so IDs survive package renames. Packages in the
+	// same document must not contain files with the same name.
+	FileIDPath
+
+	// FileIDContent hashes the SHA1 checksum of the file with its
+	// name, IDs stay the same as long as the file does not change
+	FileIDContent
+)
+
+// DefaultPlaceholders are the junk values importers commonly find in
+// package metadata in place of real data
+var DefaultPlaceholders = []string{"UNKNOWN", "N/A", "TBD", "TODO", "null", "undefined", "-", "?"}
+
+// defaultMaxDepth is the maximum nesting of packages rendered when
+// the package options do not set one
+const defaultMaxDepth = 256
+
 func (p *Package) Options() *PackageOptions {
 	return p.options
 }
 
+// provenanceComment returns the line noting the tool that generated
+// the package at created, or an empty string if the option is not set.
+// Packages rendered outside of a document have no creation time, they
+// use the clock in the options.
+func (p *Package) provenanceComment(created time.Time) string {
+	opts := p.Options()
+	if opts == nil || !opts.EmitProvenanceComment {
+		return ""
+	}
+	tool := opts.ProvenanceTool
+	if tool == "" {
+		tool = "k8s.io/release/pkg/spdx"
+	}
+	if opts.ProvenanceVersion != "" {
+		tool += " " + opts.ProvenanceVersion
+	}
+	if created.IsZero() {
+		created = time.Now()
+		if opts.Clock != nil {
+			created = opts.Clock()
+		}
+	}
+	return fmt.Sprintf("Generated by %s at %s", tool, created.UTC().Format(time.RFC3339))
+}
+
+// scopeComment returns the note on the dependency scope of the package,
+// or an empty string if the option is not set or the scope has no note
+func (p *Package) scopeComment() string {
+	opts := p.Options()
+	if opts == nil || !opts.EmitScopeComment || p.Scope == "" {
+		return ""
+	}
+	if comment, ok := opts.ScopeComments[p.Scope]; ok {
+		return comment
+	}
+	return DefaultScopeComments[p.Scope]
+}
+
+// renderedChecksums returns the package checksums computed with the
+// algorithms in the RenderAlgorithms option
+func (p *Package) renderedChecksums() map[string]string {
+	if p.Options() == nil || len(p.Options().RenderAlgorithms) == 0 {
+		return p.Checksum
+	}
+	checksums := map[string]string{}
+	for _, algorithm := range p.Options().RenderAlgorithms {
+		if value, ok := p.Checksum[algorithm]; ok {
+			checksums[algorithm] = value
+		}
+	}
+	return checksums
+}
+
+// maxDepth returns the maximum nesting of packages under p to render
+func (p *Package) maxDepth() int {
+	if p.Options() == nil || p.Options().MaxDepth <= 0 {
+		return defaultMaxDepth
+	}
+	return p.Options().MaxDepth
+}
+
 // ReadSourceFile reads the source file for the package and populates
-//  the package fields derived from it (Checksums and FileName)
+//  the package fields derived from it (Checksums, FileName and ArchiveFileName)
 func (p *Package) ReadSourceFile(path string) error {
 	if !util.Exists(path) {
 		return errors.New("unable to find package source file")
 	}
+	if p.Options() != nil && p.Options().UseMmap {
+		checksums, ok, err := checksumsForMappedFile(path)
+		if err != nil {
+			return errors.Wrap(err, "getting source file checksums")
+		}
+		if ok {
+			delete(checksums, "SHA1")
+			return p.setSourceFile(path, checksums)
+		}
+	}
 	s256, err := hash.SHA256ForFile(path)
 	if err != nil {
 		return errors.Wrap(err, "getting source file sha256")
@@ -128,74 +422,524 @@ func (p *Package) ReadSourceFile(path string) error {
 	if err != nil {
 		return errors.Wrap(err, "getting source file sha512")
 	}
-	p.Checksum = map[string]string{
+	return p.setSourceFile(path, map[string]string{
 		"SHA256": s256,
 		"SHA512": s512,
+	})
+}
+
+// ReadSourceBytes populates the package fields derived from the source
+// file (Checksums, FileName and ArchiveFileName) from data in memory,
+// recording them under name without reading the filesystem
+func (p *Package) ReadSourceBytes(name string, data []byte) error {
+	if name == "" {
+		return errors.New("source name is empty")
+	}
+	checksums := checksumsForBytes(data)
+	delete(checksums, "SHA1")
+	p.Checksum = normalizeChecksums(checksums)
+	p.SourceFile = ""
+	p.FileName = name
+	p.ArchiveFileName = name
+	return nil
+}
+
+// setSourceFile records path as the source file of the package
+func (p *Package) setSourceFile(path string, checksums map[string]string) error {
+	fileName, err := relativeFileName(p.Options().WorkDir, path)
+	if err != nil {
+		return errors.Wrap(err, "building package file name")
 	}
+	p.Checksum = normalizeChecksums(checksums)
 	p.SourceFile = path
-	p.FileName = strings.TrimPrefix(path, p.Options().WorkDir+string(filepath.Separator))
+	p.FileName = fileName
+	p.ArchiveFileName = fileName
+	return nil
+}
+
+// relativeFileName returns path relative to workDir in the form used
+// in SPDX file names: forward slash separated and prefixed with "./".
+// If workDir is empty, the path is considered relative to its own
+// directory. Paths outside of workDir return an error.
+func relativeFileName(workDir, path string) (string, error) {
+	if workDir == "" {
+		workDir = filepath.Dir(path)
+	}
+	absWorkDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return "", errors.Wrap(err, "getting absolute path of working directory")
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", errors.Wrap(err, "getting absolute path of file")
+	}
+	rel, err := filepath.Rel(absWorkDir, absPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting path of %s relative to %s", path, workDir)
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", errors.Errorf("%s is not contained in %s", path, workDir)
+	}
+	return "./" + rel, nil
+}
+
+// AddPackageURL adds a purl as an external reference of the package. If
+// the DeriveCPEs option is set, the CPE derived from the purl is added
+// too (see PurlToCPE).
+func (p *Package) AddPackageURL(purl string) {
+	p.ExternalRefs = append(p.ExternalRefs, ExternalRef{
+		Category: "PACKAGE-MANAGER",
+		Type:     "purl",
+		Locator:  purl,
+	})
+	if p.Options() != nil && p.Options().DeriveCPEs {
+		if cpe, ok := PurlToCPE(purl); ok {
+			p.AddCPE(cpe)
+		}
+	}
+}
+
+// AddCPE adds a CPE 2.3 name as a security external reference
+func (p *Package) AddCPE(cpe string) {
+	p.ExternalRefs = append(p.ExternalRefs, ExternalRef{
+		Category: "SECURITY",
+		Type:     "cpe23Type",
+		Locator:  cpe,
+	})
+}
+
+// SetLicenseConcluded sets the concluded license of the package. The SPDX
+// sentinels are recognized regardless of case: NONE asserts the package
+// has no license while NOASSERTION, or an empty expression, leaves the
+// license undetermined.
+func (p *Package) SetLicenseConcluded(expr string) {
+	p.LicenseConcluded = normalizeLicenseSentinel(expr)
+}
+
+// normalizeLicenseSentinel trims a license expression and converts the
+// NONE and NOASSERTION sentinels to their canonical form. An explicit
+// NOASSERTION is stored as empty, the value of an unset field.
+func normalizeLicenseSentinel(expr string) string {
+	expr = strings.TrimSpace(expr)
+	switch strings.ToUpper(expr) {
+	case NONE:
+		return NONE
+	case NOASSERTION:
+		return ""
+	}
+	return expr
+}
+
+// TrimEmptyFields cleans up the string fields of the package: it trims
+// their whitespace, unsets those left blank and replaces the values in
+// the Placeholders option with NOASSERTION. License and copyright fields
+// render NOASSERTION when unset, so they are unset too. Fields where
+// SPDX does not allow NOASSERTION are unset. Empty values are removed
+// from the lists of strings.
+func (p *Package) TrimEmptyFields() {
+	placeholders := DefaultPlaceholders
+	if p.Options() != nil && len(p.Options().Placeholders) > 0 {
+		placeholders = p.Options().Placeholders
+	}
+	isPlaceholder := func(value string) bool {
+		for _, placeholder := range placeholders {
+			if strings.EqualFold(value, placeholder) {
+				return true
+			}
+		}
+		return false
+	}
+	clean := func(value string) string {
+		value = strings.TrimSpace(value)
+		if isPlaceholder(value) {
+			return ""
+		}
+		return value
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	location := strings.TrimSpace(p.DownloadLocation)
+	trimStringFields(reflect.ValueOf(p).Elem(), clean)
+	if isPlaceholder(location) {
+		p.DownloadLocation = NOASSERTION
+	}
+	p.LicenseConcluded = normalizeLicenseSentinel(p.LicenseConcluded)
+	p.LicenseDeclared = normalizeLicenseSentinel(p.LicenseDeclared)
+}
+
+// trimStringFields applies clean to the exported strings of a struct,
+// including those in nested structs and string lists. Empty strings are
+// removed from the lists.
+func trimStringFields(v reflect.Value, clean func(string) string) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" || field.Anonymous {
+			continue
+		}
+		value := v.Field(i)
+		switch {
+		case value.Kind() == reflect.String:
+			value.SetString(clean(value.String()))
+		case value.Kind() == reflect.Struct:
+			trimStringFields(value, clean)
+		case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String:
+			if value.IsNil() {
+				continue
+			}
+			list := []string{}
+			for _, s := range value.Interface().([]string) {
+				if s = clean(s); s != "" {
+					list = append(list, s)
+				}
+			}
+			value.Set(reflect.ValueOf(list))
+		}
+	}
+}
+
+// AddChecksum records a checksum of the package. The algorithm has to be
+// one of those supported by SPDX and value its hex encoded digest, which
+// is stored in lowercase.
+func (p *Package) AddChecksum(algorithm, value string) error {
+	if err := validateChecksum(algorithm, value); err != nil {
+		return errors.Wrap(err, "validating package checksum")
+	}
+	p.Lock()
+	defer p.Unlock()
+	if p.Checksum == nil {
+		p.Checksum = map[string]string{}
+	}
+	p.Checksum[algorithm] = strings.ToLower(value)
 	return nil
 }
 
-// AddFile adds a file contained in the package
+// packageURL returns the first purl found in the external refs
+func (p *Package) packageURL() string {
+	for _, ref := range p.ExternalRefs {
+		if ref.Type == "purl" {
+			return ref.Locator
+		}
+	}
+	return ""
+}
+
+// sortedPackageIDs returns the keys of a package map in sorted order
+func sortedPackageIDs(packages map[string]*Package) []string {
+	ids := []string{}
+	for id := range packages {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// sortedFileIDs returns the keys of a file map in sorted order
+func sortedFileIDs(files map[string]*File) []string {
+	ids := []string{}
+	for id := range files {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// AddFile adds a file contained in the package. It returns an error if
+// the package has a different file, with another name, under the same ID.
 func (p *Package) AddFile(file *File) error {
+	return p.AddFiles([]*File{file})
+}
+
+// AddFileWithID adds file to the package under id, replacing the ID the
+// file may have. Like AddFile, it returns an error if the package has a
+// different file with the same ID.
+func (p *Package) AddFileWithID(id string, file *File) error {
+	if id == "" {
+		return errors.New("unable to add file, the ID is empty")
+	}
+	previous := file.ID
+	file.ID = id
+	if err := p.AddFile(file); err != nil {
+		file.ID = previous
+		return err
+	}
+	return nil
+}
+
+// AddFileFromPath reads the file at path, relative to the WorkDir in the
+// package options unless it is absolute, and adds it to the package. The
+// file is named after its path relative to WorkDir and gets all the
+// checksums, including the SHA1 needed for the verification code. Its
+// license is detected when the LicenseReader option is set and its
+// copyright notices are read when DetectCopyright is. It returns the
+// file added.
+func (p *Package) AddFileFromPath(path string) (*File, error) {
+	opts := p.Options()
+	if opts == nil {
+		opts = &PackageOptions{}
+	}
+	workDir := opts.WorkDir
+	if workDir == "" {
+		workDir = "."
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
+	}
+	name, err := relativeFileName(workDir, path)
+	if err != nil {
+		return nil, errors.Wrap(err, "building file name")
+	}
+
+	f := NewFile()
+	f.Options().WorkDir = workDir
+	f.Options().UseMmap = opts.UseMmap
+	if err := f.ReadSourceFile(path); err != nil {
+		return nil, errors.Wrap(err, "reading file data")
+	}
+	f.Name = name
+	// Let AddFiles derive the ID from the file name, the content
+	// based ID from ReadSourceFile collides on identical files
+	f.ID = ""
+
+	if opts.LicenseReader != nil {
+		l, err := opts.LicenseReader.LicenseFromFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "detecting license of %s", name)
+		}
+		if l != nil && l.LicenseID != "" {
+			p.logger().Debugf("Detected license %s in %s", l.LicenseID, name)
+			f.LicenseInfoInFile = []string{l.LicenseID}
+		}
+	}
+	if opts.DetectCopyright {
+		if err := f.ReadCopyright(path); err != nil {
+			return nil, errors.Wrapf(err, "reading copyright of %s", name)
+		}
+	}
+
+	if err := p.AddFile(f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// AddFiles adds a list of files to the package acquiring its lock only
+// once. Files without an ID get one generated from their name, they
+// replace a file with the same name and are rejected if the ID belongs
+// to a file with another name. Files with an ID set are rejected if the
+// package holds a different file with that ID. If any of the files
+// cannot be added, the package is left unchanged.
+func (p *Package) AddFiles(files []*File) error {
 	p.Lock()
 	defer p.Unlock()
-	if p.Files == nil {
-		p.Files = map[string]*File{}
+
+	// Compute all IDs before modifying anything
+	ids := make([]string, len(files))
+	explicit := make([]bool, len(files))
+	for i, file := range files {
+		explicit[i] = file.ID != ""
+		id, err := p.fileID(file)
+		if err != nil {
+			return errors.Wrapf(err, "adding file #%d", i)
+		}
+		if p.ID != "" && id == p.ID {
+			return errors.Errorf("adding file #%d: its ID %s is the package ID, a package can not contain itself", i, id)
+		}
+		ids[i] = id
 	}
-	// If file does not have an ID, we try to build one
-	// by hashing the file name
-	if file.ID == "" {
-		if file.Name == "" {
-			return errors.New("unable to generate file ID, filename not set")
+
+	// Generated IDs are derived from the file names, so files with the
+	// same name replace each other. Explicit IDs never replace a file.
+	added := make(map[string]*File, len(files))
+	for i, file := range files {
+		previous, ok := added[ids[i]]
+		if !ok {
+			previous, ok = p.Files[ids[i]]
 		}
-		if p.Name == "" {
-			return errors.New("unable to generate file ID, package not set")
+		if ok && previous != file && (explicit[i] || previous.Name != file.Name) {
+			return errors.Errorf("adding file #%d: the package already has a file with ID %s", i, ids[i])
 		}
-		h := sha1.New()
-		if _, err := h.Write([]byte(p.Name + ":" + file.Name)); err != nil {
-			return errors.Wrap(err, "getting sha1 of filename")
+		if ok && previous != file {
+			if err := p.duplicateFile(file.Name, previous, file); err != nil {
+				return err
+			}
 		}
-		file.ID = "SPDXRef-File-" + fmt.Sprintf("%x", h.Sum(nil))
+		added[ids[i]] = file
+	}
+
+	if p.Files == nil {
+		p.Files = make(map[string]*File, len(files))
+	}
+	for i, file := range files {
+		file.ID = ids[i]
+		p.Files[file.ID] = file
+		p.hashes.add(file.ID, file.Checksum["SHA1"])
 	}
-	p.Files[file.ID] = file
 	return nil
 }
 
+// ResolveRelativePaths rewrites the absolute names of the package files
+// to be relative to root, in the "./" prefixed and forward slash
+// separated form used in SPDX file names. File IDs are not changed. If
+// any of the files is outside of root, an error is returned and no names
+// are changed.
+func (p *Package) ResolveRelativePaths(root string) error {
+	p.Lock()
+	defer p.Unlock()
+
+	names := map[string]string{}
+	for _, id := range sortedFileIDs(p.Files) {
+		f := p.Files[id]
+		if !filepath.IsAbs(f.Name) {
+			rel := filepath.ToSlash(filepath.Clean(f.Name))
+			if rel == ".." || strings.HasPrefix(rel, "../") {
+				return errors.Errorf("%s is not contained in %s", f.Name, root)
+			}
+			continue
+		}
+		name, err := relativeFileName(root, f.Name)
+		if err != nil {
+			return errors.Wrapf(err, "resolving path of file %s", id)
+		}
+		names[id] = name
+	}
+	for id, name := range names {
+		p.Files[id].Name = name
+	}
+	return nil
+}
+
+// RemoveFile removes the file with id from the package
+func (p *Package) RemoveFile(id string) error {
+	p.Lock()
+	defer p.Unlock()
+	if _, ok := p.Files[id]; !ok {
+		return errors.Errorf("package %s does not contain file %s", p.ID, id)
+	}
+	delete(p.Files, id)
+	p.hashes.remove(id)
+	return nil
+}
+
+// duplicateFile reports two files with the same name in the package. It
+// returns an error if their checksums differ and the StrictFileNames
+// option is set, otherwise it logs a warning.
+func (p *Package) duplicateFile(name string, a, b *File) error {
+	if reflect.DeepEqual(a.Checksum, b.Checksum) {
+		return nil
+	}
+	if p.Options() != nil && p.Options().StrictFileNames {
+		return errors.Errorf("package %s has more than one file named %s with different content", p.ID, name)
+	}
+	p.logger().Warnf("Package %s has more than one file named %s with different content", p.ID, name)
+	return nil
+}
+
+// fileID returns the ID of a file. If file does not have an ID,
+// we try to build one by hashing the file name with the package ID,
+// or the package name if the package has no ID yet. The FileIDScheme
+// option replaces the package with other stable data.
+func (p *Package) fileID(file *File) (string, error) {
+	if file.ID != "" {
+		return file.ID, nil
+	}
+	if file.Name == "" {
+		return "", errors.New("unable to generate file ID, filename not set")
+	}
+	scheme := FileIDPackageScoped
+	if p.Options() != nil {
+		scheme = p.Options().FileIDScheme
+	}
+	var basis string
+	switch scheme {
+	case FileIDPackageScoped:
+		scope := p.ID
+		if scope == "" {
+			scope = p.Name
+		}
+		if scope == "" {
+			return "", errors.New("unable to generate file ID, package not set")
+		}
+		basis = scope + ":" + file.Name
+	case FileIDPath:
+		basis = file.Name
+	case FileIDContent:
+		if file.Checksum["SHA1"] == "" {
+			return "", errors.Errorf("unable to generate file ID, %s has no SHA1 checksum", file.Name)
+		}
+		basis = file.Checksum["SHA1"] + ":" + file.Name
+	default:
+		return "", errors.Errorf("unknown file ID scheme %d", scheme)
+	}
+	h := sha1.New()
+	if _, err := h.Write([]byte(basis)); err != nil {
+		return "", errors.Wrap(err, "getting sha1 of filename")
+	}
+	return "SPDXRef-File-" + fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// packageIDFromName derives a package ID from its name by removing the
+// characters not valid in IDs. Names with non ASCII characters, which
+// could otherwise be stripped down to the same ID, or with no valid
+// characters at all get a hash of the full name appended.
+func packageIDFromName(name string) string {
+	reg := regexp.MustCompile(validNameCharsRe)
+	id := reg.ReplaceAllString(name, "")
+	ascii := true
+	for _, r := range name {
+		if r > unicode.MaxASCII {
+			ascii = false
+			break
+		}
+	}
+	if ascii && id != "" {
+		return "SPDXRef-Package-" + id
+	}
+	sum := sha1.Sum([]byte(name))
+	if id == "" {
+		return fmt.Sprintf("SPDXRef-Package-%x", sum[:8])
+	}
+	return fmt.Sprintf("SPDXRef-Package-%s-%x", id, sum[:8])
+}
+
 // preProcessSubPackage performs a basic check on a package
 // to ensure it can be added as a subpackage, trying to infer
-// missing data when possible
-func (p *Package) preProcessSubPackage(pkg *Package) error {
-	if pkg.ID == "" {
+// missing data when possible. A package can be both a subpackage
+// and a dependency, but its ID cannot be taken by another package.
+func (p *Package) preProcessSubPackage(pkg *Package, dependency bool) error {
+	if pkg.ID == "" && pkg.Name != "" {
 		// If we so not have an ID but have a name generate it fro there
-		reg := regexp.MustCompile(validNameCharsRe)
-		id := reg.ReplaceAllString(pkg.Name, "")
-		if id != "" {
-			pkg.ID = "SPDXRef-Package-" + id
-		}
+		pkg.ID = packageIDFromName(pkg.Name)
 	}
 	if pkg.ID == "" {
 		return errors.New("package name is needed to add a new package")
 	}
-	if _, ok := p.Packages[pkg.ID]; ok {
+	// SPDX elements can not contain or depend on themselves
+	if pkg == p || pkg.ID == p.ID {
+		return errors.New("package " + pkg.ID + " can not be added to itself")
+	}
+	if existing, ok := p.Packages[pkg.ID]; ok && (!dependency || existing != pkg) {
 		return errors.New("a package named " + pkg.ID + " already exists as a subpackage")
 	}
 
-	if _, ok := p.Dependencies[pkg.ID]; ok {
+	if existing, ok := p.Dependencies[pkg.ID]; ok && (dependency || existing != pkg) {
 		return errors.New("a package named " + pkg.ID + " already exists as a dependency")
 	}
 
 	return nil
 }
 
-// AddPackage adds a new subpackage to a package
+// AddPackage adds a new subpackage to a package. A dependency of the
+// package can be added too, both relationships are rendered.
 func (p *Package) AddPackage(pkg *Package) error {
+	p.Lock()
+	defer p.Unlock()
 	if p.Packages == nil {
 		p.Packages = map[string]*Package{}
 	}
 
-	if err := p.preProcessSubPackage(pkg); err != nil {
+	if err := p.preProcessSubPackage(pkg, false); err != nil {
 		return errors.Wrap(err, "performing subpackage preprocessing")
 	}
 
@@ -205,11 +949,13 @@ func (p *Package) AddPackage(pkg *Package) error {
 
 // AddDependency adds a new subpackage as a dependency
 func (p *Package) AddDependency(pkg *Package) error {
+	p.Lock()
+	defer p.Unlock()
 	if p.Dependencies == nil {
 		p.Dependencies = map[string]*Package{}
 	}
 
-	if err := p.preProcessSubPackage(pkg); err != nil {
+	if err := p.preProcessSubPackage(pkg, true); err != nil {
 		return errors.Wrap(err, "performing subpackage preprocessing")
 	}
 
@@ -217,110 +963,688 @@ func (p *Package) AddDependency(pkg *Package) error {
 	return nil
 }
 
-// Render renders the document fragment of the package
-func (p *Package) Render() (docFragment string, err error) {
-	var buf bytes.Buffer
-	tmpl, err := template.New("package").Parse(packageTemplate)
-	if err != nil {
-		return "", errors.Wrap(err, "parsing package template")
+// AddDependencyByCoordinates creates a package for version of name in
+// the ecosystem (the purl type, eg golang or npm) and adds it as a
+// dependency. The new package is returned to be enriched by the caller.
+func (p *Package) AddDependencyByCoordinates(ecosystem, name, version string) (*Package, error) {
+	if ecosystem == "" || name == "" {
+		return nil, errors.New("ecosystem and name are needed to add a dependency")
+	}
+	dep := NewPackage()
+	dep.Name = name
+	dep.Version = version
+	dep.ID = coordinatesPackageID(ecosystem, name, version)
+	dep.AddPackageURL(coordinatesPackageURL(ecosystem, name, version))
+	if err := p.AddDependency(dep); err != nil {
+		return nil, errors.Wrapf(err, "adding dependency %s", name)
 	}
+	return dep, nil
+}
 
-	// If files were analyzed, calculate the verification which
-	// is a sha1sum from all sha1 checksumf from included friles.
-	//
-	// Since we are already doing it, we use the same loop to
-	// collect license tags to express them in the LicenseInfoFromFiles
-	// entry of the SPDX package:
-	filesTagList := []string{}
-	if p.FilesAnalyzed {
-		if len(p.Files) == 0 {
-			return docFragment, errors.New("unable to get package verification code, package has no files")
-		}
-		shaList := []string{}
-		for _, f := range p.Files {
-			if f.Checksum == nil {
-				return docFragment, errors.New("unable to render package, file has no checksums")
-			}
-			if _, ok := f.Checksum["SHA1"]; !ok {
-				return docFragment, errors.New("unable to render package, files were analyzed but some do not have sha1 checksum")
+// coordinatesPackageID returns the SPDX ID of the package at version of
+// name in the ecosystem. Go modules get the same IDs as FromGoBinary.
+func coordinatesPackageID(ecosystem, name, version string) string {
+	if ecosystem == "golang" {
+		return goPackageID(name, version)
+	}
+	reg := regexp.MustCompile(validNameCharsRe)
+	id := "SPDXRef-Package-" + strings.Trim(reg.ReplaceAllString(ecosystem+"-"+name, "-"), "-")
+	if version != "" {
+		id += "-" + strings.Trim(reg.ReplaceAllString(version, "-"), "-")
+	}
+	return id
+}
+
+// ReplacePackage swaps the subpackage or dependency with id for pkg,
+// which takes the same ID. The relationships pointing to the replaced
+// package from any package or file in the tree are updated to point to
+// pkg. It returns an error if the package has no subpackage or
+// dependency with id, if pkg is the package itself or if pkg is already
+// one of its subpackages or dependencies with a different ID.
+func (p *Package) ReplacePackage(id string, pkg *Package) error {
+	if pkg == nil {
+		return errors.New("unable to replace package, new package is nil")
+	}
+	// SPDX elements can not contain or depend on themselves
+	if pkg == p {
+		return errors.Errorf("package %s can not replace one of its own subpackages", p.ID)
+	}
+	p.Lock()
+	defer p.Unlock()
+
+	for _, list := range []map[string]*Package{p.Packages, p.Dependencies} {
+		for existingID, existing := range list {
+			if existing == pkg && existingID != id {
+				return errors.Errorf(
+					"package %s already has the replacement package as %s", p.ID, existingID,
+				)
 			}
-			shaList = append(shaList, f.Checksum["SHA1"])
+		}
+	}
 
-			// Collect the license tags
-			if f.LicenseInfoInFile != "" {
-				collected := false
-				for _, tag := range filesTagList {
-					if tag == f.LicenseInfoInFile {
-						collected = true
-						break
-					}
-				}
-				if !collected {
-					filesTagList = append(filesTagList, f.LicenseInfoInFile)
+	old, inPackages := p.Packages[id]
+	if dep, ok := p.Dependencies[id]; ok {
+		if inPackages && dep != old {
+			return errors.Errorf("package %s has two different packages with id %s", p.ID, id)
+		}
+		old = dep
+		p.Dependencies[id] = pkg
+	} else if !inPackages {
+		return errors.Errorf("package %s does not contain package %s", p.ID, id)
+	}
+	if inPackages {
+		p.Packages[id] = pkg
+	}
+	pkg.ID = id
+
+	// p is already locked, the rest of the tree is locked as it is walked
+	seen := map[*Package]struct{}{old: {}}
+	var rewire func(q *Package)
+	rewire = func(q *Package) {
+		if _, ok := seen[q]; ok {
+			return
+		}
+		seen[q] = struct{}{}
+		if q != p {
+			q.Lock()
+			defer q.Unlock()
+		}
+		rewireRelationships(q.Relationships, old, pkg)
+		for _, f := range q.Files {
+			rewireRelationships(f.Relationships, old, pkg)
+		}
+		for _, sub := range q.Packages {
+			rewire(sub)
+		}
+		for _, dep := range q.Dependencies {
+			rewire(dep)
+		}
+	}
+	rewire(p)
+	return nil
+}
+
+// rewireRelationships points the relationships with the old package as
+// peer to pkg
+func rewireRelationships(relationships []*Relationship, old, pkg *Package) {
+	for _, r := range relationships {
+		if r.Package == old {
+			r.Package = pkg
+		}
+	}
+}
+
+// HasPackage returns true if the package contains a subpackage with id
+func (p *Package) HasPackage(id string) bool {
+	p.RLock()
+	defer p.RUnlock()
+	_, ok := p.Packages[id]
+	return ok
+}
+
+// HasDependency returns true if the package has a dependency with id
+func (p *Package) HasDependency(id string) bool {
+	p.RLock()
+	defer p.RUnlock()
+	_, ok := p.Dependencies[id]
+	return ok
+}
+
+// HasFile returns true if the package contains a file with id
+func (p *Package) HasFile(id string) bool {
+	p.RLock()
+	defer p.RUnlock()
+	_, ok := p.Files[id]
+	return ok
+}
+
+// SetDownloadLocation sets the download location of the package. The
+// credentials in URLs are removed so they do not leak into the SBOM,
+// in that case DownloadLocationComment notes that they are required.
+// Only ssh URLs keep a user name without a password, like
+// git+ssh://git@host/repo, other URLs may use tokens as user names.
+func (p *Package) SetDownloadLocation(location string) {
+	u, err := url.Parse(location)
+	if err != nil || u.User == nil {
+		p.DownloadLocation = location
+		return
+	}
+	if _, ok := u.User.Password(); !ok && sshURLSchemes[u.Scheme] {
+		p.DownloadLocation = location
+		return
+	}
+	u.User = nil
+	p.DownloadLocation = u.String()
+	if p.DownloadLocationComment == "" {
+		p.DownloadLocationComment = fmt.Sprintf(
+			"Credentials were removed from the download location, %s requires authentication", u.Host,
+		)
+	}
+}
+
+// sshURLSchemes are the URL schemes where the user name is an account
+// name and not a credential
+var sshURLSchemes = map[string]bool{"ssh": true, "git+ssh": true}
+
+// SortedFiles returns a snapshot of the package files sorted by ID
+func (p *Package) SortedFiles() []*File {
+	p.RLock()
+	defer p.RUnlock()
+	files := make([]*File, 0, len(p.Files))
+	for _, id := range sortedFileIDs(p.Files) {
+		files = append(files, p.Files[id])
+	}
+	return files
+}
+
+// SortedPackages returns a snapshot of the subpackages sorted by ID
+func (p *Package) SortedPackages() []*Package {
+	p.RLock()
+	defer p.RUnlock()
+	packages := make([]*Package, 0, len(p.Packages))
+	for _, id := range sortedPackageIDs(p.Packages) {
+		packages = append(packages, p.Packages[id])
+	}
+	return packages
+}
+
+// Flatten returns every unique package in the tree, starting with p and
+// followed by its subpackages and dependencies in depth-first ID order.
+// Packages reachable through more than one path are listed once. The
+// returned packages are shallow copies with their subpackages,
+// dependencies and relationships removed.
+func (p *Package) Flatten() []*Package {
+	list := []*Package{}
+	p.forEachUniquePackage(func(pkg *Package) {
+		flat := NewPackage()
+		copyExportedFields(flat, pkg)
+		flat.options = pkg.options
+		flat.Packages = nil
+		flat.Dependencies = nil
+		flat.Relationships = nil
+		list = append(list, flat)
+	})
+	return list
+}
+
+// Prune removes the subpackages and dependencies matching predicate
+// from every package in the tree under p. Packages below a removed one
+// are dropped with it unless they are still reachable through another
+// path. The relationships of the remaining packages and files pointing
+// to any dropped package are removed too. It returns the number of
+// unique packages dropped. The root package itself is never removed.
+func (p *Package) Prune(predicate func(*Package) bool) int {
+	before := p.reachablePackages()
+	kept := map[*Package]struct{}{}
+	var prune func(pkg *Package)
+	prune = func(pkg *Package) {
+		if _, ok := kept[pkg]; ok {
+			return
+		}
+		kept[pkg] = struct{}{}
+
+		pkg.Lock()
+		children := []*Package{}
+		for _, packages := range []map[string]*Package{pkg.Packages, pkg.Dependencies} {
+			for _, id := range sortedPackageIDs(packages) {
+				sub := packages[id]
+				if predicate(sub) {
+					delete(packages, id)
+					continue
 				}
+				children = append(children, sub)
 			}
 		}
-		sort.Strings(shaList)
-		h := sha1.New()
-		if _, err := h.Write([]byte(strings.Join(shaList, ""))); err != nil {
-			return docFragment, errors.Wrap(err, "getting sha1 verification of files")
+		pkg.Unlock()
+		for _, sub := range children {
+			prune(sub)
 		}
-		p.VerificationCode = fmt.Sprintf("%x", h.Sum(nil))
+	}
+	prune(p)
 
-		for _, tag := range filesTagList {
-			if tag != NONE && tag != NOASSERTION {
-				p.LicenseInfoFromFiles = append(p.LicenseInfoFromFiles, tag)
+	// Drop the edges once all the packages no longer in the tree are known
+	after := p.reachablePackages()
+	dropped := map[*Package]struct{}{}
+	for pkg := range before {
+		if _, ok := after[pkg]; !ok {
+			dropped[pkg] = struct{}{}
+		}
+	}
+	keep := func(relationships []*Relationship) []*Relationship {
+		filtered := relationships[:0]
+		for _, r := range relationships {
+			if _, ok := dropped[r.Package]; ok {
+				continue
 			}
+			filtered = append(filtered, r)
 		}
-
-		// If no license tags where collected from files, then
-		// the BOM has to express "NONE" in the LicenseInfoFromFiles
-		// section to be compliant:
-		if len(filesTagList) == 0 {
-			p.LicenseInfoFromFiles = append(p.LicenseInfoFromFiles, NONE)
+		return filtered
+	}
+	for pkg := range after {
+		pkg.Lock()
+		pkg.Relationships = keep(pkg.Relationships)
+		for _, f := range pkg.Files {
+			f.Relationships = keep(f.Relationships)
 		}
+		pkg.Unlock()
 	}
+	return len(dropped)
+}
 
-	// Run the template to verify the output.
-	if err := tmpl.Execute(&buf, p); err != nil {
-		return "", errors.Wrap(err, "executing spdx package template")
-	}
+// reachablePackages returns the set of unique packages in the tree of
+// p, including p itself
+func (p *Package) reachablePackages() map[*Package]struct{} {
+	reachable := map[*Package]struct{}{}
+	p.forEachUniquePackage(func(pkg *Package) {
+		reachable[pkg] = struct{}{}
+	})
+	return reachable
+}
 
-	docFragment = buf.String()
+// forEachUniquePackage calls fn for p and then for each package in its
+// subpackages and dependencies, depth first with the children of each
+// package sorted by ID. Packages reachable through more than one path,
+// or through cycles, are visited once. The package is read locked
+// while fn runs.
+func (p *Package) forEachUniquePackage(fn func(pkg *Package)) {
+	seen := map[string]struct{}{}
+	var walk func(pkg *Package)
+	walk = func(pkg *Package) {
+		if _, ok := seen[pkg.ID]; ok {
+			return
+		}
+		seen[pkg.ID] = struct{}{}
 
-	for _, f := range p.Files {
-		fileFragment, err := f.Render()
+		pkg.RLock()
+		fn(pkg)
+		children := []*Package{}
+		for _, list := range []map[string]*Package{pkg.Packages, pkg.Dependencies} {
+			for _, id := range sortedPackageIDs(list) {
+				children = append(children, list[id])
+			}
+		}
+		pkg.RUnlock()
+
+		sort.SliceStable(children, func(i, j int) bool { return children[i].ID < children[j].ID })
+		for _, child := range children {
+			walk(child)
+		}
+	}
+	walk(p)
+}
+
+// VerificationCode computes the SPDX package verification code of a set
+// of files: the sha1 of their sorted sha1 checksums. Files whose names
+// are listed in excludes are left out of the computation.
+func VerificationCode(files map[string]*File, excludes []string) (string, error) {
+	excluded := map[string]struct{}{}
+	for _, name := range excludes {
+		excluded[name] = struct{}{}
+	}
+	shaList := []string{}
+	for _, f := range files {
+		if _, ok := excluded[f.Name]; ok {
+			continue
+		}
+		sha, err := verificationFileSHA1(f)
 		if err != nil {
-			return "", errors.Wrap(err, "rendering file "+f.Name)
+			return "", err
 		}
-		docFragment += fileFragment
-		docFragment += fmt.Sprintf("Relationship: %s CONTAINS %s\n\n", p.ID, f.ID)
+		shaList = append(shaList, sha)
 	}
+	return packageVerificationCode(shaList)
+}
 
-	// Print the contained sub packages
-	if p.Packages != nil {
-		for _, pkg := range p.Packages {
-			pkgDoc, err := pkg.Render()
-			if err != nil {
-				return "", errors.Wrap(err, "rendering pkg "+pkg.Name)
+// verificationFileSHA1 returns the sha1 checksum of a file that goes
+// into the package verification code
+func verificationFileSHA1(f *File) (string, error) {
+	if f.Checksum == nil {
+		return "", errors.New("unable to render package, file has no checksums")
+	}
+	sha, ok := f.Checksum["SHA1"]
+	if !ok {
+		return "", errors.New("unable to render package, files were analyzed but some do not have sha1 checksum")
+	}
+	return sha, nil
+}
+
+// packageVerificationCode returns the verification code of the file
+// sha1 checksums of a package, which needs at least one file
+func packageVerificationCode(shaList []string) (string, error) {
+	if len(shaList) == 0 {
+		return "", errors.New("unable to get package verification code, package has no files")
+	}
+	return sha1VerificationCode(shaList)
+}
+
+// sha1VerificationCode returns the sha1 of the sorted and concatenated
+// list of file sha1 checksums
+func sha1VerificationCode(shaList []string) (string, error) {
+	sorted := append([]string{}, shaList...)
+	sort.Strings(sorted)
+	h := sha1.New()
+	if _, err := h.Write([]byte(strings.Join(sorted, ""))); err != nil {
+		return "", errors.Wrap(err, "getting sha1 verification of files")
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// DeepVerificationCode computes a verification code covering the files
+// of the package and of all the subpackages it contains, recursively.
+// This is not part of the SPDX spec, which only considers the files of
+// the package itself in the PackageVerificationCode.
+func (p *Package) DeepVerificationCode() (string, error) {
+	shaList := []string{}
+	seen := map[string]struct{}{}
+	var collect func(pkg *Package) error
+	collect = func(pkg *Package) error {
+		if _, ok := seen[pkg.ID]; ok {
+			return nil
+		}
+		seen[pkg.ID] = struct{}{}
+
+		pkg.RLock()
+		defer pkg.RUnlock()
+		for _, id := range sortedFileIDs(pkg.Files) {
+			sha, ok := pkg.Files[id].Checksum["SHA1"]
+			if !ok {
+				return errors.Errorf("file %s in package %s does not have a sha1 checksum", id, pkg.ID)
+			}
+			shaList = append(shaList, sha)
+		}
+		for _, id := range sortedPackageIDs(pkg.Packages) {
+			if err := collect(pkg.Packages[id]); err != nil {
+				return err
 			}
+		}
+		return nil
+	}
+	if err := collect(p); err != nil {
+		return "", errors.Wrap(err, "collecting file checksums")
+	}
+	if len(shaList) == 0 {
+		return "", errors.New("unable to get verification code, package tree has no files")
+	}
+	return sha1VerificationCode(shaList)
+}
+
+// packageView is the data passed to the package template. It embeds
+// the package and overrides the fields computed at render time, so
+// that rendering does not modify the package.
+type packageView struct {
+	*Package
+	VerificationCode     string
+	LicenseInfoFromFiles []string
+	Comment              string
+	Checksum             map[string]string
+	ArchiveFileName      string
+}
+
+// FileProvider yields the files of a package on demand, so packages
+// with large files lists do not need to hold them in memory. It may be
+// called more than once per render and must yield the same files in the
+// same order every time.
+type FileProvider interface {
+	ForEachFile(fn func(*File) error) error
+}
+
+// forEachFile calls fn for each file of the package, in ID order when
+// read from the Files map or in the order yielded by the FileProvider
+func (p *Package) forEachFile(fn func(*File) error) error {
+	if p.FileProvider != nil {
+		return p.FileProvider.ForEachFile(fn)
+	}
+	for _, id := range sortedFileIDs(p.Files) {
+		if err := fn(p.Files[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			docFragment += pkgDoc
-			docFragment += fmt.Sprintf("Relationship: %s CONTAINS %s\n\n", p.ID, pkg.ID)
+// view returns the package data passed to the templates, computing
+// the fields derived from its files. The caller must hold the package
+// lock.
+func (p *Package) view(created time.Time) (*packageView, error) {
+	if p.ID == "" {
+		return nil, errors.New("unable to render package, it has no SPDX ID")
+	}
+	if p.Name == "" {
+		return nil, errors.Errorf("unable to render package %s, it has no name", p.ID)
+	}
+	// An empty download location means the package was not downloaded
+	// from anywhere, which SPDX expresses as NONE. Locations with spaces
+	// or line breaks would not be read back as a single value.
+	if strings.IndexFunc(p.DownloadLocation, unicode.IsSpace) >= 0 {
+		return nil, errors.Errorf("unable to render package %s, invalid download location %q", p.ID, p.DownloadLocation)
+	}
+	// The verification code and the license info from files are only
+	// rendered when files were analyzed, in which case they are computed
+	// from the files below. Values set in the package are ignored.
+	view := &packageView{Package: p, Checksum: p.renderedChecksums(), ArchiveFileName: p.ArchiveFileName}
+	// Packages that only set FileName still render it as before
+	if view.ArchiveFileName == "" {
+		view.ArchiveFileName = p.FileName
+	}
+	for _, line := range []string{p.provenanceComment(created), p.scopeComment(), p.Comment, p.DownloadLocationComment} {
+		if line == "" {
+			continue
+		}
+		if view.Comment != "" {
+			view.Comment += "\n"
 		}
+		view.Comment += line
 	}
 
-	// Print the contained dependencies
-	if p.Dependencies != nil {
-		for _, pkg := range p.Dependencies {
-			pkgDoc, err := pkg.Render()
+	if !p.FilesAnalyzed && (len(p.Files) > 0 || p.FileProvider != nil) {
+		return nil, errors.New("unable to render package, it has files but FilesAnalyzed is false")
+	}
+
+	// If files were analyzed, calculate the verification code and
+	// collect the license tags of the files to express them in the
+	// LicenseInfoFromFiles entry of the SPDX package:
+	if p.FilesAnalyzed {
+		// The checksums kept by AddFiles can be used unless the files
+		// were modified after adding them
+		incremental := p.FileProvider == nil && len(p.hashes.byID) == len(p.Files)
+		shaList := []string{}
+		filesTags := map[string]struct{}{}
+		if err := p.forEachFile(func(f *File) error {
+			sha, err := verificationFileSHA1(f)
 			if err != nil {
-				return "", errors.Wrap(err, "rendering pkg "+pkg.Name)
+				return err
+			}
+			shaList = append(shaList, sha)
+			incremental = incremental && p.hashes.matches(f.ID, sha)
+
+			// Collect the license tags
+			for _, l := range f.LicenseInfoInFile {
+				if l != "" && l != NONE && l != NOASSERTION {
+					filesTags[l] = struct{}{}
+				}
 			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		var code string
+		var err error
+		if incremental && len(shaList) > 0 {
+			code, err = p.hashes.verificationCode()
+		} else {
+			code, err = packageVerificationCode(shaList)
+		}
+		if err != nil {
+			return nil, err
+		}
+		view.VerificationCode = code
 
-			docFragment += pkgDoc
-			docFragment += fmt.Sprintf("Relationship: %s DEPENDS_ON %s\n\n", p.ID, pkg.ID)
+		// Sort the tags to get the same output on every run
+		view.LicenseInfoFromFiles = []string{}
+		for tag := range filesTags {
+			view.LicenseInfoFromFiles = append(view.LicenseInfoFromFiles, tag)
 		}
+		sort.Strings(view.LicenseInfoFromFiles)
+
+		// If no license tags where collected from files, then
+		// the BOM has to express "NONE" in the LicenseInfoFromFiles
+		// section to be compliant, or NOASSERTION if the options
+		// say the licenses were not determined:
+		if len(view.LicenseInfoFromFiles) == 0 {
+			sentinel := NONE
+			if p.Options() != nil && p.Options().UnknownFileLicensePolicy == UnknownFileLicenseNoAssertion {
+				sentinel = NOASSERTION
+			}
+			view.LicenseInfoFromFiles = append(view.LicenseInfoFromFiles, sentinel)
+		}
+	}
+	return view, nil
+}
+
+// renderState keeps track of the elements rendered while traversing
+// a tree of packages, so that packages reachable through more than one
+// path are only rendered once
+type renderState struct {
+	rendered map[string]struct{}
+
+	// When relationshipsAtEnd is set, relationships are collected
+	// to be rendered after all the element bodies
+	relationshipsAtEnd bool
+	relationships      string
+
+	// path holds the IDs of the packages being rendered, from the
+	// root down, its length is limited to maxDepth
+	path     []string
+	maxDepth int
+
+	// created is the creation time of the document being rendered,
+	// zero when rendering packages on their own
+	created time.Time
+
+	// out receives the rendered output, err holds the first error
+	// writing to it
+	out io.Writer
+	err error
+}
+
+// write writes s to the state output unless a previous write failed
+func (s *renderState) write(str string) {
+	if s.err != nil || str == "" {
+		return
+	}
+	if _, err := io.WriteString(s.out, str); err != nil {
+		s.err = errors.Wrap(err, "writing rendered output")
+	}
+}
+
+func newRenderState() *renderState {
+	return &renderState{
+		rendered: map[string]struct{}{},
+		maxDepth: defaultMaxDepth,
+	}
+}
+
+// relationship returns the rendered relationship rel to be added to the
+// output or, if relationships are rendered at the end, collects it and
+// returns an empty string
+func (s *renderState) relationship(rel string) string {
+	if !s.relationshipsAtEnd {
+		return rel
+	}
+	s.relationships += strings.TrimSuffix(rel, "\n")
+	return ""
+}
+
+// lineEnding returns the line terminator set in the package options
+func (p *Package) lineEnding() LineEnding {
+	if p.Options() == nil {
+		return LineEndingLF
+	}
+	return p.Options().LineEnding
+}
+
+// lineEndingWriter returns a writer converting the line feeds written
+// to it to the line ending, or w itself for the default line feeds
+func lineEndingWriter(w io.Writer, ending LineEnding) io.Writer {
+	if ending != LineEndingCRLF {
+		return w
 	}
-	return docFragment, nil
+	return &crlfWriter{w: w}
+}
+
+// crlfWriter writes to w with every line ending in CRLF. Rendered
+// fragments are written whole, so CRLF sequences already present in
+// them are not doubled.
+type crlfWriter struct {
+	w io.Writer
+}
+
+func (c *crlfWriter) Write(data []byte) (int, error) {
+	converted := bytes.ReplaceAll(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")), []byte("\n"), []byte("\r\n"))
+	if _, err := c.w.Write(converted); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// Render renders the document fragment of the package. Packages need
+// at least an ID and a name to be rendered, an empty DownloadLocation
+// is rendered as NONE and one with spaces is an error.
+func (p *Package) Render() (docFragment string, err error) {
+	var sb strings.Builder
+	if err := p.RenderTo(&sb); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// RenderTo writes the document fragment of the package to w as it is
+// rendered, so the output does not need to be held in memory
+func (p *Package) RenderTo(w io.Writer) error {
+	state := newRenderState()
+	state.relationshipsAtEnd = p.Options() != nil && p.Options().RelationshipsAtEnd
+	state.maxDepth = p.maxDepth()
+	state.out = lineEndingWriter(w, p.lineEnding())
+	if err := p.render(state); err != nil {
+		return err
+	}
+	if state.relationships != "" {
+		state.write(state.relationships + "\n")
+	}
+	return state.err
+}
+
+// RenderSelf renders the document fragment of the package alone: its
+// own body and, if the files were analyzed, the files it contains. The
+// subpackages, dependencies and other relationships are not rendered.
+func (p *Package) RenderSelf() (docFragment string, err error) {
+	var sb strings.Builder
+	state := newRenderState()
+	state.relationshipsAtEnd = p.Options() != nil && p.Options().RelationshipsAtEnd
+	state.out = lineEndingWriter(&sb, p.lineEnding())
+
+	p.RLock()
+	defer p.RUnlock()
+	if err := p.renderSelf(state); err != nil {
+		return "", err
+	}
+	if state.relationships != "" {
+		state.write(state.relationships + "\n")
+	}
+	if state.err != nil {
+		return "", state.err
+	}
+	return sb.String(), nil
+}
+
+// renderSelf writes the body of the package and its files to the state
+// output. The caller must hold the package lock.
+func (p *Package) renderSelf(state *renderState) error {
+	return p.walkSelf(state, &tagValueWriter{state: state})
+}
+
+// render writes the package and its subpackages and dependencies to the
+// state output, skipping any packages already rendered in the state
+func (p *Package) render(state *renderState) error {
+	return p.walk(state, &tagValueWriter{state: state})
 }
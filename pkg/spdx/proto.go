@@ -0,0 +1,370 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"github.com/pkg/errors"
+)
+
+// The Proto types are generated from spdx.proto in spdx.pb.go, the
+// functions in this file convert package trees to and from them.
+
+// ToProto converts the package and all the elements reachable from it
+// into a flat protobuf graph. Each package and file is listed once and
+// the relationships among them are recorded as edges.
+func (p *Package) ToProto() (*ProtoPackageGraph, error) {
+	g := &ProtoPackageGraph{RootId: p.ID}
+	seenPackages := map[string]struct{}{}
+	seenFiles := map[string]struct{}{}
+	// Packages related to files are added after the tree traversal
+	pending := []*Package{}
+
+	var addFile func(f *File) error
+	addFile = func(f *File) error {
+		if f.ID == "" {
+			return errors.Errorf("file %s has no ID", f.Name)
+		}
+		if _, ok := seenFiles[f.ID]; ok {
+			return nil
+		}
+		seenFiles[f.ID] = struct{}{}
+		g.Files = append(g.Files, protoFile(f))
+		for _, r := range f.Relationships {
+			g.Edges = append(g.Edges, &ProtoEdge{SourceId: f.ID, Type: r.Type, TargetId: r.peerID()})
+			if r.Package != nil {
+				pending = append(pending, r.Package)
+			} else if err := addFile(r.File); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var addPackage func(pkg *Package) error
+	addPackage = func(pkg *Package) error {
+		if pkg.ID == "" {
+			return errors.Errorf("package %s has no ID", pkg.Name)
+		}
+		if _, ok := seenPackages[pkg.ID]; ok {
+			return nil
+		}
+		seenPackages[pkg.ID] = struct{}{}
+
+		pkg.RLock()
+		defer pkg.RUnlock()
+		g.Packages = append(g.Packages, protoPackage(pkg))
+		peers := []*Package{}
+		if err := pkg.forEachFile(func(f *File) error {
+			g.Edges = append(g.Edges, &ProtoEdge{SourceId: pkg.ID, Type: RelationshipContains, TargetId: f.ID})
+			return addFile(f)
+		}); err != nil {
+			return err
+		}
+		for _, id := range sortedPackageIDs(pkg.Packages) {
+			g.Edges = append(g.Edges, &ProtoEdge{SourceId: pkg.ID, Type: RelationshipContains, TargetId: id})
+			peers = append(peers, pkg.Packages[id])
+		}
+		for _, id := range sortedPackageIDs(pkg.Dependencies) {
+			g.Edges = append(g.Edges, &ProtoEdge{SourceId: pkg.ID, Type: RelationshipDependsOn, TargetId: id})
+			peers = append(peers, pkg.Dependencies[id])
+		}
+		for _, r := range pkg.Relationships {
+			g.Edges = append(g.Edges, &ProtoEdge{SourceId: pkg.ID, Type: r.Type, TargetId: r.peerID()})
+			if r.Package != nil {
+				peers = append(peers, r.Package)
+			} else if err := addFile(r.File); err != nil {
+				return err
+			}
+		}
+		for _, peer := range peers {
+			if err := addPackage(peer); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := addPackage(p); err != nil {
+		return nil, errors.Wrap(err, "converting package to protobuf")
+	}
+	for len(pending) > 0 {
+		pkg := pending[0]
+		pending = pending[1:]
+		if err := addPackage(pkg); err != nil {
+			return nil, errors.Wrap(err, "converting package to protobuf")
+		}
+	}
+	return g, nil
+}
+
+// FromProto rebuilds a package tree from its protobuf graph and
+// returns the root package
+func FromProto(g *ProtoPackageGraph) (*Package, error) {
+	packages := map[string]*Package{}
+	files := map[string]*File{}
+	for _, pp := range g.Packages {
+		if _, ok := packages[pp.Id]; ok {
+			return nil, errors.Errorf("duplicate package %s in protobuf graph", pp.Id)
+		}
+		packages[pp.Id] = packageFromProto(pp)
+	}
+	for _, pf := range g.Files {
+		if _, ok := files[pf.Id]; ok {
+			return nil, errors.Errorf("duplicate file %s in protobuf graph", pf.Id)
+		}
+		files[pf.Id] = fileFromProto(pf)
+	}
+
+	for _, e := range g.Edges {
+		if err := addProtoEdge(packages, files, e); err != nil {
+			return nil, errors.Wrapf(
+				err, "adding %s relationship from %s to %s", e.Type, e.SourceId, e.TargetId,
+			)
+		}
+	}
+
+	root, ok := packages[g.RootId]
+	if !ok {
+		return nil, errors.Errorf("root package %s not found in protobuf graph", g.RootId)
+	}
+	return root, nil
+}
+
+// addProtoEdge links the elements of a graph edge
+func addProtoEdge(packages map[string]*Package, files map[string]*File, e *ProtoEdge) error {
+	r := &Relationship{Type: e.Type, Package: packages[e.TargetId], File: files[e.TargetId]}
+	if err := r.validate(); err != nil {
+		return err
+	}
+
+	if f, ok := files[e.SourceId]; ok {
+		return f.AddRelationship(r)
+	}
+	pkg, ok := packages[e.SourceId]
+	if !ok {
+		return errors.New("source element not found")
+	}
+	// The files of packages encoded with a file provider are yielded by
+	// a new one instead of being added to the package
+	provider, ok := pkg.FileProvider.(*protoFileProvider)
+	if ok && e.Type == RelationshipContains && r.File != nil {
+		provider.files = append(provider.files, r.File)
+		return nil
+	}
+	switch {
+	case e.Type == RelationshipContains && r.File != nil:
+		return pkg.AddFile(r.File)
+	case e.Type == RelationshipContains:
+		return pkg.AddPackage(r.Package)
+	case e.Type == RelationshipDependsOn && r.Package != nil:
+		return pkg.AddDependency(r.Package)
+	}
+	return pkg.AddRelationship(r)
+}
+
+// protoFileProvider yields the files of a package decoded from a
+// protobuf graph, in the order they were encoded
+type protoFileProvider struct {
+	files []*File
+}
+
+func (fp *protoFileProvider) ForEachFile(fn func(*File) error) error {
+	for _, f := range fp.files {
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func protoPackage(p *Package) *ProtoPackage {
+	pp := &ProtoPackage{
+		Id:                      p.ID,
+		Name:                    p.Name,
+		Version:                 p.Version,
+		FileName:                p.FileName,
+		ArchiveFileName:         p.ArchiveFileName,
+		SourceFile:              p.SourceFile,
+		Scope:                   string(p.Scope),
+		DownloadLocation:        p.DownloadLocation,
+		DownloadLocationComment: p.DownloadLocationComment,
+		HomePage:                p.HomePage,
+		FilesAnalyzed:           p.FilesAnalyzed,
+		VerificationCode:        p.VerificationCode,
+		LicenseConcluded:        p.LicenseConcluded,
+		LicenseInfoFromFiles:    p.LicenseInfoFromFiles,
+		LicenseDeclared:         p.LicenseDeclared,
+		LicenseComments:         p.LicenseComments,
+		CopyrightText:           p.CopyrightText,
+		Summary:                 p.Summary,
+		Description:             p.Description,
+		Comment:                 p.Comment,
+		SourceInfo:              p.SourceInfo,
+		AttributionText:         p.AttributionText,
+		SupplierPerson:          p.Supplier.Person,
+		SupplierOrganization:    p.Supplier.Organization,
+		OriginatorPerson:        p.Originator.Person,
+		OriginatorOrganization:  p.Originator.Organization,
+		Checksums:               protoChecksums(p.Checksum),
+		IsPrimary:               p.IsPrimary,
+		FileProvider:            p.FileProvider != nil,
+	}
+	for _, ref := range p.ExternalRefs {
+		pp.ExternalRefs = append(pp.ExternalRefs, &ProtoExternalRef{
+			Category: ref.Category, Type: ref.Type, Locator: ref.Locator,
+		})
+	}
+	return pp
+}
+
+func packageFromProto(pp *ProtoPackage) *Package {
+	p := NewPackage()
+	p.ID = pp.Id
+	p.Name = pp.Name
+	p.Version = pp.Version
+	p.FileName = pp.FileName
+	p.ArchiveFileName = pp.ArchiveFileName
+	p.SourceFile = pp.SourceFile
+	p.Scope = DependencyScope(pp.Scope)
+	p.HomePage = pp.HomePage
+	p.DownloadLocation = pp.DownloadLocation
+	p.DownloadLocationComment = pp.DownloadLocationComment
+	p.FilesAnalyzed = pp.FilesAnalyzed
+	p.VerificationCode = pp.VerificationCode
+	p.LicenseConcluded = pp.LicenseConcluded
+	p.LicenseInfoFromFiles = pp.LicenseInfoFromFiles
+	p.LicenseDeclared = pp.LicenseDeclared
+	p.LicenseComments = pp.LicenseComments
+	p.CopyrightText = pp.CopyrightText
+	p.Summary = pp.Summary
+	p.Description = pp.Description
+	p.Comment = pp.Comment
+	p.SourceInfo = pp.SourceInfo
+	p.AttributionText = pp.AttributionText
+	p.Supplier.Person = pp.SupplierPerson
+	p.Supplier.Organization = pp.SupplierOrganization
+	p.Originator.Person = pp.OriginatorPerson
+	p.Originator.Organization = pp.OriginatorOrganization
+	p.Checksum = checksumsFromProto(pp.Checksums)
+	p.IsPrimary = pp.IsPrimary
+	if pp.FileProvider {
+		p.FileProvider = &protoFileProvider{}
+	}
+	for _, ref := range pp.ExternalRefs {
+		p.ExternalRefs = append(p.ExternalRefs, ExternalRef{
+			Category: ref.Category, Type: ref.Type, Locator: ref.Locator,
+		})
+	}
+	return p
+}
+
+func protoFile(f *File) *ProtoFile {
+	pf := &ProtoFile{
+		Id:                f.ID,
+		Name:              f.Name,
+		FileName:          f.FileName,
+		SourceFile:        f.SourceFile,
+		FileType:          f.FileType,
+		LicenseConcluded:  f.LicenseConcluded,
+		LicenseInfoInFile: f.LicenseInfoInFile,
+		CopyrightText:     f.CopyrightText,
+		Comment:           f.Comment,
+		Size:              f.Size,
+		Lines:             int64(f.Lines),
+		AttributionText:   f.AttributionText,
+		Notice:            f.Notice,
+		Checksums:         protoChecksums(f.Checksum),
+		GitBlobSha1:       f.GitBlobSHA1,
+	}
+	for _, s := range f.Snippets {
+		pf.Snippets = append(pf.Snippets, &ProtoSnippet{
+			Id:                   s.ID,
+			SnippetFromFile:      s.SnippetFromFile,
+			ByteRange:            protoSnippetRange(s.ByteRange),
+			LineRange:            protoSnippetRange(s.LineRange),
+			LicenseConcluded:     s.LicenseConcluded,
+			LicenseInfoInSnippet: s.LicenseInfoInSnippet,
+			CopyrightText:        s.CopyrightText,
+			Comment:              s.Comment,
+		})
+	}
+	return pf
+}
+
+func fileFromProto(pf *ProtoFile) *File {
+	f := NewFile()
+	f.ID = pf.Id
+	f.Name = pf.Name
+	f.FileName = pf.FileName
+	f.SourceFile = pf.SourceFile
+	f.FileType = pf.FileType
+	f.LicenseConcluded = pf.LicenseConcluded
+	f.LicenseInfoInFile = pf.LicenseInfoInFile
+	f.CopyrightText = pf.CopyrightText
+	f.Comment = pf.Comment
+	f.Size = pf.Size
+	f.Lines = int(pf.Lines)
+	f.AttributionText = pf.AttributionText
+	f.Notice = pf.Notice
+	f.Checksum = checksumsFromProto(pf.Checksums)
+	f.GitBlobSHA1 = pf.GitBlobSha1
+	for _, ps := range pf.Snippets {
+		f.Snippets = append(f.Snippets, &Snippet{
+			ID:                   ps.Id,
+			SnippetFromFile:      ps.SnippetFromFile,
+			ByteRange:            snippetRangeFromProto(ps.ByteRange),
+			LineRange:            snippetRangeFromProto(ps.LineRange),
+			LicenseConcluded:     ps.LicenseConcluded,
+			LicenseInfoInSnippet: ps.LicenseInfoInSnippet,
+			CopyrightText:        ps.CopyrightText,
+			Comment:              ps.Comment,
+		})
+	}
+	return f
+}
+
+// protoSnippetRange returns nil for ranges that are not set
+func protoSnippetRange(r SnippetRange) *ProtoSnippetRange {
+	if r.IsZero() {
+		return nil
+	}
+	return &ProtoSnippetRange{Start: int64(r.Start), End: int64(r.End)}
+}
+
+func snippetRangeFromProto(r *ProtoSnippetRange) SnippetRange {
+	return SnippetRange{Start: int(r.GetStart()), End: int(r.GetEnd())}
+}
+
+// protoChecksums returns the checksums in the rendering order
+func protoChecksums(checksums map[string]string) []*ProtoChecksum {
+	var list []*ProtoChecksum
+	for _, algorithm := range sortedChecksumAlgorithms(checksums) {
+		list = append(list, &ProtoChecksum{Algorithm: algorithm, Value: checksums[algorithm]})
+	}
+	return list
+}
+
+func checksumsFromProto(list []*ProtoChecksum) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	checksums := map[string]string{}
+	for _, c := range list {
+		checksums[c.Algorithm] = c.Value
+	}
+	return checksums
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProtoRoundTrip(t *testing.T) {
+	root := testPackageWithFiles(t, "MIT", "Apache-2.0")
+	root.Version = "1.0"
+	root.LicenseDeclared = "MIT"
+	root.Supplier.Organization = "Kubernetes"
+	root.Originator.Person = "Jane Doe"
+	root.Summary = "Test package"
+	root.Description = "A package to test the protobuf conversion"
+	root.Comment = "Built for the tests"
+	root.SourceInfo = "built from the test fixtures"
+	root.AttributionText = []string{"Copyright The Kubernetes Authors"}
+	root.DownloadLocation = "https://example.com/test-package-1.0.tar.gz"
+	root.DownloadLocationComment = "Download the release tarball"
+	root.ArchiveFileName = "test-package-1.0.tar.gz"
+	root.SourceFile = "/tmp/test-package-1.0.tar.gz"
+	root.HomePage = "https://example.com"
+	root.IsPrimary = true
+	root.AddPackageURL("pkg:generic/test-package@1.0")
+	require.Nil(t, root.AddChecksum("SHA1", "da39a3ee5e6b4b0d3255bfef95601890afd80709"))
+
+	f := root.Files[sortedFileIDs(root.Files)[0]]
+	f.FileName = "file0.txt"
+	f.SourceFile = "/tmp/file0.txt"
+	f.FileType = []string{"TEXT"}
+	f.Comment = "The first file"
+	f.Size = 1024
+	f.Lines = 32
+	f.AttributionText = []string{"Includes code by Jane Doe"}
+	f.Notice = "Licensed under the MIT license"
+	f.GitBlobSHA1 = "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391"
+	f.Snippets = []*Snippet{{
+		ID:                   "SPDXRef-Snippet-vendored",
+		SnippetFromFile:      f.ID,
+		ByteRange:            SnippetRange{Start: 1, End: 100},
+		LineRange:            SnippetRange{Start: 1, End: 4},
+		LicenseConcluded:     "BSD-3-Clause",
+		LicenseInfoInSnippet: []string{"BSD-3-Clause"},
+		CopyrightText:        "Copyright Jane Doe",
+		Comment:              "Vendored code",
+	}, {
+		ID:              "SPDXRef-Snippet-no-lines",
+		SnippetFromFile: f.ID,
+		ByteRange:       SnippetRange{Start: 101, End: 200},
+	}}
+
+	sub := NewPackage()
+	sub.Name = "sub"
+	dep := NewPackage()
+	dep.Name = "dep"
+	dep.Scope = ScopeTest
+	src := NewPackage()
+	src.Name = "src"
+	src.ID = "SPDXRef-Package-src"
+	require.Nil(t, root.AddPackage(sub))
+	require.Nil(t, sub.AddDependency(dep))
+	require.Nil(t, root.MarkGeneratedFrom(src))
+	// Cycle back to the root
+	require.Nil(t, dep.AddDependency(root))
+
+	// Files read from a provider are not held by the package
+	lazy := NewPackage()
+	lazy.ID = "SPDXRef-Package-lazy"
+	lazy.Name = "lazy"
+	lazy.FilesAnalyzed = true
+	lazy.FileProvider = &testFileProvider{count: 3}
+	require.Nil(t, root.AddPackage(lazy))
+
+	graph, err := root.ToProto()
+	require.Nil(t, err)
+	require.Len(t, graph.Packages, 5)
+	require.Len(t, graph.Files, 5)
+
+	data, err := proto.Marshal(graph)
+	require.Nil(t, err)
+	decoded := &ProtoPackageGraph{}
+	require.Nil(t, proto.Unmarshal(data, decoded))
+	require.True(t, proto.Equal(graph, decoded))
+
+	rebuilt, err := FromProto(decoded)
+	require.Nil(t, err)
+	require.True(t, root.Equal(rebuilt))
+	require.Len(t, rebuilt.Relationships, 1)
+	require.Equal(t, "SPDXRef-Package-src", rebuilt.Relationships[0].Package.ID)
+
+	// The cycle is preserved with the same package instance
+	rebuiltDep := rebuilt.Packages["SPDXRef-Package-sub"].Dependencies["SPDXRef-Package-dep"]
+	require.Equal(t, rebuilt, rebuiltDep.Dependencies["SPDXRef-Package-test-package"])
+
+	// Provider files are yielded by a provider in the rebuilt package
+	rebuiltLazy := rebuilt.Packages["SPDXRef-Package-lazy"]
+	require.Empty(t, rebuiltLazy.Files)
+	require.NotNil(t, rebuiltLazy.FileProvider)
+	ids := []string{}
+	require.Nil(t, rebuiltLazy.FileProvider.ForEachFile(func(f *File) error {
+		ids = append(ids, f.ID)
+		return nil
+	}))
+	require.Equal(t, []string{"SPDXRef-File-00000", "SPDXRef-File-00001", "SPDXRef-File-00002"}, ids)
+	expected, err := lazy.Render()
+	require.Nil(t, err)
+	actual, err := rebuiltLazy.Render()
+	require.Nil(t, err)
+	require.Equal(t, expected, actual)
+
+	// Data lost in the conversion makes the packages differ
+	rebuilt.Files[f.ID].Snippets[0].LineRange = SnippetRange{}
+	require.False(t, root.Equal(rebuilt))
+
+	// Broken data fails to decode
+	require.NotNil(t, proto.Unmarshal([]byte{0x12, 0xff}, decoded))
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// buildPackageURL assembles a package URL (purl) string from its
+// components as defined in https://github.com/package-url/purl-spec
+// Empty components are omitted from the result. Each segment of the
+// namespace is escaped on its own so its slashes are kept.
+func buildPackageURL(
+	purlType, namespace, name, version string, qualifiers map[string]string,
+) string {
+	purl := "pkg:" + purlType + "/"
+	if namespace != "" {
+		segments := strings.Split(namespace, "/")
+		for i := range segments {
+			segments[i] = escapePurlSegment(segments[i])
+		}
+		purl += strings.Join(segments, "/") + "/"
+	}
+	purl += escapePurlSegment(name)
+	if version != "" {
+		purl += "@" + escapePurlSegment(version)
+	}
+
+	// Qualifiers are sorted by key as mandated by the spec
+	keys := []string{}
+	for k, v := range qualifiers {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	pairs := []string{}
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+url.QueryEscape(qualifiers[k]))
+	}
+	if len(pairs) > 0 {
+		purl += "?" + strings.Join(pairs, "&")
+	}
+	return purl
+}
+
+// escapePurlSegment percent-encodes a purl path segment. The @ is
+// valid in URL paths but separates the version in purls, so it is
+// encoded as well.
+func escapePurlSegment(segment string) string {
+	return strings.ReplaceAll(url.PathEscape(segment), "@", "%40")
+}
+
+// coordinatesPackageURL returns the purl of version of name in the
+// ecosystem. Go modules and scoped npm packages get their namespace
+// split from the name.
+func coordinatesPackageURL(ecosystem, name, version string) string {
+	switch ecosystem {
+	case "golang":
+		return goPackageURL(name, version)
+	case "npm":
+		return npmPackageURL(name, version)
+	}
+	return buildPackageURL(ecosystem, "", name, version, nil)
+}
+
+// parsePackageURL splits a purl into its type, namespace, name and
+// version. Qualifiers and subpaths are discarded.
+func parsePackageURL(purl string) (purlType, namespace, name, version string, ok bool) {
+	rest := strings.TrimPrefix(purl, "pkg:")
+	if rest == purl {
+		return "", "", "", "", false
+	}
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+	i := strings.Index(rest, "/")
+	if i <= 0 {
+		return "", "", "", "", false
+	}
+	purlType, rest = strings.ToLower(rest[:i]), strings.Trim(rest[i+1:], "/")
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		version, rest = rest[i+1:], rest[:i]
+	}
+	if i := strings.LastIndex(rest, "/"); i >= 0 {
+		namespace, rest = rest[:i], rest[i+1:]
+	}
+	name = rest
+
+	var err error
+	if namespace, err = url.PathUnescape(namespace); err != nil {
+		return "", "", "", "", false
+	}
+	if name, err = url.PathUnescape(name); err != nil || name == "" {
+		return "", "", "", "", false
+	}
+	if version, err = url.PathUnescape(version); err != nil {
+		return "", "", "", "", false
+	}
+	return purlType, namespace, name, version, true
+}
+
+// cpeVendorHosts are the code hosts whose go module paths are mapped
+// to CPE vendors, the first path element after the host is the vendor
+var cpeVendorHosts = map[string]struct{}{
+	"github.com": {}, "gitlab.com": {}, "bitbucket.org": {},
+}
+
+// cpeDistroPurls maps the CPE vendors of linux distributions to the
+// type and namespace of their package purls
+var cpeDistroPurls = map[string][2]string{
+	"debian": {"deb", "debian"},
+	"ubuntu": {"deb", "ubuntu"},
+	"redhat": {"rpm", "redhat"},
+	"fedora": {"rpm", "fedora"},
+	"centos": {"rpm", "centos"},
+	"suse":   {"rpm", "opensuse"},
+}
+
+// PurlToCPE guesses the CPE 2.3 name of the package identified by purl.
+// Only the deb, rpm, npm, pypi and golang types are mapped. The purl
+// namespace of distro packages is the distro, not the vendor of the
+// software, so their vendor is left as a wildcard. It returns false
+// when there is no confident product for the package.
+func PurlToCPE(purl string) (string, bool) {
+	purlType, namespace, name, version, ok := parsePackageURL(purl)
+	if !ok {
+		return "", false
+	}
+	var vendor, product string
+	switch purlType {
+	case "deb", "rpm":
+		vendor, product = "*", name
+	case "npm":
+		vendor, product = strings.TrimPrefix(namespace, "@"), name
+		if vendor == "" {
+			vendor = name
+		}
+	case "pypi":
+		vendor, product = name, name
+	case "golang":
+		parts := strings.Split(namespace, "/")
+		if len(parts) != 2 {
+			return "", false
+		}
+		if _, ok := cpeVendorHosts[parts[0]]; !ok {
+			return "", false
+		}
+		vendor, product = parts[1], name
+	default:
+		return "", false
+	}
+	if vendor == "" || product == "" {
+		return "", false
+	}
+	if version == "" {
+		version = "*"
+	} else {
+		version = cpeEscape(version)
+	}
+	if vendor != "*" {
+		vendor = cpeEscape(vendor)
+	}
+	return "cpe:2.3:a:" + vendor + ":" + cpeEscape(product) + ":" +
+		version + ":*:*:*:*:*:*:*", true
+}
+
+// CPEToPurl guesses the purl of the package named by a CPE 2.3 or 2.2
+// name. Only packages from the linux distributions in cpeDistroPurls
+// can be mapped, other vendors return false.
+func CPEToPurl(cpe string) (string, bool) {
+	var fields []string
+	switch {
+	case strings.HasPrefix(cpe, "cpe:2.3:"):
+		fields = splitCPE(strings.TrimPrefix(cpe, "cpe:2.3:"))
+	case strings.HasPrefix(cpe, "cpe:/"):
+		fields = strings.Split(strings.TrimPrefix(cpe, "cpe:/"), ":")
+	default:
+		return "", false
+	}
+	if len(fields) < 3 || fields[0] != "a" {
+		return "", false
+	}
+	distro, ok := cpeDistroPurls[strings.ToLower(fields[1])]
+	if !ok {
+		return "", false
+	}
+	product := cpeUnescape(fields[2])
+	if product == "" || product == "*" || product == "-" {
+		return "", false
+	}
+	version := ""
+	if len(fields) > 3 && fields[3] != "*" && fields[3] != "-" {
+		version = cpeUnescape(fields[3])
+	}
+	return buildPackageURL(distro[0], distro[1], product, version, nil), true
+}
+
+// cpeEscape lowercases s and quotes the characters that are special in
+// a CPE 2.3 formatted string
+func cpeEscape(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '_' && r != '-' && r != '.' {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// cpeUnescape removes the quoting added by cpeEscape
+func cpeUnescape(s string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range s {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// splitCPE splits the fields of a CPE 2.3 formatted string, honoring
+// escaped colons
+func splitCPE(s string) []string {
+	fields := []string{}
+	start, escaped := 0, false
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			fields = append(fields, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(fields, s[start:])
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurlToCPE(t *testing.T) {
+	for _, tc := range []struct {
+		purl string
+		cpe  string
+		ok   bool
+	}{
+		{"pkg:deb/debian/openssl@1.1", "cpe:2.3:a:*:openssl:1.1:*:*:*:*:*:*:*", true},
+		{"pkg:deb/debian/openssl@1.1.1n-0%2Bdeb11u3?arch=amd64", "cpe:2.3:a:*:openssl:1.1.1n-0\\+deb11u3:*:*:*:*:*:*:*", true},
+		{"pkg:rpm/fedora/curl@7.50.3-1.fc25", "cpe:2.3:a:*:curl:7.50.3-1.fc25:*:*:*:*:*:*:*", true},
+		{"pkg:rpm/curl@7.50.3", "cpe:2.3:a:*:curl:7.50.3:*:*:*:*:*:*:*", true},
+		{"pkg:npm/%40angular/core@12.0.0", "cpe:2.3:a:angular:core:12.0.0:*:*:*:*:*:*:*", true},
+		{"pkg:npm/lodash@4.17.21", "cpe:2.3:a:lodash:lodash:4.17.21:*:*:*:*:*:*:*", true},
+		{"pkg:pypi/Django", "cpe:2.3:a:django:django:*:*:*:*:*:*:*:*", true},
+		{"pkg:golang/github.com/spf13/cobra@v1.1.3", "cpe:2.3:a:spf13:cobra:v1.1.3:*:*:*:*:*:*:*", true},
+		{"pkg:golang/k8s.io/release@v0.7.0", "", false},
+		{"pkg:maven/org.apache/commons@1.0", "", false},
+		{"not-a-purl", "", false},
+	} {
+		cpe, ok := PurlToCPE(tc.purl)
+		require.Equal(t, tc.ok, ok, tc.purl)
+		require.Equal(t, tc.cpe, cpe, tc.purl)
+	}
+}
+
+func TestCPEToPurl(t *testing.T) {
+	for _, tc := range []struct {
+		cpe  string
+		purl string
+		ok   bool
+	}{
+		{"cpe:2.3:a:debian:openssl:1.1:*:*:*:*:*:*:*", "pkg:deb/debian/openssl@1.1", true},
+		{"cpe:2.3:a:ubuntu:bash:5.0\\:1:*:*:*:*:*:*:*", "pkg:deb/ubuntu/bash@5.0:1", true},
+		{"cpe:/a:redhat:curl:7.29.0", "pkg:rpm/redhat/curl@7.29.0", true},
+		{"cpe:2.3:a:fedora:curl:*:*:*:*:*:*:*:*", "pkg:rpm/fedora/curl", true},
+		{"cpe:2.3:a:apache:http_server:2.4:*:*:*:*:*:*:*", "", false},
+		{"cpe:2.3:o:debian:debian_linux:11:*:*:*:*:*:*:*", "", false},
+	} {
+		purl, ok := CPEToPurl(tc.cpe)
+		require.Equal(t, tc.ok, ok, tc.cpe)
+		require.Equal(t, tc.purl, purl, tc.cpe)
+	}
+}
+
+func TestAddPackageURLDeriveCPEs(t *testing.T) {
+	pkg := NewPackage()
+	pkg.AddPackageURL("pkg:deb/debian/openssl@1.1")
+	require.Len(t, pkg.ExternalRefs, 1)
+
+	pkg = NewPackage()
+	pkg.Options().DeriveCPEs = true
+	pkg.AddPackageURL("pkg:deb/debian/openssl@1.1")
+	pkg.AddPackageURL("pkg:maven/org.apache/commons@1.0")
+	require.Equal(t, []ExternalRef{
+		{Category: "PACKAGE-MANAGER", Type: "purl", Locator: "pkg:deb/debian/openssl@1.1"},
+		{Category: "SECURITY", Type: "cpe23Type", Locator: "cpe:2.3:a:*:openssl:1.1:*:*:*:*:*:*:*"},
+		{Category: "PACKAGE-MANAGER", Type: "purl", Locator: "pkg:maven/org.apache/commons@1.0"},
+	}, pkg.ExternalRefs)
+}
+
+func TestBuildPackageURL(t *testing.T) {
+	for _, tc := range []struct {
+		purlType, namespace, name, version string
+		qualifiers                         map[string]string
+		expected                           string
+	}{
+		{"golang", "github.com/spf13", "cobra", "v1.1.3", nil, "pkg:golang/github.com/spf13/cobra@v1.1.3"},
+		{"golang", "", "rsc.io", "v1.0.0", nil, "pkg:golang/rsc.io@v1.0.0"},
+		{"npm", "@babel", "core", "7.22.9", nil, "pkg:npm/%40babel/core@7.22.9"},
+		{"npm", "", "a@b", "1.0 beta", nil, "pkg:npm/a%40b@1.0%20beta"},
+		{
+			"deb", "debian", "openssl", "1.1.1n-0+deb11u3",
+			map[string]string{"distro": "", "arch": "amd64"},
+			"pkg:deb/debian/openssl@1.1.1n-0+deb11u3?arch=amd64",
+		},
+	} {
+		purl := buildPackageURL(tc.purlType, tc.namespace, tc.name, tc.version, tc.qualifiers)
+		require.Equal(t, tc.expected, purl)
+
+		purlType, namespace, name, version, ok := parsePackageURL(purl)
+		require.True(t, ok, purl)
+		require.Equal(t, tc.purlType, purlType, purl)
+		require.Equal(t, tc.namespace, namespace, purl)
+		require.Equal(t, tc.name, name, purl)
+		require.Equal(t, tc.version, version, purl)
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	pythonWheelMetadata  = "METADATA"
+	pythonSdistMetadata  = "PKG-INFO"
+	pythonDistInfoSuffix = ".dist-info"
+	pythonMaxMetadata    = 16 * 1024 * 1024
+	pypiNameSeparatorsRe = `[-_.]+`
+)
+
+// FromPythonDist builds a SPDX package from a python distribution, either
+// a wheel (.whl) or a source distribution (.tar.gz or .zip). The package
+// data is read from the METADATA file of wheels and the PKG-INFO file of
+// source distributions.
+func FromPythonDist(path string) (*Package, error) {
+	metadata, err := readPythonMetadata(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading python package metadata")
+	}
+
+	if metadata["Name"] == "" {
+		return nil, errors.New("python package metadata does not define a package name")
+	}
+
+	pkg := NewPackage()
+	pkg.Options().WorkDir = filepath.Dir(path)
+	if err := pkg.ReadSourceFile(path); err != nil {
+		return nil, errors.Wrap(err, "reading python package source file")
+	}
+	pkg.Name = metadata["Name"]
+	pkg.Version = metadata["Version"]
+	pkg.Summary = metadata["Summary"]
+	pkg.LicenseDeclared = pythonLicense(metadata)
+	pkg.Supplier.Person = pythonAuthor(metadata["Author"], metadata["Author-email"])
+	pkg.HomePage = metadata["Home-page"]
+	// The home page is not where the package was downloaded from, without
+	// a Download-URL the location is left unset
+	pkg.SetDownloadLocation(metadata["Download-URL"])
+	pkg.SourceInfo = "acquired package info from the metadata of " + filepath.Base(path)
+	pkg.AddPackageURL(buildPackageURL(
+		"pypi", "", pypiNormalizedName(pkg.Name), pkg.Version, nil,
+	))
+	return pkg, nil
+}
+
+// pythonLicense returns the declared license of a python package as a
+// SPDX expression. Metadata 2.4 adds the License-Expression field, older
+// packages only have the free text License field. Licenses that are not
+// valid expressions after replacing deprecated identifiers are returned
+// as NOASSERTION.
+func pythonLicense(metadata map[string]string) string {
+	expr := strings.TrimSpace(metadata["License-Expression"])
+	if expr == "" {
+		expr = strings.TrimSpace(metadata["License"])
+	}
+	if expr == "" {
+		return ""
+	}
+	expr, _ = NormalizeLicense(expr)
+	if err := ValidateLicenseExpression(expr); err != nil {
+		return NOASSERTION
+	}
+	return expr
+}
+
+// readPythonMetadata finds the metadata file in a python distribution
+// archive and returns its fields in a map
+func readPythonMetadata(path string) (map[string]string, error) {
+	switch {
+	case strings.HasSuffix(path, ".whl"):
+		return readPythonMetadataFromZip(path, func(name string) bool {
+			dir, file := splitPythonMember(name)
+			return strings.HasSuffix(dir, pythonDistInfoSuffix) && file == pythonWheelMetadata
+		})
+	case strings.HasSuffix(path, ".zip"):
+		return readPythonMetadataFromZip(path, isSdistMetadata)
+	case strings.HasSuffix(path, ".tar.gz"), strings.HasSuffix(path, ".tgz"):
+		return readPythonMetadataFromTarball(path)
+	}
+	return nil, errors.Errorf("unsupported python distribution format: %s", filepath.Base(path))
+}
+
+// splitPythonMember splits the name of an archive member in its top
+// level directory and the rest of the path. Both are empty if the
+// member is not exactly one level deep.
+func splitPythonMember(name string) (dir, file string) {
+	parts := strings.Split(path.Clean(name), "/")
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+// isSdistMetadata returns true if name is the PKG-INFO file at the
+// top directory of a source distribution. Other PKG-INFO files, such
+// as the one in the egg-info directory, are ignored.
+func isSdistMetadata(name string) bool {
+	dir, file := splitPythonMember(name)
+	return dir != "" && file == pythonSdistMetadata
+}
+
+func readPythonMetadataFromZip(path string, match func(string) bool) (map[string]string, error) {
+	z, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening python distribution")
+	}
+	defer z.Close()
+	for _, f := range z.File {
+		if !match(f.Name) {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, errors.Wrapf(err, "opening %s", f.Name)
+		}
+		defer r.Close()
+		return parsePythonMetadata(io.LimitReader(r, pythonMaxMetadata))
+	}
+	return nil, errors.New("metadata file not found in python distribution")
+}
+
+func readPythonMetadataFromTarball(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening python distribution")
+	}
+	defer f.Close()
+	gzf, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating gzip reader")
+	}
+	defer gzf.Close()
+
+	tr := tar.NewReader(gzf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.New("metadata file not found in python distribution")
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "reading python distribution")
+		}
+		if !isSdistMetadata(hdr.Name) {
+			continue
+		}
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, io.LimitReader(tr, pythonMaxMetadata)); err != nil {
+			return nil, errors.Wrap(err, "extracting metadata file")
+		}
+		return parsePythonMetadata(&buf)
+	}
+}
+
+// parsePythonMetadata reads the headers of a python core metadata file.
+// The headers end at the first empty line, the rest of the file is the
+// package description. Only the first value of repeated fields, like
+// Classifier, and the first line of multiline fields are kept.
+func parsePythonMetadata(r io.Reader) (map[string]string, error) {
+	fields := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+		// Continuation lines start with whitespace
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		// Old tools write UNKNOWN for fields without a value
+		if _, ok := fields[key]; ok || value == "UNKNOWN" {
+			continue
+		}
+		fields[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading metadata file")
+	}
+	return fields, nil
+}
+
+// pythonAuthor combines the Author and Author-email metadata fields in
+// the SPDX "Name (email)" form. Author-email can also hold the name in
+// the "Name <email>" form.
+func pythonAuthor(author, email string) string {
+	if email == "" {
+		return author
+	}
+	email = formatMaintainer(email)
+	if author == "" || strings.HasPrefix(email, author+" (") {
+		return email
+	}
+	return author + " (" + email + ")"
+}
+
+// pypiNormalizedName returns the name of a python package normalized as
+// required by the pypi purl type: lowercase with runs of dashes,
+// underscores and dots replaced by a dash
+func pypiNormalizedName(name string) string {
+	return regexp.MustCompile(pypiNameSeparatorsRe).ReplaceAllString(strings.ToLower(name), "-")
+}
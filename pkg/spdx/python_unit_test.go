@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromPythonDist(t *testing.T) {
+	_, err := FromPythonDist("testdata/non-existent.whl")
+	require.NotNil(t, err)
+	_, err = FromPythonDist("testdata/hello_1.0.0-1_amd64.deb")
+	require.NotNil(t, err)
+
+	pkg, err := FromPythonDist("testdata/hello_py-1.0.0-py3-none-any.whl")
+	require.Nil(t, err)
+	require.Equal(t, "hello_py", pkg.Name)
+	require.Equal(t, "1.0.0", pkg.Version)
+	require.Equal(t, "Apache-2.0", pkg.LicenseDeclared)
+	require.Equal(t, "Jane Doe (jane@example.com)", pkg.Supplier.Person)
+	require.Empty(t, pkg.DownloadLocation)
+	require.Equal(t, "https://github.com/example/hello-py", pkg.HomePage)
+	require.Equal(t, "./hello_py-1.0.0-py3-none-any.whl", pkg.FileName)
+	require.NotEmpty(t, pkg.Checksum["SHA256"])
+	require.Len(t, pkg.ExternalRefs, 1)
+	require.Equal(t, "pkg:pypi/hello-py@1.0.0", pkg.ExternalRefs[0].Locator)
+
+	// Source distributions read the top level PKG-INFO file
+	pkg, err = FromPythonDist("testdata/hello-sdist-0.3.1.tar.gz")
+	require.Nil(t, err)
+	require.Equal(t, "hello-sdist", pkg.Name)
+	require.Equal(t, "0.3.1", pkg.Version)
+	require.Equal(t, "MIT", pkg.LicenseDeclared)
+	require.Equal(t, "Example Maintainers (maintainers@example.com)", pkg.Supplier.Person)
+	require.Equal(t, "pkg:pypi/hello-sdist@0.3.1", pkg.ExternalRefs[0].Locator)
+}
+
+func TestPythonAuthor(t *testing.T) {
+	for _, tc := range []struct {
+		author, email, expected string
+	}{
+		{"Jane Doe", "jane@example.com", "Jane Doe (jane@example.com)"},
+		{"", "Jane Doe <jane@example.com>", "Jane Doe (jane@example.com)"},
+		{"Jane Doe", "Jane Doe <jane@example.com>", "Jane Doe (jane@example.com)"},
+		{"Jane Doe", "", "Jane Doe"},
+		{"", "", ""},
+	} {
+		require.Equal(t, tc.expected, pythonAuthor(tc.author, tc.email))
+	}
+}
+
+func TestPythonLicense(t *testing.T) {
+	for _, tc := range []struct {
+		metadata map[string]string
+		expected string
+	}{
+		{map[string]string{"License-Expression": "MIT OR Apache-2.0"}, "MIT OR Apache-2.0"},
+		{map[string]string{"License-Expression": "MIT", "License": "BSD"}, "MIT"},
+		{map[string]string{"License": "Apache-2.0"}, "Apache-2.0"},
+		{map[string]string{"License": "GPLv2+"}, "GPL-2.0-or-later"},
+		{map[string]string{"License": "Apache License 2.0"}, NOASSERTION},
+		{map[string]string{"License": "Permission is hereby granted (see LICENSE"}, NOASSERTION},
+		{map[string]string{}, ""},
+	} {
+		require.Equal(t, tc.expected, pythonLicense(tc.metadata), tc.metadata)
+	}
+}
+
+func TestFromPythonDistMetadata(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spdx-python-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "private-1.0-py3-none-any.whl")
+	f, err := os.Create(path)
+	require.Nil(t, err)
+	w := zip.NewWriter(f)
+	m, err := w.Create("private-1.0.dist-info/METADATA")
+	require.Nil(t, err)
+	_, err = m.Write([]byte("Metadata-Version: 2.1\nName: private\nVersion: 1.0\n" +
+		"License: The MIT License\n" +
+		"Download-URL: https://TOKEN@pypi.example.com/private-1.0.tar.gz\n"))
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+	require.Nil(t, f.Close())
+
+	pkg, err := FromPythonDist(path)
+	require.Nil(t, err)
+	require.Equal(t, NOASSERTION, pkg.LicenseDeclared)
+	require.Equal(t, "https://pypi.example.com/private-1.0.tar.gz", pkg.DownloadLocation)
+	require.Contains(t, pkg.DownloadLocationComment, "pypi.example.com requires authentication")
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"github.com/pkg/errors"
+)
+
+// SPDX relationship types
+const (
+	RelationshipDescribes            = "DESCRIBES"
+	RelationshipContains             = "CONTAINS"
+	RelationshipDependsOn            = "DEPENDS_ON"
+	RelationshipBuildDependencyOf    = "BUILD_DEPENDENCY_OF"
+	RelationshipDevDependencyOf      = "DEV_DEPENDENCY_OF"
+	RelationshipTestDependencyOf     = "TEST_DEPENDENCY_OF"
+	RelationshipOptionalDependencyOf = "OPTIONAL_DEPENDENCY_OF"
+	RelationshipGeneratedFrom        = "GENERATED_FROM"
+	RelationshipBuildToolOf          = "BUILD_TOOL_OF"
+	RelationshipDevToolOf            = "DEV_TOOL_OF"
+	RelationshipTestOf               = "TEST_OF"
+	RelationshipVariantOf            = "VARIANT_OF"
+	RelationshipCopyOf               = "COPY_OF"
+	RelationshipOther                = "OTHER"
+)
+
+// relationshipTypes are the relationship types defined in SPDX 2.2
+var relationshipTypes = map[string]struct{}{
+	"DESCRIBES": {}, "DESCRIBED_BY": {}, "CONTAINS": {}, "CONTAINED_BY": {},
+	"DEPENDS_ON": {}, "DEPENDENCY_OF": {}, "DEPENDENCY_MANIFEST_OF": {},
+	"BUILD_DEPENDENCY_OF": {}, "DEV_DEPENDENCY_OF": {},
+	"OPTIONAL_DEPENDENCY_OF": {}, "PROVIDED_DEPENDENCY_OF": {},
+	"TEST_DEPENDENCY_OF": {}, "RUNTIME_DEPENDENCY_OF": {}, "EXAMPLE_OF": {},
+	"GENERATES": {}, "GENERATED_FROM": {}, "ANCESTOR_OF": {},
+	"DESCENDANT_OF": {}, "VARIANT_OF": {}, "DISTRIBUTION_ARTIFACT": {},
+	"PATCH_FOR": {}, "PATCH_APPLIED": {}, "COPY_OF": {}, "FILE_ADDED": {},
+	"FILE_DELETED": {}, "FILE_MODIFIED": {}, "EXPANDED_FROM_ARCHIVE": {},
+	"DYNAMIC_LINK": {}, "STATIC_LINK": {}, "DATA_FILE_OF": {},
+	"TEST_CASE_OF": {}, "BUILD_TOOL_OF": {}, "DEV_TOOL_OF": {}, "TEST_OF": {},
+	"TEST_TOOL_OF": {}, "DOCUMENTATION_OF": {}, "OPTIONAL_COMPONENT_OF": {},
+	"METAFILE_OF": {}, "PACKAGE_OF": {}, "AMENDS": {}, "PREREQUISITE_FOR": {},
+	"HAS_PREREQUISITE": {}, "OTHER": {},
+}
+
+// DependencyScope tells when a dependency package is needed
+type DependencyScope string
+
+// Dependency scopes, the empty scope is treated as runtime
+const (
+	ScopeRuntime  DependencyScope = "Runtime"
+	ScopeBuild    DependencyScope = "Build"
+	ScopeDev      DependencyScope = "Dev"
+	ScopeTest     DependencyScope = "Test"
+	ScopeOptional DependencyScope = "Optional"
+)
+
+// scopeRelationships maps the non runtime scopes to the SPDX
+// relationships expressing them. These point from the dependency
+// to the package depending on it.
+var scopeRelationships = map[DependencyScope]string{
+	ScopeBuild:    RelationshipBuildDependencyOf,
+	ScopeDev:      RelationshipDevDependencyOf,
+	ScopeTest:     RelationshipTestDependencyOf,
+	ScopeOptional: RelationshipOptionalDependencyOf,
+}
+
+// DefaultScopeComments are the notes added to the comment of scoped
+// dependencies when the EmitScopeComment option is set
+var DefaultScopeComments = map[DependencyScope]string{
+	ScopeBuild:    "build dependency; not shipped in runtime artifact",
+	ScopeDev:      "development dependency; not shipped in runtime artifact",
+	ScopeTest:     "test dependency; not shipped in runtime artifact",
+	ScopeOptional: "optional dependency; may be missing from runtime artifact",
+}
+
+// dependencyRelationship returns the element, type and related
+// element of the relationship between a package and one of its
+// dependencies, according to the dependency scope
+func dependencyRelationship(p, dep *Package) (element, relType, related string) {
+	if relType, ok := scopeRelationships[dep.Scope]; ok {
+		return dep.ID, relType, p.ID
+	}
+	return p.ID, RelationshipDependsOn, dep.ID
+}
+
+// scopeFromRelationship returns the dependency scope expressed by an
+// *_DEPENDENCY_OF relationship type
+func scopeFromRelationship(relType string) (DependencyScope, bool) {
+	for scope, t := range scopeRelationships {
+		if t == relType {
+			return scope, true
+		}
+	}
+	return "", false
+}
+
+// Relationship links an SPDX element to a peer package or file. The
+// CONTAINS and DEPENDS_ON relationships are expressed through the
+// Packages, Files and Dependencies fields, this is used for the rest.
+type Relationship struct {
+	Type    string   // GENERATED_FROM, BUILD_TOOL_OF, etc
+	Package *Package // Peer package of the relationship
+	File    *File    // Peer file of the relationship
+}
+
+// peerID returns the SPDX ID of the relationship peer
+func (r *Relationship) peerID() string {
+	if r.Package != nil {
+		return r.Package.ID
+	}
+	if r.File != nil {
+		return r.File.ID
+	}
+	return ""
+}
+
+// validate checks that the relationship has a valid SPDX type and a
+// single peer
+func (r *Relationship) validate() error {
+	if r.Type == "" {
+		return errors.New("relationship type not set")
+	}
+	if _, ok := relationshipTypes[r.Type]; !ok {
+		return errors.Errorf("unknown relationship type %q", r.Type)
+	}
+	if (r.Package == nil) == (r.File == nil) {
+		return errors.New("relationship must have either a package or a file as peer")
+	}
+	return nil
+}
+
+// peer returns the ID of the relationship peer of the element with
+// sourceID, or an error if the peer has no ID
+func (r *Relationship) peer(sourceID string) (string, error) {
+	if r.peerID() == "" {
+		return "", errors.Errorf("%s relationship peer of %s has no ID", r.Type, sourceID)
+	}
+	return r.peerID(), nil
+}
+
+// AddRelationship records a relationship from the package to a peer
+func (p *Package) AddRelationship(r *Relationship) error {
+	if err := r.validate(); err != nil {
+		return errors.Wrap(err, "validating relationship")
+	}
+	p.Lock()
+	defer p.Unlock()
+	p.Relationships = append(p.Relationships, r)
+	return nil
+}
+
+// AddRelationships records a list of relationships from the package to
+// its peers acquiring its lock only once. If any of the relationships is
+// invalid, none are added and the error notes its index in the list.
+func (p *Package) AddRelationships(edges []Relationship) error {
+	added := make([]*Relationship, len(edges))
+	for i := range edges {
+		r := edges[i]
+		if err := r.validate(); err != nil {
+			return errors.Wrapf(err, "validating relationship #%d", i)
+		}
+		added[i] = &r
+	}
+	p.Lock()
+	defer p.Unlock()
+	p.Relationships = append(p.Relationships, added...)
+	return nil
+}
+
+// MarkGeneratedFrom records that the package was generated from src,
+// for example a binary package built from a source package
+func (p *Package) MarkGeneratedFrom(src *Package) error {
+	return p.AddRelationship(&Relationship{Type: RelationshipGeneratedFrom, Package: src})
+}
+
+// AddRelationship records a relationship from the file to a peer
+func (f *File) AddRelationship(r *Relationship) error {
+	if err := r.validate(); err != nil {
+		return errors.Wrap(err, "validating relationship")
+	}
+	f.Relationships = append(f.Relationships, r)
+	return nil
+}
+
+// MarkGeneratedFrom records that the file was generated from src,
+// for example a binary compiled from a source file
+func (f *File) MarkGeneratedFrom(src *File) error {
+	return f.AddRelationship(&Relationship{Type: RelationshipGeneratedFrom, File: src})
+}
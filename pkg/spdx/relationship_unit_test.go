@@ -0,0 +1,227 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkGeneratedFrom(t *testing.T) {
+	src := NewPackage()
+	src.Name = "hello-src"
+	src.ID = "SPDXRef-Package-hello-src"
+	srcFile := NewFile()
+	srcFile.ID = "SPDXRef-File-main-go"
+	srcFile.Name = "main.go"
+	srcFile.Checksum = map[string]string{"SHA1": fmt.Sprintf("%040x", 1)}
+	src.FilesAnalyzed = true
+	require.Nil(t, src.AddFile(srcFile))
+
+	bin := NewPackage()
+	bin.Name = "hello"
+	bin.ID = "SPDXRef-Package-hello"
+	binFile := NewFile()
+	binFile.ID = "SPDXRef-File-hello"
+	binFile.Name = "hello"
+	binFile.Checksum = map[string]string{"SHA1": fmt.Sprintf("%040x", 2)}
+	bin.FilesAnalyzed = true
+	require.Nil(t, bin.AddFile(binFile))
+
+	require.Nil(t, bin.MarkGeneratedFrom(src))
+	require.Nil(t, binFile.MarkGeneratedFrom(srcFile))
+	// Relationships need a peer
+	require.NotNil(t, bin.AddRelationship(&Relationship{Type: RelationshipGeneratedFrom}))
+
+	doc, err := bin.Render()
+	require.Nil(t, err)
+
+	fileRel := "Relationship: SPDXRef-File-hello GENERATED_FROM SPDXRef-File-main-go\n"
+	pkgRel := "Relationship: SPDXRef-Package-hello GENERATED_FROM SPDXRef-Package-hello-src\n"
+	require.Contains(t, doc, fileRel)
+	require.Contains(t, doc, pkgRel)
+
+	// The file relationship follows the file body, before the package
+	// lists it as contained
+	fileStart := strings.Index(doc, "FileName: hello\n")
+	require.Greater(t, strings.Index(doc, fileRel), fileStart)
+	require.Less(t, strings.Index(doc, fileRel), strings.Index(doc, "Relationship: SPDXRef-Package-hello CONTAINS SPDXRef-File-hello\n"))
+
+	// The source package is rendered once, before the relationship
+	require.Equal(t, 1, strings.Count(doc, "PackageName: hello-src\n"))
+	require.Less(t, strings.Index(doc, "PackageName: hello-src\n"), strings.Index(doc, pkgRel))
+}
+
+func TestAddRelationships(t *testing.T) {
+	app := NewPackage()
+	app.Name = "app"
+	app.ID = "SPDXRef-Package-app"
+	tool := NewPackage()
+	tool.Name = "tool"
+	tool.ID = "SPDXRef-Package-tool"
+	readme := NewFile()
+	readme.ID = "SPDXRef-File-readme"
+	readme.Name = "README.md"
+	readme.Checksum = map[string]string{"SHA1": fmt.Sprintf("%040x", 1)}
+	app.FilesAnalyzed = true
+	require.Nil(t, app.AddFile(readme))
+
+	require.Nil(t, app.AddRelationships([]Relationship{
+		{Type: RelationshipBuildToolOf, Package: tool},
+		{Type: "DOCUMENTATION_OF", File: readme},
+		{Type: RelationshipOther, Package: tool},
+	}))
+	out, err := app.Render()
+	require.Nil(t, err)
+	require.Contains(t, out, "Relationship: SPDXRef-Package-app BUILD_TOOL_OF SPDXRef-Package-tool\n")
+	require.Contains(t, out, "Relationship: SPDXRef-Package-app DOCUMENTATION_OF SPDXRef-File-readme\n")
+	require.Contains(t, out, "Relationship: SPDXRef-Package-app OTHER SPDXRef-Package-tool\n")
+
+	// An invalid edge aborts the import
+	err = app.AddRelationships([]Relationship{
+		{Type: RelationshipOther, Package: tool},
+		{Package: tool},
+		{Type: RelationshipOther, File: readme},
+	})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "relationship #1")
+	require.Len(t, app.Relationships, 3)
+
+	// Types not defined by SPDX are rejected
+	err = app.AddRelationships([]Relationship{
+		{Type: RelationshipOther, Package: tool},
+		{Type: "LIKES", Package: tool},
+	})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), `relationship #1: unknown relationship type "LIKES"`)
+	require.Len(t, app.Relationships, 3)
+	require.NotNil(t, app.AddRelationship(&Relationship{Type: "LIKES", Package: tool}))
+}
+
+func TestDependencyScopes(t *testing.T) {
+	app := NewPackage()
+	app.Name = "app"
+	app.ID = "SPDXRef-Package-app"
+	for _, dep := range []struct {
+		id    string
+		scope DependencyScope
+	}{
+		{"SPDXRef-Package-runtime", ScopeRuntime},
+		{"SPDXRef-Package-build", ScopeBuild},
+		{"SPDXRef-Package-dev", ScopeDev},
+	} {
+		pkg := NewPackage()
+		pkg.Name = strings.TrimPrefix(dep.id, "SPDXRef-Package-")
+		pkg.ID = dep.id
+		pkg.Scope = dep.scope
+		require.Nil(t, app.AddDependency(pkg))
+	}
+
+	doc, err := app.Render()
+	require.Nil(t, err)
+	require.Contains(t, doc, "Relationship: SPDXRef-Package-app DEPENDS_ON SPDXRef-Package-runtime\n")
+	require.Contains(t, doc, "Relationship: SPDXRef-Package-build BUILD_DEPENDENCY_OF SPDXRef-Package-app\n")
+	require.Contains(t, doc, "Relationship: SPDXRef-Package-dev DEV_DEPENDENCY_OF SPDXRef-Package-app\n")
+	require.Equal(t, 1, strings.Count(doc, "DEPENDS_ON"))
+}
+
+func TestDependencyScopeComment(t *testing.T) {
+	app := NewPackage()
+	app.Name = "app"
+	app.ID = "SPDXRef-Package-app"
+	dev := NewPackage()
+	dev.Name = "dev"
+	dev.ID = "SPDXRef-Package-dev"
+	dev.Scope = ScopeDev
+	dev.Comment = "Linter used in CI"
+	require.Nil(t, app.AddDependency(dev))
+
+	// The note is opt-in
+	doc, err := app.Render()
+	require.Nil(t, err)
+	require.NotContains(t, doc, "development dependency")
+
+	dev.Options().EmitScopeComment = true
+	doc, err = app.Render()
+	require.Nil(t, err)
+	require.Contains(t, doc, "PackageComment: <text>development dependency; not shipped in runtime artifact\n"+
+		"Linter used in CI\n</text>\n")
+
+	// Notes can be overridden per scope
+	dev.Options().ScopeComments = map[DependencyScope]string{ScopeDev: "only used to lint the code"}
+	doc, err = app.Render()
+	require.Nil(t, err)
+	require.Contains(t, doc, "PackageComment: <text>only used to lint the code\nLinter used in CI\n</text>\n")
+
+	// Packages without a scope get no note
+	dev.Scope = ""
+	doc, err = app.Render()
+	require.Nil(t, err)
+	require.Contains(t, doc, "PackageComment: <text>Linter used in CI\n</text>\n")
+}
+
+func TestRelationshipsAtEnd(t *testing.T) {
+	pkg := testPackageWithFiles(t, "MIT", "Apache-2.0")
+	sub := NewPackage()
+	sub.Name = "sub"
+	require.Nil(t, pkg.AddPackage(sub))
+	dep := NewPackage()
+	dep.Name = "dep"
+	require.Nil(t, sub.AddDependency(dep))
+
+	interleaved, err := pkg.Render()
+	require.Nil(t, err)
+	pkg.Options().RelationshipsAtEnd = true
+	atEnd, err := pkg.Render()
+	require.Nil(t, err)
+
+	// splitRelationships separates the relationship lines from the rest
+	splitRelationships := func(doc string) (body string, relationships []string) {
+		for _, line := range strings.Split(doc, "\n") {
+			if strings.HasPrefix(line, "Relationship: ") {
+				relationships = append(relationships, line)
+			} else if line != "" {
+				body += line + "\n"
+			}
+		}
+		return body, relationships
+	}
+	interleavedBody, interleavedRels := splitRelationships(interleaved)
+	atEndBody, atEndRels := splitRelationships(atEnd)
+	require.Equal(t, interleavedBody, atEndBody)
+	require.Equal(t, interleavedRels, atEndRels)
+	require.Len(t, atEndRels, 4)
+
+	// All relationships are in a single trailing block
+	block := strings.Join(atEndRels, "\n") + "\n\n"
+	require.True(t, strings.HasSuffix(atEnd, block))
+	require.Equal(t, 1, strings.Count(atEnd, "Relationship: "+pkg.ID+" CONTAINS "+sub.ID))
+	require.NotEqual(t, interleaved, atEnd)
+
+	// Documents describing the package render the same way
+	doc := NewDocument()
+	doc.Name = "at-end"
+	require.Nil(t, doc.AddPackage(pkg))
+	rendered, err := doc.Render()
+	require.Nil(t, err)
+	require.True(t, strings.HasSuffix(
+		rendered, "##### Relationships\n\n"+block[:len(block)-1]+"Relationship: SPDXRef-DOCUMENT DESCRIBES "+pkg.ID+"\n\n",
+	))
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	rpmLeadLength        = 96
+	rpmHeaderIndexLength = 16
+	rpmMaxHeaderSize     = 32 * 1024 * 1024
+
+	// Tags we read from the rpm header
+	rpmTagName    = 1000
+	rpmTagVersion = 1001
+	rpmTagRelease = 1002
+	rpmTagVendor  = 1011
+	rpmTagLicense = 1014
+	rpmTagURL     = 1020
+	rpmTagArch    = 1022
+
+	// Data types of the header entries
+	rpmTypeString     = 6
+	rpmTypeI18NString = 9
+)
+
+var (
+	rpmLeadMagic   = []byte{0xed, 0xab, 0xee, 0xdb}
+	rpmHeaderMagic = []byte{0x8e, 0xad, 0xe8, 0x01}
+)
+
+// FromRPM builds a SPDX package from the header data of an rpm file
+func FromRPM(path string) (*Package, error) {
+	tags, err := readRPMHeader(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading rpm header")
+	}
+
+	if tags[rpmTagName] == "" {
+		return nil, errors.New("rpm header does not define a package name")
+	}
+
+	pkg := NewPackage()
+	pkg.Options().WorkDir = filepath.Dir(path)
+	if err := pkg.ReadSourceFile(path); err != nil {
+		return nil, errors.Wrap(err, "reading rpm source file")
+	}
+	pkg.Name = tags[rpmTagName]
+	// rpm versions are expressed as version-release
+	pkg.Version = tags[rpmTagVersion]
+	if tags[rpmTagRelease] != "" {
+		pkg.Version += "-" + tags[rpmTagRelease]
+	}
+	pkg.LicenseDeclared = tags[rpmTagLicense]
+	pkg.Supplier.Organization = tags[rpmTagVendor]
+	pkg.DownloadLocation = tags[rpmTagURL]
+	pkg.HomePage = tags[rpmTagURL]
+	pkg.SourceInfo = "acquired package info from the header of " + filepath.Base(path)
+	pkg.AddPackageURL(buildPackageURL(
+		"rpm", "", pkg.Name, pkg.Version,
+		map[string]string{"arch": tags[rpmTagArch]},
+	))
+	return pkg, nil
+}
+
+// readRPMHeader reads the header of an rpm file and returns the
+// string values of the tags we are interested in
+func readRPMHeader(path string) (map[int32]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening rpm file")
+	}
+	defer f.Close()
+
+	lead := make([]byte, rpmLeadLength)
+	if _, err := io.ReadFull(f, lead); err != nil {
+		return nil, errors.Wrap(err, "reading rpm lead")
+	}
+	if !bytes.Equal(lead[0:4], rpmLeadMagic) {
+		return nil, errors.New("file is not an rpm package")
+	}
+
+	// The signature header comes first, it is padded to 8 bytes
+	sigSize, err := skipRPMHeader(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading rpm signature header")
+	}
+	if pad := sigSize % 8; pad != 0 {
+		if _, err := f.Seek(8-pad, io.SeekCurrent); err != nil {
+			return nil, errors.Wrap(err, "skipping signature padding")
+		}
+	}
+
+	index, store, err := readRPMHeaderStructure(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading rpm main header")
+	}
+
+	tags := map[int32]string{}
+	for i := 0; i < len(index); i += rpmHeaderIndexLength {
+		tag := int32(binary.BigEndian.Uint32(index[i:]))
+		dataType := binary.BigEndian.Uint32(index[i+4:])
+		offset := binary.BigEndian.Uint32(index[i+8:])
+
+		switch tag {
+		case rpmTagName, rpmTagVersion, rpmTagRelease, rpmTagVendor,
+			rpmTagLicense, rpmTagURL, rpmTagArch:
+		default:
+			continue
+		}
+		if dataType != rpmTypeString && dataType != rpmTypeI18NString {
+			continue
+		}
+		if int(offset) >= len(store) {
+			return nil, errors.Errorf("rpm header tag %d points outside of the data store", tag)
+		}
+		// Strings are NUL terminated. For i18n strings we use the
+		// first one which is the default locale.
+		value := store[offset:]
+		if end := bytes.IndexByte(value, 0); end != -1 {
+			value = value[:end]
+		}
+		tags[tag] = string(value)
+	}
+	return tags, nil
+}
+
+// skipRPMHeader reads past a header structure and returns its size
+func skipRPMHeader(r io.Reader) (int64, error) {
+	index, store, err := readRPMHeaderStructure(r)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(index) + len(store)), nil
+}
+
+// readRPMHeaderStructure reads a header structure returning the raw index
+// entries and data store
+func readRPMHeaderStructure(r io.Reader) (index, store []byte, err error) {
+	intro := make([]byte, 16)
+	if _, err := io.ReadFull(r, intro); err != nil {
+		return nil, nil, errors.Wrap(err, "reading header intro")
+	}
+	if !bytes.Equal(intro[0:4], rpmHeaderMagic) {
+		return nil, nil, errors.New("invalid rpm header magic")
+	}
+	entries := int64(binary.BigEndian.Uint32(intro[8:12]))
+	storeSize := int64(binary.BigEndian.Uint32(intro[12:16]))
+	if entries*rpmHeaderIndexLength+storeSize > rpmMaxHeaderSize {
+		return nil, nil, errors.New("rpm header exceeds maximum size")
+	}
+
+	index = make([]byte, entries*rpmHeaderIndexLength)
+	if _, err := io.ReadFull(r, index); err != nil {
+		return nil, nil, errors.Wrap(err, "reading header index")
+	}
+	store = make([]byte, storeSize)
+	if _, err := io.ReadFull(r, store); err != nil {
+		return nil, nil, errors.Wrap(err, "reading header data store")
+	}
+	return index, store, nil
+}
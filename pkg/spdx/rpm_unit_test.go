@@ -0,0 +1,48 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromRPM(t *testing.T) {
+	_, err := FromRPM("testdata/hello_1.0.0-1_amd64.deb")
+	require.NotNil(t, err)
+
+	pkg, err := FromRPM("testdata/hello-1.2.3-4.fc34.x86_64.rpm")
+	require.Nil(t, err)
+	require.NotNil(t, pkg)
+
+	require.Equal(t, "hello", pkg.Name)
+	require.Equal(t, "1.2.3-4.fc34", pkg.Version)
+	require.Equal(t, "GPL-2.0-or-later", pkg.LicenseDeclared)
+	require.Equal(t, "Fedora Project", pkg.Supplier.Organization)
+	require.Equal(t, "https://example.com/hello", pkg.DownloadLocation)
+	require.Equal(t, "https://example.com/hello", pkg.HomePage)
+	require.NotEmpty(t, pkg.Checksum["SHA256"])
+	require.Len(t, pkg.ExternalRefs, 1)
+	require.Equal(t, "pkg:rpm/hello@1.2.3-4.fc34?arch=x86_64", pkg.ExternalRefs[0].Locator)
+	require.NotEmpty(t, pkg.SourceInfo)
+
+	pkg.ID = "SPDXRef-Package-hello"
+	doc, err := pkg.Render()
+	require.Nil(t, err)
+	require.Contains(t, doc, "PackageSourceInfo: <text>acquired package info from the header of hello-1.2.3-4.fc34.x86_64.rpm\n</text>\n")
+}
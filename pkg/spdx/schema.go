@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	_ "embed" // Needed to embed the SPDX JSON schema
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// spdxJSONSchema is the SPDX JSON schema the rendered documents are
+// validated against
+//
+//go:embed spdx-schema.json
+var spdxJSONSchema []byte
+
+// The compiled schema is shared by all validations
+var (
+	compiledSchemaOnce sync.Once
+	compiledSchema     *gojsonschema.Schema
+	compiledSchemaErr  error
+)
+
+// ValidateJSON renders the document as SPDX JSON and validates it
+// against the SPDX JSON schema. The errors name the path of the
+// offending fields.
+func (d *Document) ValidateJSON() error {
+	data, err := d.RenderJSON()
+	if err != nil {
+		return errors.Wrap(err, "rendering document as JSON")
+	}
+	return validateSPDXJSON(data)
+}
+
+// validateSPDXJSON validates a JSON document against the SPDX schema
+func validateSPDXJSON(data []byte) error {
+	schema, err := loadSPDXJSONSchema()
+	if err != nil {
+		return err
+	}
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		return errors.Wrap(err, "parsing SPDX JSON document")
+	}
+	if result.Valid() {
+		return nil
+	}
+	violations := []string{}
+	for _, e := range result.Errors() {
+		violations = append(violations, schemaErrorPath(e)+": "+e.Description())
+	}
+	return errors.Errorf(
+		"document does not match the SPDX JSON schema:\n%s", strings.Join(violations, "\n"),
+	)
+}
+
+// loadSPDXJSONSchema compiles the embedded schema the first time it is
+// needed and returns the cached result afterwards
+func loadSPDXJSONSchema() (*gojsonschema.Schema, error) {
+	compiledSchemaOnce.Do(func() {
+		compiledSchema, compiledSchemaErr = gojsonschema.NewSchema(
+			gojsonschema.NewBytesLoader(spdxJSONSchema),
+		)
+		if compiledSchemaErr != nil {
+			compiledSchemaErr = errors.Wrap(compiledSchemaErr, "loading SPDX JSON schema")
+		}
+	})
+	return compiledSchema, compiledSchemaErr
+}
+
+// schemaErrorPath returns the path of the value that failed validation
+// as a JSON pointer, eg /packages/0/name
+func schemaErrorPath(e gojsonschema.ResultError) string {
+	path := strings.TrimPrefix(e.Context().String(), gojsonschema.STRING_CONTEXT_ROOT)
+	return "/" + strings.TrimPrefix(strings.ReplaceAll(path, ".", "/"), "/")
+}
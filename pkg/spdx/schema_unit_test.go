@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateJSON(t *testing.T) {
+	doc := NewDocument()
+	doc.Name = "schema"
+	doc.Namespace = "https://example.com/schema"
+	pkg := testPackageWithFiles(t, "MIT", "Apache-2.0")
+	dep := NewPackage()
+	dep.Name = "dep"
+	dep.Scope = ScopeDev
+	require.Nil(t, pkg.AddDependency(dep))
+	require.Nil(t, doc.AddPackage(pkg))
+	require.Nil(t, doc.ValidateJSON())
+
+	data, err := doc.RenderJSON()
+	require.Nil(t, err)
+	corrupt := func(modify func(map[string]interface{})) error {
+		parsed := map[string]interface{}{}
+		require.Nil(t, json.Unmarshal(data, &parsed))
+		modify(parsed)
+		corrupted, err := json.Marshal(parsed)
+		require.Nil(t, err)
+		return validateSPDXJSON(corrupted)
+	}
+
+	// Required fields are reported with their path
+	err = corrupt(func(doc map[string]interface{}) {
+		delete(doc["creationInfo"].(map[string]interface{}), "created")
+	})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "/creationInfo: created is required")
+
+	err = corrupt(func(doc map[string]interface{}) {
+		delete(doc["packages"].([]interface{})[0].(map[string]interface{}), "downloadLocation")
+	})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "/packages/0: downloadLocation is required")
+
+	// So are wrong types and values
+	err = corrupt(func(doc map[string]interface{}) {
+		doc["relationships"].([]interface{})[0].(map[string]interface{})["relationshipType"] = "LIKES"
+		doc["name"] = 1
+	})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "/relationships/0/relationshipType: relationships.0.relationshipType must be one of the following")
+	require.Contains(t, err.Error(), "/name: Invalid type. Expected: string, given: integer")
+
+	// Patterns and array sizes apply too
+	err = corrupt(func(doc map[string]interface{}) {
+		doc["creationInfo"].(map[string]interface{})["creators"] = []interface{}{}
+		doc["SPDXID"] = "SPDXRef-with spaces"
+	})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "/creationInfo/creators: Array must have at least 1 items")
+	require.Contains(t, err.Error(), "/SPDXID: Does not match pattern")
+
+	// Documents that are not JSON can't be validated
+	require.NotNil(t, validateSPDXJSON([]byte("not json")))
+}
+
+func TestLoadSPDXJSONSchemaOnce(t *testing.T) {
+	first, err := loadSPDXJSONSchema()
+	require.NoError(t, err)
+	second, err := loadSPDXJSONSchema()
+	require.NoError(t, err)
+	require.Same(t, first, second)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Signer produces detached signatures of rendered SPDX documents. It
+// can be implemented on top of any signing backend (cosign, PGP, KMS)
+// so this package does not depend on one.
+type Signer interface {
+	Sign(data []byte) (signature []byte, err error)
+}
+
+// Verifier checks detached signatures produced by a Signer
+type Verifier interface {
+	Verify(data, signature []byte) error
+}
+
+// Sign returns a detached signature of the rendered document doc
+func Sign(doc []byte, signer Signer) ([]byte, error) {
+	if len(doc) == 0 {
+		return nil, errors.New("unable to sign empty document")
+	}
+	if signer == nil {
+		return nil, errors.New("no signer specified")
+	}
+	signature, err := signer.Sign(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "signing document")
+	}
+	if len(signature) == 0 {
+		return nil, errors.New("signer returned an empty signature")
+	}
+	return signature, nil
+}
+
+// Verify checks that signature is a valid detached signature of doc
+func Verify(doc, signature []byte, verifier Verifier) error {
+	if len(signature) == 0 {
+		return errors.New("document signature is empty")
+	}
+	if verifier == nil {
+		return errors.New("no verifier specified")
+	}
+	return errors.Wrap(verifier.Verify(doc, signature), "verifying document signature")
+}
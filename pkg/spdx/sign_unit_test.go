@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSigner signs with an in-memory HMAC key
+type fakeSigner struct {
+	key []byte
+}
+
+func (s *fakeSigner) Sign(data []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(data) // nolint: errcheck
+	return mac.Sum(nil), nil
+}
+
+func (s *fakeSigner) Verify(data, signature []byte) error {
+	expected, err := s.Sign(data)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(expected, signature) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+func TestSignVerify(t *testing.T) {
+	doc := NewDocument()
+	doc.Name = "signed"
+	doc.Namespace = "https://example.com/signed"
+	pkg := NewPackage()
+	pkg.Name = "signed-package"
+	require.Nil(t, doc.AddPackage(pkg))
+	rendered, err := doc.Render()
+	require.Nil(t, err)
+
+	signer := &fakeSigner{key: []byte("test-key")}
+	signature, err := Sign([]byte(rendered), signer)
+	require.Nil(t, err)
+	require.NotEmpty(t, signature)
+	require.Nil(t, Verify([]byte(rendered), signature, signer))
+
+	// Tampering with the document breaks the signature
+	tampered := []byte(rendered + "PackageComment: tampered\n")
+	require.NotNil(t, Verify(tampered, signature, signer))
+	// So does verifying with another key
+	require.NotNil(t, Verify([]byte(rendered), signature, &fakeSigner{key: []byte("other")}))
+
+	_, err = Sign(nil, signer)
+	require.NotNil(t, err)
+	require.NotNil(t, Verify([]byte(rendered), nil, signer))
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+var snippetTemplate = `SnippetSPDXID: {{ .ID }}
+SnippetFromFileSPDXID: {{ .SnippetFromFile }}
+SnippetByteRange: {{ .ByteRange }}
+{{ if not .LineRange.IsZero }}SnippetLineRange: {{ .LineRange }}
+{{ end -}}
+SnippetLicenseConcluded: {{ if .LicenseConcluded }}{{ .LicenseConcluded }}{{ else }}NOASSERTION{{ end }}
+{{ range .LicenseInfoInSnippet }}LicenseInfoInSnippet: {{ . }}
+{{ else }}LicenseInfoInSnippet: NOASSERTION
+{{ end -}}
+SnippetCopyrightText: {{ if .CopyrightText }}<text>{{ escapeText .CopyrightText }}
+</text>{{ else }}NOASSERTION{{ end }}
+{{ textField "SnippetComment" .Comment }}
+`
+
+// Snippet describes the licensing of a part of a file, such as code
+// copied from a project under a different license
+type Snippet struct {
+	ID                   string       // SPDXRef-Snippet-vendored
+	SnippetFromFile      string       // ID of the file containing the snippet
+	ByteRange            SnippetRange // Bytes of the file in the snippet, starting at 1
+	LineRange            SnippetRange // Lines of the file in the snippet (optional)
+	LicenseConcluded     string       // MIT
+	LicenseInfoInSnippet []string     // Licenses found in the snippet
+	CopyrightText        string       // Copyright notices of the snippet
+	Comment              string       // Free form comment about the snippet
+}
+
+// SnippetRange is an inclusive range of bytes or lines of a file,
+// both ends are counted from 1
+type SnippetRange struct {
+	Start int
+	End   int
+}
+
+// IsZero returns true if the range is not set
+func (r SnippetRange) IsZero() bool {
+	return r.Start == 0 && r.End == 0
+}
+
+// String renders the range in the start:end form of tag-value documents
+func (r SnippetRange) String() string {
+	return fmt.Sprintf("%d:%d", r.Start, r.End)
+}
+
+// validate checks the range starts at 1 or later and is not reversed
+func (r SnippetRange) validate() error {
+	if r.Start < 1 || r.End < r.Start {
+		return errors.Errorf("invalid range %s", r)
+	}
+	return nil
+}
+
+// parseSnippetRange parses a range in the start:end form
+func parseSnippetRange(value string) (SnippetRange, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return SnippetRange{}, errors.Errorf("invalid range %q", value)
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return SnippetRange{}, errors.Wrapf(err, "parsing start of range %q", value)
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return SnippetRange{}, errors.Wrapf(err, "parsing end of range %q", value)
+	}
+	r := SnippetRange{Start: start, End: end}
+	return r, r.validate()
+}
+
+// AddSnippet adds a snippet of the file. Snippets without an ID get one
+// generated from the file name and the snippet ranges. The snippet
+// must have a byte range, its line range is optional.
+func (f *File) AddSnippet(s *Snippet) error {
+	if err := s.ByteRange.validate(); err != nil {
+		return errors.Wrap(err, "checking snippet byte range")
+	}
+	if !s.LineRange.IsZero() {
+		if err := s.LineRange.validate(); err != nil {
+			return errors.Wrap(err, "checking snippet line range")
+		}
+	}
+	if s.SnippetFromFile != "" && f.ID != "" && s.SnippetFromFile != f.ID {
+		return errors.Errorf("snippet is from file %s, not %s", s.SnippetFromFile, f.ID)
+	}
+	if s.ID == "" {
+		if f.Name == "" {
+			return errors.New("unable to generate snippet ID, filename not set")
+		}
+		h := sha1.New()
+		if _, err := h.Write([]byte(f.Name + ":" + s.ByteRange.String() + ":" + s.LineRange.String())); err != nil {
+			return errors.Wrap(err, "getting sha1 of snippet ranges")
+		}
+		s.ID = "SPDXRef-Snippet-" + fmt.Sprintf("%x", h.Sum(nil))
+	}
+	for _, existing := range f.Snippets {
+		if existing.ID == s.ID {
+			return errors.Errorf("file already has a snippet with ID %s", s.ID)
+		}
+	}
+	f.Snippets = append(f.Snippets, s)
+	return nil
+}
+
+// snippetViews returns copies of the file snippets pointing to the file
+// ID, which is only known once the file is added to a package
+func (f *File) snippetViews() ([]*Snippet, error) {
+	views := make([]*Snippet, 0, len(f.Snippets))
+	for _, s := range f.Snippets {
+		if s.SnippetFromFile != "" && s.SnippetFromFile != f.ID {
+			return nil, errors.Errorf("snippet %s is from file %s, not %s", s.ID, s.SnippetFromFile, f.ID)
+		}
+		if f.ID == "" {
+			return nil, errors.Errorf("unable to render snippet %s, file %s has no ID", s.ID, f.Name)
+		}
+		view := *s
+		view.SnippetFromFile = f.ID
+		views = append(views, &view)
+	}
+	return views, nil
+}
+
+// renderSnippets renders the blocks of the file snippets
+func (f *File) renderSnippets() (string, error) {
+	if len(f.Snippets) == 0 {
+		return "", nil
+	}
+	views, err := f.snippetViews()
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New("snippet").Funcs(templateFuncs).Parse(snippetTemplate)
+	if err != nil {
+		return "", errors.Wrap(err, "parsing snippet template")
+	}
+	var buf bytes.Buffer
+	for _, s := range views {
+		if err := tmpl.Execute(&buf, s); err != nil {
+			return "", errors.Wrap(err, "executing spdx snippet template")
+		}
+	}
+	return buf.String(), nil
+}
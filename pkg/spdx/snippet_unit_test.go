@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderSnippet(t *testing.T) {
+	pkg := NewPackage()
+	pkg.ID = "SPDXRef-Package-snippets"
+	pkg.Name = "snippets"
+	pkg.FilesAnalyzed = true
+	f := NewFile()
+	f.Name = "./vendor.go"
+	f.Checksum = map[string]string{"SHA1": "a94a8fe5ccb19ba61c4c0873d391e987982fbbd3"}
+	require.Nil(t, f.AddSnippet(&Snippet{
+		ID:                   "SPDXRef-Snippet-vendored",
+		ByteRange:            SnippetRange{Start: 310, End: 420},
+		LineRange:            SnippetRange{Start: 5, End: 23},
+		LicenseConcluded:     "MIT",
+		LicenseInfoInSnippet: []string{"MIT"},
+		CopyrightText:        "Copyright 2019 Example Authors",
+	}))
+	require.Nil(t, pkg.AddFile(f))
+
+	out, err := pkg.Render()
+	require.Nil(t, err)
+	require.Contains(t, out, "SnippetSPDXID: SPDXRef-Snippet-vendored\n"+
+		"SnippetFromFileSPDXID: "+f.ID+"\n"+
+		"SnippetByteRange: 310:420\n"+
+		"SnippetLineRange: 5:23\n"+
+		"SnippetLicenseConcluded: MIT\n"+
+		"LicenseInfoInSnippet: MIT\n"+
+		"SnippetCopyrightText: <text>Copyright 2019 Example Authors\n</text>\n",
+	)
+
+	// Snippets are read back in both formats
+	parsed, err := Parse(strings.NewReader(out))
+	require.Nil(t, err)
+	expected := *f.Snippets[0]
+	expected.SnippetFromFile = f.ID
+	require.Equal(t, &expected, parsed.Files[f.ID].Snippets[0])
+
+	doc := NewDocument()
+	doc.Name = "snippets"
+	doc.Namespace = "https://example.com/snippets"
+	require.Nil(t, doc.AddPackage(pkg))
+	require.Nil(t, doc.ValidateJSON())
+	data, err := doc.RenderJSON()
+	require.Nil(t, err)
+	jsonDoc, err := ParseJSON(strings.NewReader(string(data)))
+	require.Nil(t, err)
+	require.Equal(t, &expected, jsonDoc.Packages[pkg.ID].Files[f.ID].Snippets[0])
+}
+
+func TestAddSnippet(t *testing.T) {
+	f := NewFile()
+	f.Name = "./vendor.go"
+	for _, r := range []SnippetRange{{}, {Start: 0, End: 10}, {Start: 20, End: 10}} {
+		require.NotNil(t, f.AddSnippet(&Snippet{ByteRange: r}), r.String())
+	}
+	require.NotNil(t, f.AddSnippet(&Snippet{
+		ByteRange: SnippetRange{Start: 1, End: 10},
+		LineRange: SnippetRange{Start: 3, End: 1},
+	}))
+
+	// IDs are generated from the ranges
+	require.Nil(t, f.AddSnippet(&Snippet{ByteRange: SnippetRange{Start: 1, End: 10}}))
+	require.True(t, strings.HasPrefix(f.Snippets[0].ID, "SPDXRef-Snippet-"))
+	require.NotNil(t, f.AddSnippet(&Snippet{ByteRange: SnippetRange{Start: 1, End: 10}}))
+	require.Len(t, f.Snippets, 1)
+}
@@ -20,10 +20,8 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"strings"
 
 	"github.com/google/uuid"
-	"github.com/nozzle/throttler"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
@@ -69,6 +67,10 @@ type Options struct {
 	LicenseCacheDir  string   // Directory to cache SPDX license downloads
 	LicenseData      string   // Directory to store the SPDX licenses
 	IgnorePatterns   []string // Patterns to ignore when scanning file
+	CaptureNotices   bool     // Record the contents of NOTICE files as their file notice
+
+	SymlinkPolicy SymlinkPolicy // What to do with symlinks found in directories
+	Concurrency   int           // Number of files read in parallel from directories
 }
 
 func (spdx *SPDX) Options() *Options {
@@ -82,6 +84,7 @@ var defaultSPDXOptions = Options{
 	ProcessGoModules: true,
 	IgnorePatterns:   []string{},
 	ScanLicenses:     true,
+	Concurrency:      5,
 }
 
 type ArchiveManifest struct {
@@ -102,10 +105,6 @@ func (spdx *SPDX) PackageFromDirectory(dirPath string) (pkg *Package, err error)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting absolute directory path")
 	}
-	fileList, err := spdx.impl.GetDirectoryTree(dirPath)
-	if err != nil {
-		return nil, errors.Wrap(err, "building directory tree")
-	}
 	reader, err := spdx.impl.LicenseReader(spdx.Options())
 	if err != nil {
 		return nil, errors.Wrap(err, "creating license reader")
@@ -130,12 +129,7 @@ func (spdx *SPDX) PackageFromDirectory(dirPath string) (pkg *Package, err error)
 		return nil, errors.Wrap(err, "building ignore patterns list")
 	}
 
-	// Apply the ignore patterns to the list of files
-	fileList = spdx.impl.ApplyIgnorePatterns(fileList, patterns)
-	logrus.Infof("Scanning %d files and adding them to the SPDX package", len(fileList))
-
 	pkg = NewPackage()
-	pkg.FilesAnalyzed = true
 	pkg.Name = filepath.Base(dirPath)
 	// If the package file will result in an empty ID, generate one
 	reg := regexp.MustCompile(validNameCharsRe)
@@ -143,46 +137,32 @@ func (spdx *SPDX) PackageFromDirectory(dirPath string) (pkg *Package, err error)
 		pkg.Name = uuid.NewString()
 	}
 	pkg.LicenseConcluded = licenseTag
+	pkg.Options().SymlinkPolicy = spdx.Options().SymlinkPolicy
+	pkg.Options().Concurrency = spdx.Options().Concurrency
 
-	t := throttler.New(5, len(fileList))
-
-	processDirectoryFile := func(path string, pkg *Package) {
-		defer t.Done(err)
-		f := NewFile()
-		f.FileName = path
-		f.SourceFile = filepath.Join(dirPath, path)
-		lic, err = reader.LicenseFromFile(f.SourceFile)
+	// Scan the licenses of the files as they are read
+	processDirectoryFile := func(f *File) error {
+		lic, err := reader.LicenseFromFile(f.SourceFile)
 		if err != nil {
-			err = errors.Wrap(err, "scanning file for license")
-			return
+			return errors.Wrap(err, "scanning file for license")
 		}
-		f.LicenseInfoInFile = NONE
+		f.LicenseInfoInFile = []string{NONE}
 		if lic == nil {
 			f.LicenseConcluded = licenseTag
 		} else {
-			f.LicenseInfoInFile = lic.LicenseID
+			f.LicenseInfoInFile = []string{lic.LicenseID}
 		}
-
-		if err = f.ReadSourceFile(filepath.Join(dirPath, path)); err != nil {
-			err = errors.Wrap(err, "checksumming file")
-			return
-		}
-		f.Name = strings.TrimPrefix(path, dirPath+string(filepath.Separator))
-		if err = pkg.AddFile(f); err != nil {
-			err = errors.Wrapf(err, "adding %s as file to the spdx package", path)
-			return
+		if spdx.Options().CaptureNotices {
+			if err := f.ReadNotice(f.SourceFile); err != nil {
+				return errors.Wrap(err, "capturing license notice")
+			}
 		}
+		return nil
 	}
-
-	// Read the files in parallel
-	for _, path := range fileList {
-		go processDirectoryFile(path, pkg)
-		t.Throttle()
-	}
-
-	if err := t.Err(); err != nil {
-		return nil, err
+	if err := pkg.readDirectory(dirPath, patterns, processDirectoryFile); err != nil {
+		return nil, errors.Wrap(err, "reading directory files")
 	}
+	logrus.Infof("Added %d files from %s to the SPDX package", len(pkg.Files), dirPath)
 
 	if util.Exists(filepath.Join(dirPath, GoModFileName)) && spdx.Options().ProcessGoModules {
 		logrus.Info("Directory contains a go module. Scanning go packages")
@@ -0,0 +1,1240 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.26.0
+// 	protoc        (unknown)
+// source: pkg/spdx/spdx.proto
+
+package spdx
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ProtoPackageGraph is a flattened tree of packages and files. Elements
+// are listed once and linked through edges, so cycles can be represented.
+type ProtoPackageGraph struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RootId   string          `protobuf:"bytes,1,opt,name=root_id,json=rootId,proto3" json:"root_id,omitempty"`
+	Packages []*ProtoPackage `protobuf:"bytes,2,rep,name=packages,proto3" json:"packages,omitempty"`
+	Files    []*ProtoFile    `protobuf:"bytes,3,rep,name=files,proto3" json:"files,omitempty"`
+	Edges    []*ProtoEdge    `protobuf:"bytes,4,rep,name=edges,proto3" json:"edges,omitempty"`
+}
+
+func (x *ProtoPackageGraph) Reset() {
+	*x = ProtoPackageGraph{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_spdx_spdx_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProtoPackageGraph) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoPackageGraph) ProtoMessage() {}
+
+func (x *ProtoPackageGraph) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_spdx_spdx_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoPackageGraph.ProtoReflect.Descriptor instead.
+func (*ProtoPackageGraph) Descriptor() ([]byte, []int) {
+	return file_pkg_spdx_spdx_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ProtoPackageGraph) GetRootId() string {
+	if x != nil {
+		return x.RootId
+	}
+	return ""
+}
+
+func (x *ProtoPackageGraph) GetPackages() []*ProtoPackage {
+	if x != nil {
+		return x.Packages
+	}
+	return nil
+}
+
+func (x *ProtoPackageGraph) GetFiles() []*ProtoFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *ProtoPackageGraph) GetEdges() []*ProtoEdge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+type ProtoPackage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id                      string              `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                    string              `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Version                 string              `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	FileName                string              `protobuf:"bytes,4,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	DownloadLocation        string              `protobuf:"bytes,5,opt,name=download_location,json=downloadLocation,proto3" json:"download_location,omitempty"`
+	FilesAnalyzed           bool                `protobuf:"varint,6,opt,name=files_analyzed,json=filesAnalyzed,proto3" json:"files_analyzed,omitempty"`
+	VerificationCode        string              `protobuf:"bytes,7,opt,name=verification_code,json=verificationCode,proto3" json:"verification_code,omitempty"`
+	LicenseConcluded        string              `protobuf:"bytes,8,opt,name=license_concluded,json=licenseConcluded,proto3" json:"license_concluded,omitempty"`
+	LicenseInfoFromFiles    []string            `protobuf:"bytes,9,rep,name=license_info_from_files,json=licenseInfoFromFiles,proto3" json:"license_info_from_files,omitempty"`
+	LicenseDeclared         string              `protobuf:"bytes,10,opt,name=license_declared,json=licenseDeclared,proto3" json:"license_declared,omitempty"`
+	LicenseComments         string              `protobuf:"bytes,11,opt,name=license_comments,json=licenseComments,proto3" json:"license_comments,omitempty"`
+	CopyrightText           string              `protobuf:"bytes,12,opt,name=copyright_text,json=copyrightText,proto3" json:"copyright_text,omitempty"`
+	SupplierPerson          string              `protobuf:"bytes,13,opt,name=supplier_person,json=supplierPerson,proto3" json:"supplier_person,omitempty"`
+	SupplierOrganization    string              `protobuf:"bytes,14,opt,name=supplier_organization,json=supplierOrganization,proto3" json:"supplier_organization,omitempty"`
+	OriginatorPerson        string              `protobuf:"bytes,15,opt,name=originator_person,json=originatorPerson,proto3" json:"originator_person,omitempty"`
+	OriginatorOrganization  string              `protobuf:"bytes,16,opt,name=originator_organization,json=originatorOrganization,proto3" json:"originator_organization,omitempty"`
+	Checksums               []*ProtoChecksum    `protobuf:"bytes,17,rep,name=checksums,proto3" json:"checksums,omitempty"`
+	ExternalRefs            []*ProtoExternalRef `protobuf:"bytes,18,rep,name=external_refs,json=externalRefs,proto3" json:"external_refs,omitempty"`
+	ArchiveFileName         string              `protobuf:"bytes,19,opt,name=archive_file_name,json=archiveFileName,proto3" json:"archive_file_name,omitempty"`
+	Scope                   string              `protobuf:"bytes,20,opt,name=scope,proto3" json:"scope,omitempty"`
+	HomePage                string              `protobuf:"bytes,21,opt,name=home_page,json=homePage,proto3" json:"home_page,omitempty"`
+	Summary                 string              `protobuf:"bytes,22,opt,name=summary,proto3" json:"summary,omitempty"`
+	Description             string              `protobuf:"bytes,23,opt,name=description,proto3" json:"description,omitempty"`
+	Comment                 string              `protobuf:"bytes,24,opt,name=comment,proto3" json:"comment,omitempty"`
+	SourceInfo              string              `protobuf:"bytes,25,opt,name=source_info,json=sourceInfo,proto3" json:"source_info,omitempty"`
+	AttributionText         []string            `protobuf:"bytes,26,rep,name=attribution_text,json=attributionText,proto3" json:"attribution_text,omitempty"`
+	DownloadLocationComment string              `protobuf:"bytes,27,opt,name=download_location_comment,json=downloadLocationComment,proto3" json:"download_location_comment,omitempty"`
+	IsPrimary               bool                `protobuf:"varint,28,opt,name=is_primary,json=isPrimary,proto3" json:"is_primary,omitempty"`
+	SourceFile              string              `protobuf:"bytes,29,opt,name=source_file,json=sourceFile,proto3" json:"source_file,omitempty"`
+	// The files of the package are read from a file provider, its
+	// CONTAINS edges to files are yielded by the provider when decoded
+	FileProvider bool `protobuf:"varint,30,opt,name=file_provider,json=fileProvider,proto3" json:"file_provider,omitempty"`
+}
+
+func (x *ProtoPackage) Reset() {
+	*x = ProtoPackage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_spdx_spdx_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProtoPackage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoPackage) ProtoMessage() {}
+
+func (x *ProtoPackage) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_spdx_spdx_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoPackage.ProtoReflect.Descriptor instead.
+func (*ProtoPackage) Descriptor() ([]byte, []int) {
+	return file_pkg_spdx_spdx_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ProtoPackage) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetDownloadLocation() string {
+	if x != nil {
+		return x.DownloadLocation
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetFilesAnalyzed() bool {
+	if x != nil {
+		return x.FilesAnalyzed
+	}
+	return false
+}
+
+func (x *ProtoPackage) GetVerificationCode() string {
+	if x != nil {
+		return x.VerificationCode
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetLicenseConcluded() string {
+	if x != nil {
+		return x.LicenseConcluded
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetLicenseInfoFromFiles() []string {
+	if x != nil {
+		return x.LicenseInfoFromFiles
+	}
+	return nil
+}
+
+func (x *ProtoPackage) GetLicenseDeclared() string {
+	if x != nil {
+		return x.LicenseDeclared
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetLicenseComments() string {
+	if x != nil {
+		return x.LicenseComments
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetCopyrightText() string {
+	if x != nil {
+		return x.CopyrightText
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetSupplierPerson() string {
+	if x != nil {
+		return x.SupplierPerson
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetSupplierOrganization() string {
+	if x != nil {
+		return x.SupplierOrganization
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetOriginatorPerson() string {
+	if x != nil {
+		return x.OriginatorPerson
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetOriginatorOrganization() string {
+	if x != nil {
+		return x.OriginatorOrganization
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetChecksums() []*ProtoChecksum {
+	if x != nil {
+		return x.Checksums
+	}
+	return nil
+}
+
+func (x *ProtoPackage) GetExternalRefs() []*ProtoExternalRef {
+	if x != nil {
+		return x.ExternalRefs
+	}
+	return nil
+}
+
+func (x *ProtoPackage) GetArchiveFileName() string {
+	if x != nil {
+		return x.ArchiveFileName
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetHomePage() string {
+	if x != nil {
+		return x.HomePage
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetSourceInfo() string {
+	if x != nil {
+		return x.SourceInfo
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetAttributionText() []string {
+	if x != nil {
+		return x.AttributionText
+	}
+	return nil
+}
+
+func (x *ProtoPackage) GetDownloadLocationComment() string {
+	if x != nil {
+		return x.DownloadLocationComment
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetIsPrimary() bool {
+	if x != nil {
+		return x.IsPrimary
+	}
+	return false
+}
+
+func (x *ProtoPackage) GetSourceFile() string {
+	if x != nil {
+		return x.SourceFile
+	}
+	return ""
+}
+
+func (x *ProtoPackage) GetFileProvider() bool {
+	if x != nil {
+		return x.FileProvider
+	}
+	return false
+}
+
+type ProtoFile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id                string           `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name              string           `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	FileType          []string         `protobuf:"bytes,3,rep,name=file_type,json=fileType,proto3" json:"file_type,omitempty"`
+	LicenseConcluded  string           `protobuf:"bytes,4,opt,name=license_concluded,json=licenseConcluded,proto3" json:"license_concluded,omitempty"`
+	LicenseInfoInFile []string         `protobuf:"bytes,5,rep,name=license_info_in_file,json=licenseInfoInFile,proto3" json:"license_info_in_file,omitempty"`
+	CopyrightText     string           `protobuf:"bytes,6,opt,name=copyright_text,json=copyrightText,proto3" json:"copyright_text,omitempty"`
+	Comment           string           `protobuf:"bytes,7,opt,name=comment,proto3" json:"comment,omitempty"`
+	Checksums         []*ProtoChecksum `protobuf:"bytes,8,rep,name=checksums,proto3" json:"checksums,omitempty"`
+	FileName          string           `protobuf:"bytes,9,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	SourceFile        string           `protobuf:"bytes,10,opt,name=source_file,json=sourceFile,proto3" json:"source_file,omitempty"`
+	Size              int64            `protobuf:"varint,11,opt,name=size,proto3" json:"size,omitempty"`
+	Lines             int64            `protobuf:"varint,12,opt,name=lines,proto3" json:"lines,omitempty"`
+	AttributionText   []string         `protobuf:"bytes,13,rep,name=attribution_text,json=attributionText,proto3" json:"attribution_text,omitempty"`
+	Notice            string           `protobuf:"bytes,14,opt,name=notice,proto3" json:"notice,omitempty"`
+	GitBlobSha1       string           `protobuf:"bytes,15,opt,name=git_blob_sha1,json=gitBlobSha1,proto3" json:"git_blob_sha1,omitempty"`
+	Snippets          []*ProtoSnippet  `protobuf:"bytes,16,rep,name=snippets,proto3" json:"snippets,omitempty"`
+}
+
+func (x *ProtoFile) Reset() {
+	*x = ProtoFile{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_spdx_spdx_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProtoFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoFile) ProtoMessage() {}
+
+func (x *ProtoFile) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_spdx_spdx_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoFile.ProtoReflect.Descriptor instead.
+func (*ProtoFile) Descriptor() ([]byte, []int) {
+	return file_pkg_spdx_spdx_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProtoFile) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ProtoFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProtoFile) GetFileType() []string {
+	if x != nil {
+		return x.FileType
+	}
+	return nil
+}
+
+func (x *ProtoFile) GetLicenseConcluded() string {
+	if x != nil {
+		return x.LicenseConcluded
+	}
+	return ""
+}
+
+func (x *ProtoFile) GetLicenseInfoInFile() []string {
+	if x != nil {
+		return x.LicenseInfoInFile
+	}
+	return nil
+}
+
+func (x *ProtoFile) GetCopyrightText() string {
+	if x != nil {
+		return x.CopyrightText
+	}
+	return ""
+}
+
+func (x *ProtoFile) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *ProtoFile) GetChecksums() []*ProtoChecksum {
+	if x != nil {
+		return x.Checksums
+	}
+	return nil
+}
+
+func (x *ProtoFile) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *ProtoFile) GetSourceFile() string {
+	if x != nil {
+		return x.SourceFile
+	}
+	return ""
+}
+
+func (x *ProtoFile) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ProtoFile) GetLines() int64 {
+	if x != nil {
+		return x.Lines
+	}
+	return 0
+}
+
+func (x *ProtoFile) GetAttributionText() []string {
+	if x != nil {
+		return x.AttributionText
+	}
+	return nil
+}
+
+func (x *ProtoFile) GetNotice() string {
+	if x != nil {
+		return x.Notice
+	}
+	return ""
+}
+
+func (x *ProtoFile) GetGitBlobSha1() string {
+	if x != nil {
+		return x.GitBlobSha1
+	}
+	return ""
+}
+
+func (x *ProtoFile) GetSnippets() []*ProtoSnippet {
+	if x != nil {
+		return x.Snippets
+	}
+	return nil
+}
+
+type ProtoSnippet struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id                   string             `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	SnippetFromFile      string             `protobuf:"bytes,2,opt,name=snippet_from_file,json=snippetFromFile,proto3" json:"snippet_from_file,omitempty"`
+	ByteRange            *ProtoSnippetRange `protobuf:"bytes,3,opt,name=byte_range,json=byteRange,proto3" json:"byte_range,omitempty"`
+	LineRange            *ProtoSnippetRange `protobuf:"bytes,4,opt,name=line_range,json=lineRange,proto3" json:"line_range,omitempty"`
+	LicenseConcluded     string             `protobuf:"bytes,5,opt,name=license_concluded,json=licenseConcluded,proto3" json:"license_concluded,omitempty"`
+	LicenseInfoInSnippet []string           `protobuf:"bytes,6,rep,name=license_info_in_snippet,json=licenseInfoInSnippet,proto3" json:"license_info_in_snippet,omitempty"`
+	CopyrightText        string             `protobuf:"bytes,7,opt,name=copyright_text,json=copyrightText,proto3" json:"copyright_text,omitempty"`
+	Comment              string             `protobuf:"bytes,8,opt,name=comment,proto3" json:"comment,omitempty"`
+}
+
+func (x *ProtoSnippet) Reset() {
+	*x = ProtoSnippet{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_spdx_spdx_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProtoSnippet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoSnippet) ProtoMessage() {}
+
+func (x *ProtoSnippet) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_spdx_spdx_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoSnippet.ProtoReflect.Descriptor instead.
+func (*ProtoSnippet) Descriptor() ([]byte, []int) {
+	return file_pkg_spdx_spdx_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ProtoSnippet) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ProtoSnippet) GetSnippetFromFile() string {
+	if x != nil {
+		return x.SnippetFromFile
+	}
+	return ""
+}
+
+func (x *ProtoSnippet) GetByteRange() *ProtoSnippetRange {
+	if x != nil {
+		return x.ByteRange
+	}
+	return nil
+}
+
+func (x *ProtoSnippet) GetLineRange() *ProtoSnippetRange {
+	if x != nil {
+		return x.LineRange
+	}
+	return nil
+}
+
+func (x *ProtoSnippet) GetLicenseConcluded() string {
+	if x != nil {
+		return x.LicenseConcluded
+	}
+	return ""
+}
+
+func (x *ProtoSnippet) GetLicenseInfoInSnippet() []string {
+	if x != nil {
+		return x.LicenseInfoInSnippet
+	}
+	return nil
+}
+
+func (x *ProtoSnippet) GetCopyrightText() string {
+	if x != nil {
+		return x.CopyrightText
+	}
+	return ""
+}
+
+func (x *ProtoSnippet) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+type ProtoSnippetRange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Start int64 `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End   int64 `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+}
+
+func (x *ProtoSnippetRange) Reset() {
+	*x = ProtoSnippetRange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_spdx_spdx_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProtoSnippetRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoSnippetRange) ProtoMessage() {}
+
+func (x *ProtoSnippetRange) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_spdx_spdx_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoSnippetRange.ProtoReflect.Descriptor instead.
+func (*ProtoSnippetRange) Descriptor() ([]byte, []int) {
+	return file_pkg_spdx_spdx_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ProtoSnippetRange) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *ProtoSnippetRange) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+type ProtoChecksum struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Algorithm string `protobuf:"bytes,1,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	Value     string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *ProtoChecksum) Reset() {
+	*x = ProtoChecksum{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_spdx_spdx_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProtoChecksum) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoChecksum) ProtoMessage() {}
+
+func (x *ProtoChecksum) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_spdx_spdx_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoChecksum.ProtoReflect.Descriptor instead.
+func (*ProtoChecksum) Descriptor() ([]byte, []int) {
+	return file_pkg_spdx_spdx_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProtoChecksum) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *ProtoChecksum) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type ProtoExternalRef struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Category string `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	Type     string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Locator  string `protobuf:"bytes,3,opt,name=locator,proto3" json:"locator,omitempty"`
+}
+
+func (x *ProtoExternalRef) Reset() {
+	*x = ProtoExternalRef{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_spdx_spdx_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProtoExternalRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoExternalRef) ProtoMessage() {}
+
+func (x *ProtoExternalRef) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_spdx_spdx_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoExternalRef.ProtoReflect.Descriptor instead.
+func (*ProtoExternalRef) Descriptor() ([]byte, []int) {
+	return file_pkg_spdx_spdx_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ProtoExternalRef) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *ProtoExternalRef) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ProtoExternalRef) GetLocator() string {
+	if x != nil {
+		return x.Locator
+	}
+	return ""
+}
+
+// ProtoEdge is a relationship between two elements of the graph, such
+// as CONTAINS, DEPENDS_ON or GENERATED_FROM
+type ProtoEdge struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SourceId string `protobuf:"bytes,1,opt,name=source_id,json=sourceId,proto3" json:"source_id,omitempty"`
+	Type     string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	TargetId string `protobuf:"bytes,3,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`
+}
+
+func (x *ProtoEdge) Reset() {
+	*x = ProtoEdge{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_spdx_spdx_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProtoEdge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoEdge) ProtoMessage() {}
+
+func (x *ProtoEdge) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_spdx_spdx_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoEdge.ProtoReflect.Descriptor instead.
+func (*ProtoEdge) Descriptor() ([]byte, []int) {
+	return file_pkg_spdx_spdx_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ProtoEdge) GetSourceId() string {
+	if x != nil {
+		return x.SourceId
+	}
+	return ""
+}
+
+func (x *ProtoEdge) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ProtoEdge) GetTargetId() string {
+	if x != nil {
+		return x.TargetId
+	}
+	return ""
+}
+
+var File_pkg_spdx_spdx_proto protoreflect.FileDescriptor
+
+var file_pkg_spdx_spdx_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x70, 0x6b, 0x67, 0x2f, 0x73, 0x70, 0x64, 0x78, 0x2f, 0x73, 0x70, 0x64, 0x78, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x10, 0x6b, 0x38, 0x73, 0x2e, 0x72, 0x65, 0x6c, 0x65, 0x61,
+	0x73, 0x65, 0x2e, 0x73, 0x70, 0x64, 0x78, 0x22, 0xce, 0x01, 0x0a, 0x11, 0x50, 0x72, 0x6f, 0x74,
+	0x6f, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x47, 0x72, 0x61, 0x70, 0x68, 0x12, 0x17, 0x0a,
+	0x07, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x72, 0x6f, 0x6f, 0x74, 0x49, 0x64, 0x12, 0x3a, 0x0a, 0x08, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67,
+	0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x72,
+	0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x2e, 0x73, 0x70, 0x64, 0x78, 0x2e, 0x50, 0x72, 0x6f, 0x74,
+	0x6f, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x08, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67,
+	0x65, 0x73, 0x12, 0x31, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x1b, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x2e,
+	0x73, 0x70, 0x64, 0x78, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x05,
+	0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x31, 0x0a, 0x05, 0x65, 0x64, 0x67, 0x65, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x72, 0x65, 0x6c, 0x65, 0x61,
+	0x73, 0x65, 0x2e, 0x73, 0x70, 0x64, 0x78, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x45, 0x64, 0x67,
+	0x65, 0x52, 0x05, 0x65, 0x64, 0x67, 0x65, 0x73, 0x22, 0xb9, 0x09, 0x0a, 0x0c, 0x50, 0x72, 0x6f,
+	0x74, 0x6f, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65,
+	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64,
+	0x5f, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x10, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x5f, 0x61, 0x6e, 0x61, 0x6c, 0x79,
+	0x7a, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x66, 0x69, 0x6c, 0x65, 0x73,
+	0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x64, 0x12, 0x2b, 0x0a, 0x11, 0x76, 0x65, 0x72, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x10, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65,
+	0x5f, 0x63, 0x6f, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x10, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x43, 0x6f, 0x6e, 0x63, 0x6c, 0x75, 0x64,
+	0x65, 0x64, 0x12, 0x35, 0x0a, 0x17, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x5f, 0x69, 0x6e,
+	0x66, 0x6f, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x09, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x14, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x49, 0x6e, 0x66, 0x6f,
+	0x46, 0x72, 0x6f, 0x6d, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x6c, 0x69, 0x63,
+	0x65, 0x6e, 0x73, 0x65, 0x5f, 0x64, 0x65, 0x63, 0x6c, 0x61, 0x72, 0x65, 0x64, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0f, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x44, 0x65, 0x63, 0x6c,
+	0x61, 0x72, 0x65, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x5f,
+	0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f,
+	0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12,
+	0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x70, 0x79, 0x72, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x74, 0x65, 0x78,
+	0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6f, 0x70, 0x79, 0x72, 0x69, 0x67,
+	0x68, 0x74, 0x54, 0x65, 0x78, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x69,
+	0x65, 0x72, 0x5f, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0e, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x12,
+	0x33, 0x0a, 0x15, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x5f, 0x6f, 0x72, 0x67, 0x61,
+	0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14,
+	0x73, 0x75, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x4f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2b, 0x0a, 0x11, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x74,
+	0x6f, 0x72, 0x5f, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x10, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x65, 0x72, 0x73, 0x6f,
+	0x6e, 0x12, 0x37, 0x0a, 0x17, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x5f,
+	0x6f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x10, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x16, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x4f, 0x72,
+	0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3d, 0x0a, 0x09, 0x63, 0x68,
+	0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x18, 0x11, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e,
+	0x6b, 0x38, 0x73, 0x2e, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x2e, 0x73, 0x70, 0x64, 0x78,
+	0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x52, 0x09,
+	0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x12, 0x47, 0x0a, 0x0d, 0x65, 0x78, 0x74,
+	0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x72, 0x65, 0x66, 0x73, 0x18, 0x12, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x22, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x2e, 0x73,
+	0x70, 0x64, 0x78, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61,
+	0x6c, 0x52, 0x65, 0x66, 0x52, 0x0c, 0x65, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x52, 0x65,
+	0x66, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x5f, 0x66, 0x69,
+	0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x61,
+	0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x18, 0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73,
+	0x63, 0x6f, 0x70, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x68, 0x6f, 0x6d, 0x65, 0x5f, 0x70, 0x61, 0x67,
+	0x65, 0x18, 0x15, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x6d, 0x65, 0x50, 0x61, 0x67,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x16, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x64,
+	0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x17, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a,
+	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x18, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x5f, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x19, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x29, 0x0a, 0x10, 0x61, 0x74, 0x74, 0x72,
+	0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x65, 0x78, 0x74, 0x18, 0x1a, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0f, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x54,
+	0x65, 0x78, 0x74, 0x12, 0x3a, 0x0a, 0x19, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x5f,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74,
+	0x18, 0x1b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x17, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64,
+	0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x12,
+	0x1d, 0x0a, 0x0a, 0x69, 0x73, 0x5f, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x1c, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x09, 0x69, 0x73, 0x50, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x1f,
+	0x0a, 0x0b, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x1d, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x12,
+	0x23, 0x0a, 0x0d, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72,
+	0x18, 0x1e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x66, 0x69, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x76,
+	0x69, 0x64, 0x65, 0x72, 0x22, 0xb5, 0x04, 0x0a, 0x09, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x46, 0x69,
+	0x6c, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x5f, 0x63,
+	0x6f, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10,
+	0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x43, 0x6f, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64,
+	0x12, 0x2f, 0x0a, 0x14, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x5f, 0x69, 0x6e, 0x66, 0x6f,
+	0x5f, 0x69, 0x6e, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x11,
+	0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x49, 0x6e, 0x46, 0x69, 0x6c,
+	0x65, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x70, 0x79, 0x72, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x74,
+	0x65, 0x78, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6f, 0x70, 0x79, 0x72,
+	0x69, 0x67, 0x68, 0x74, 0x54, 0x65, 0x78, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d,
+	0x65, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x65,
+	0x6e, 0x74, 0x12, 0x3d, 0x0a, 0x09, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x18,
+	0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x72, 0x65, 0x6c, 0x65,
+	0x61, 0x73, 0x65, 0x2e, 0x73, 0x70, 0x64, 0x78, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x52, 0x09, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d,
+	0x73, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1f,
+	0x0a, 0x0b, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73,
+	0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x61, 0x74, 0x74,
+	0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x65, 0x78, 0x74, 0x18, 0x0d, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0f, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x54, 0x65, 0x78, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x6f, 0x74, 0x69, 0x63, 0x65, 0x18, 0x0e,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6e, 0x6f, 0x74, 0x69, 0x63, 0x65, 0x12, 0x22, 0x0a, 0x0d,
+	0x67, 0x69, 0x74, 0x5f, 0x62, 0x6c, 0x6f, 0x62, 0x5f, 0x73, 0x68, 0x61, 0x31, 0x18, 0x0f, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x67, 0x69, 0x74, 0x42, 0x6c, 0x6f, 0x62, 0x53, 0x68, 0x61, 0x31,
+	0x12, 0x3a, 0x0a, 0x08, 0x73, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74, 0x73, 0x18, 0x10, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65,
+	0x2e, 0x73, 0x70, 0x64, 0x78, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x53, 0x6e, 0x69, 0x70, 0x70,
+	0x65, 0x74, 0x52, 0x08, 0x73, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74, 0x73, 0x22, 0xf7, 0x02, 0x0a,
+	0x0c, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x53, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x2a, 0x0a,
+	0x11, 0x73, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x66, 0x69,
+	0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x73, 0x6e, 0x69, 0x70, 0x70, 0x65,
+	0x74, 0x46, 0x72, 0x6f, 0x6d, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x42, 0x0a, 0x0a, 0x62, 0x79, 0x74,
+	0x65, 0x5f, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e,
+	0x6b, 0x38, 0x73, 0x2e, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x2e, 0x73, 0x70, 0x64, 0x78,
+	0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x53, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74, 0x52, 0x61, 0x6e,
+	0x67, 0x65, 0x52, 0x09, 0x62, 0x79, 0x74, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x42, 0x0a,
+	0x0a, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x23, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x2e,
+	0x73, 0x70, 0x64, 0x78, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x53, 0x6e, 0x69, 0x70, 0x70, 0x65,
+	0x74, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x09, 0x6c, 0x69, 0x6e, 0x65, 0x52, 0x61, 0x6e, 0x67,
+	0x65, 0x12, 0x2b, 0x0a, 0x11, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x5f, 0x63, 0x6f, 0x6e,
+	0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x6c, 0x69,
+	0x63, 0x65, 0x6e, 0x73, 0x65, 0x43, 0x6f, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x12, 0x35,
+	0x0a, 0x17, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x5f, 0x69, 0x6e, 0x66, 0x6f, 0x5f, 0x69,
+	0x6e, 0x5f, 0x73, 0x6e, 0x69, 0x70, 0x70, 0x65, 0x74, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x14, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x49, 0x6e, 0x53, 0x6e,
+	0x69, 0x70, 0x70, 0x65, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x70, 0x79, 0x72, 0x69, 0x67,
+	0x68, 0x74, 0x5f, 0x74, 0x65, 0x78, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63,
+	0x6f, 0x70, 0x79, 0x72, 0x69, 0x67, 0x68, 0x74, 0x54, 0x65, 0x78, 0x74, 0x12, 0x18, 0x0a, 0x07,
+	0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63,
+	0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x3b, 0x0a, 0x11, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x53,
+	0x6e, 0x69, 0x70, 0x70, 0x65, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03,
+	0x65, 0x6e, 0x64, 0x22, 0x43, 0x0a, 0x0d, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x73, 0x75, 0x6d, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68,
+	0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74,
+	0x68, 0x6d, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x5c, 0x0a, 0x10, 0x50, 0x72, 0x6f, 0x74,
+	0x6f, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x66, 0x12, 0x1a, 0x0a, 0x08,
+	0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6c,
+	0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x22, 0x59, 0x0a, 0x09, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x45,
+	0x64, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x64,
+	0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x5f, 0x69,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x49,
+	0x64, 0x42, 0x19, 0x5a, 0x17, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2f, 0x72, 0x65, 0x6c, 0x65,
+	0x61, 0x73, 0x65, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x73, 0x70, 0x64, 0x78, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pkg_spdx_spdx_proto_rawDescOnce sync.Once
+	file_pkg_spdx_spdx_proto_rawDescData = file_pkg_spdx_spdx_proto_rawDesc
+)
+
+func file_pkg_spdx_spdx_proto_rawDescGZIP() []byte {
+	file_pkg_spdx_spdx_proto_rawDescOnce.Do(func() {
+		file_pkg_spdx_spdx_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_spdx_spdx_proto_rawDescData)
+	})
+	return file_pkg_spdx_spdx_proto_rawDescData
+}
+
+var file_pkg_spdx_spdx_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_pkg_spdx_spdx_proto_goTypes = []interface{}{
+	(*ProtoPackageGraph)(nil), // 0: k8s.release.spdx.ProtoPackageGraph
+	(*ProtoPackage)(nil),      // 1: k8s.release.spdx.ProtoPackage
+	(*ProtoFile)(nil),         // 2: k8s.release.spdx.ProtoFile
+	(*ProtoSnippet)(nil),      // 3: k8s.release.spdx.ProtoSnippet
+	(*ProtoSnippetRange)(nil), // 4: k8s.release.spdx.ProtoSnippetRange
+	(*ProtoChecksum)(nil),     // 5: k8s.release.spdx.ProtoChecksum
+	(*ProtoExternalRef)(nil),  // 6: k8s.release.spdx.ProtoExternalRef
+	(*ProtoEdge)(nil),         // 7: k8s.release.spdx.ProtoEdge
+}
+var file_pkg_spdx_spdx_proto_depIdxs = []int32{
+	1, // 0: k8s.release.spdx.ProtoPackageGraph.packages:type_name -> k8s.release.spdx.ProtoPackage
+	2, // 1: k8s.release.spdx.ProtoPackageGraph.files:type_name -> k8s.release.spdx.ProtoFile
+	7, // 2: k8s.release.spdx.ProtoPackageGraph.edges:type_name -> k8s.release.spdx.ProtoEdge
+	5, // 3: k8s.release.spdx.ProtoPackage.checksums:type_name -> k8s.release.spdx.ProtoChecksum
+	6, // 4: k8s.release.spdx.ProtoPackage.external_refs:type_name -> k8s.release.spdx.ProtoExternalRef
+	5, // 5: k8s.release.spdx.ProtoFile.checksums:type_name -> k8s.release.spdx.ProtoChecksum
+	3, // 6: k8s.release.spdx.ProtoFile.snippets:type_name -> k8s.release.spdx.ProtoSnippet
+	4, // 7: k8s.release.spdx.ProtoSnippet.byte_range:type_name -> k8s.release.spdx.ProtoSnippetRange
+	4, // 8: k8s.release.spdx.ProtoSnippet.line_range:type_name -> k8s.release.spdx.ProtoSnippetRange
+	9, // [9:9] is the sub-list for method output_type
+	9, // [9:9] is the sub-list for method input_type
+	9, // [9:9] is the sub-list for extension type_name
+	9, // [9:9] is the sub-list for extension extendee
+	0, // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_pkg_spdx_spdx_proto_init() }
+func file_pkg_spdx_spdx_proto_init() {
+	if File_pkg_spdx_spdx_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_spdx_spdx_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProtoPackageGraph); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_spdx_spdx_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProtoPackage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_spdx_spdx_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProtoFile); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_spdx_spdx_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProtoSnippet); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_spdx_spdx_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProtoSnippetRange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_spdx_spdx_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProtoChecksum); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_spdx_spdx_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProtoExternalRef); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_spdx_spdx_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProtoEdge); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pkg_spdx_spdx_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_pkg_spdx_spdx_proto_goTypes,
+		DependencyIndexes: file_pkg_spdx_spdx_proto_depIdxs,
+		MessageInfos:      file_pkg_spdx_spdx_proto_msgTypes,
+	}.Build()
+	File_pkg_spdx_spdx_proto = out.File
+	file_pkg_spdx_spdx_proto_rawDesc = nil
+	file_pkg_spdx_spdx_proto_goTypes = nil
+	file_pkg_spdx_spdx_proto_depIdxs = nil
+}
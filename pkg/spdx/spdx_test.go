@@ -17,10 +17,15 @@ limitations under the License.
 package spdx_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	gitignore "github.com/go-git/go-git/v5/plumbing/format/gitignore"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+	"k8s.io/release/pkg/license"
+	"k8s.io/release/pkg/license/licensefakes"
 	"k8s.io/release/pkg/spdx"
 	"k8s.io/release/pkg/spdx/spdxfakes"
 )
@@ -149,3 +154,65 @@ func TestPullImagesToArchive(t *testing.T) {
 		}
 	}
 }
+
+func TestPackageFromDirectory(t *testing.T) {
+	tmp, err := os.MkdirTemp("", "spdx-package-dir-")
+	require.Nil(t, err)
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "project")
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "sub"), os.FileMode(0o755)))
+	for name, content := range map[string]string{
+		"main.go":     "package main\n",
+		"sub/lib.go":  "package sub\n",
+		"build.log":   "ignored\n",
+		"sub/NOTICE":  "Copyright\n",
+		"sub/lib2.go": "package sub\n",
+	} {
+		require.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(content), os.FileMode(0o644)))
+	}
+	require.Nil(t, os.WriteFile(filepath.Join(tmp, "outside"), []byte("secret\n"), os.FileMode(0o644)))
+	require.Nil(t, os.Symlink(filepath.Join(tmp, "outside"), filepath.Join(dir, "outside-link")))
+	require.Nil(t, os.Symlink("lib.go", filepath.Join(dir, "sub", "lib-link.go")))
+
+	newSUT := func() *spdx.SPDX {
+		reader := &license.Reader{}
+		readerImpl := &licensefakes.FakeReaderImplementation{}
+		readerImpl.LicenseFromFileReturns(&license.License{LicenseID: "Apache-2.0"}, nil)
+		require.Nil(t, reader.SetImplementation(readerImpl))
+		mock := &spdxfakes.FakeSpdxImplementation{}
+		mock.LicenseReaderReturns(reader, nil)
+		mock.GetDirectoryLicenseReturns(&license.License{LicenseID: "Apache-2.0"}, nil)
+		mock.IgnorePatternsReturns([]gitignore.Pattern{gitignore.ParsePattern("*.log", nil)}, nil)
+		sut := spdx.NewSPDX()
+		sut.Options().ProcessGoModules = false
+		sut.SetImplementation(mock)
+		return sut
+	}
+	names := func(pkg *spdx.Package) []string {
+		list := []string{}
+		for _, f := range pkg.SortedFiles() {
+			list = append(list, f.Name)
+		}
+		return list
+	}
+
+	// Ignored files and symlinks are skipped by default
+	pkg, err := newSUT().PackageFromDirectory(dir)
+	require.Nil(t, err)
+	require.Equal(t, "project", pkg.Name)
+	require.True(t, pkg.FilesAnalyzed)
+	require.ElementsMatch(t, []string{"./main.go", "./sub/NOTICE", "./sub/lib.go", "./sub/lib2.go"}, names(pkg))
+	for _, f := range pkg.Files {
+		require.Equal(t, []string{"Apache-2.0"}, f.LicenseInfoInFile)
+	}
+
+	// Links are followed only inside the directory
+	sut := newSUT()
+	sut.Options().SymlinkPolicy = spdx.SymlinkFollowWithinRoot
+	sut.Options().Concurrency = 3
+	pkg, err = sut.PackageFromDirectory(dir)
+	require.Nil(t, err)
+	require.ElementsMatch(t, []string{
+		"./main.go", "./sub/NOTICE", "./sub/lib-link.go", "./sub/lib.go", "./sub/lib2.go",
+	}, names(pkg))
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package spdx
 
 import (
+	"archive/tar"
 	"bytes"
 	"compress/gzip"
 	"encoding/base64"
@@ -43,8 +44,47 @@ func TestUnitExtractTarballTmp(t *testing.T) {
 	require.True(t, util.Exists(filepath.Join(dir, "/text.txt")), "checking directory")
 	require.True(t, util.Exists(filepath.Join(dir, "/subdir/text.txt")), "checking subdirectory")
 	require.True(t, util.Exists(dir), "checking directory")
+}
+
+func TestUnitExtractTarballTmpTraversal(t *testing.T) {
+	sut := NewSPDX()
+	// Entries can not be written outside of the extraction directory
+	writeTar := func(headers ...*tar.Header) string {
+		f, err := os.CreateTemp("", "spdx-extract-*.tar")
+		require.Nil(t, err)
+		defer f.Close()
+		tw := tar.NewWriter(f)
+		for _, hdr := range headers {
+			require.Nil(t, tw.WriteHeader(hdr))
+			if hdr.Typeflag == tar.TypeReg {
+				_, err := tw.Write(make([]byte, hdr.Size))
+				require.Nil(t, err)
+			}
+		}
+		require.Nil(t, tw.Close())
+		return f.Name()
+	}
+	escape := writeTar(&tar.Header{Name: "../../spdx-escaped.txt", Typeflag: tar.TypeReg, Size: 4, Mode: 0o644})
+	defer os.Remove(escape)
+	dir, err := sut.ExtractTarballTmp(escape)
+	defer os.RemoveAll(dir)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "outside of the extraction directory")
+	require.False(t, util.Exists(filepath.Join(dir, "..", "..", "spdx-escaped.txt")))
 
-	// Check files
+	// Links are not extracted, so files can not be written through them
+	links := writeTar(
+		&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: os.TempDir(), Mode: 0o777},
+		&tar.Header{Name: "link/spdx-through-link.txt", Typeflag: tar.TypeReg, Size: 4, Mode: 0o644},
+	)
+	defer os.Remove(links)
+	dir, err = sut.ExtractTarballTmp(links)
+	defer os.RemoveAll(dir)
+	require.Nil(t, err)
+	info, err := os.Lstat(filepath.Join(dir, "link"))
+	require.Nil(t, err)
+	require.True(t, info.IsDir())
+	require.False(t, util.Exists(filepath.Join(os.TempDir(), "spdx-through-link.txt")))
 }
 
 func TestReadArchiveManifest(t *testing.T) {
@@ -0,0 +1,637 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// The following types mirror the SPDX 2.2/2.3 JSON schema
+type spdxJSONDocument struct {
+	SPDXVersion       string                 `json:"spdxVersion"`
+	DataLicense       string                 `json:"dataLicense"`
+	ID                string                 `json:"SPDXID"`
+	Name              string                 `json:"name"`
+	Namespace         string                 `json:"documentNamespace,omitempty"`
+	CreationInfo      spdxJSONCreationInfo   `json:"creationInfo"`
+	DocumentDescribes []string               `json:"documentDescribes,omitempty"`
+	Packages          []*spdxJSONPackage     `json:"packages,omitempty"`
+	Files             []*spdxJSONFile        `json:"files,omitempty"`
+	Snippets          []*spdxJSONSnippet     `json:"snippets,omitempty"`
+	Relationships     []spdxJSONRelationship `json:"relationships,omitempty"`
+
+	ExternalDocumentRefs []spdxJSONExternalDocumentRef `json:"externalDocumentRefs,omitempty"`
+}
+
+type spdxJSONExternalDocumentRef struct {
+	ID       string           `json:"externalDocumentId"`
+	Checksum spdxJSONChecksum `json:"checksum"`
+	Document string           `json:"spdxDocument"`
+}
+
+type spdxJSONCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+	Comment  string   `json:"comment,omitempty"`
+}
+
+type spdxJSONPackage struct {
+	Name                 string                    `json:"name"`
+	ID                   string                    `json:"SPDXID"`
+	Version              string                    `json:"versionInfo,omitempty"`
+	FileName             string                    `json:"packageFileName,omitempty"`
+	Supplier             string                    `json:"supplier,omitempty"`
+	Originator           string                    `json:"originator,omitempty"`
+	DownloadLocation     string                    `json:"downloadLocation"`
+	HomePage             string                    `json:"homepage,omitempty"`
+	FilesAnalyzed        *bool                     `json:"filesAnalyzed,omitempty"`
+	VerificationCode     *spdxJSONVerificationCode `json:"packageVerificationCode,omitempty"`
+	Checksums            []spdxJSONChecksum        `json:"checksums,omitempty"`
+	LicenseConcluded     string                    `json:"licenseConcluded"`
+	LicenseInfoFromFiles []string                  `json:"licenseInfoFromFiles,omitempty"`
+	LicenseDeclared      string                    `json:"licenseDeclared"`
+	LicenseComments      string                    `json:"licenseComments,omitempty"`
+	CopyrightText        string                    `json:"copyrightText"`
+	Summary              string                    `json:"summary,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Comment              string                    `json:"comment,omitempty"`
+	SourceInfo           string                    `json:"sourceInfo,omitempty"`
+	AttributionText      []string                  `json:"attributionTexts,omitempty"`
+	ExternalRefs         []spdxJSONExternalRef     `json:"externalRefs,omitempty"`
+	HasFiles             []string                  `json:"hasFiles,omitempty"`
+}
+
+type spdxJSONVerificationCode struct {
+	Value string `json:"packageVerificationCodeValue"`
+}
+
+type spdxJSONFile struct {
+	Name              string             `json:"fileName"`
+	ID                string             `json:"SPDXID"`
+	FileTypes         []string           `json:"fileTypes,omitempty"`
+	Checksums         []spdxJSONChecksum `json:"checksums"`
+	LicenseConcluded  string             `json:"licenseConcluded"`
+	LicenseInfoInFile []string           `json:"licenseInfoInFiles,omitempty"`
+	CopyrightText     string             `json:"copyrightText"`
+	Comment           string             `json:"comment,omitempty"`
+	AttributionText   []string           `json:"attributionTexts,omitempty"`
+	Notice            string             `json:"noticeText,omitempty"`
+}
+
+type spdxJSONSnippet struct {
+	ID                   string          `json:"SPDXID"`
+	SnippetFromFile      string          `json:"snippetFromFile"`
+	Ranges               []spdxJSONRange `json:"ranges"`
+	LicenseConcluded     string          `json:"licenseConcluded"`
+	LicenseInfoInSnippet []string        `json:"licenseInfoInSnippets,omitempty"`
+	CopyrightText        string          `json:"copyrightText"`
+	Comment              string          `json:"comment,omitempty"`
+}
+
+// spdxJSONRange is a byte or line range, its pointers set the offset
+// or the line number respectively
+type spdxJSONRange struct {
+	Start spdxJSONPointer `json:"startPointer"`
+	End   spdxJSONPointer `json:"endPointer"`
+}
+
+type spdxJSONPointer struct {
+	Reference  string `json:"reference"`
+	Offset     int    `json:"offset,omitempty"`
+	LineNumber int    `json:"lineNumber,omitempty"`
+}
+
+type spdxJSONChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+type spdxJSONExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type spdxJSONRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+// RenderJSON renders the document in the SPDX JSON format
+func (d *Document) RenderJSON() ([]byte, error) {
+	if err := d.checkDataLicense(); err != nil {
+		return nil, err
+	}
+	if err := d.checkExternalDocumentRefs(); err != nil {
+		return nil, err
+	}
+	described, err := d.describedIDs()
+	if err != nil {
+		return nil, err
+	}
+	doc := &spdxJSONDocument{
+		SPDXVersion: d.Version,
+		DataLicense: d.DataLicense,
+		ID:          d.ID,
+		Name:        d.Name,
+		Namespace:   d.Namespace,
+		CreationInfo: spdxJSONCreationInfo{
+			Created:  d.creationTime().Format(spdxTimeFormat),
+			Creators: []string{},
+			Comment:  d.CreatorComment,
+		},
+	}
+	if doc.DataLicense == "" {
+		doc.DataLicense = DataLicenseCC0
+	}
+	if d.Creator.Person != "" {
+		doc.CreationInfo.Creators = append(doc.CreationInfo.Creators, "Person: "+d.Creator.Person)
+	}
+	for _, tool := range d.Creator.Tool {
+		doc.CreationInfo.Creators = append(doc.CreationInfo.Creators, "Tool: "+tool)
+	}
+	for _, ref := range d.ExternalDocumentRefs {
+		doc.ExternalDocumentRefs = append(doc.ExternalDocumentRefs, spdxJSONExternalDocumentRef{
+			ID:       ref.ID,
+			Checksum: spdxJSONChecksum{"SHA1", ref.Checksum["SHA1"]},
+			Document: ref.URI,
+		})
+	}
+
+	// Elements and relationships are collected walking the tree the
+	// same way the tag-value renderer does
+	state := newRenderState()
+	state.created = d.creationTime()
+	b := &spdxJSONBuilder{doc: doc, packages: map[string]*spdxJSONPackage{}, files: map[string]struct{}{}}
+	for _, id := range sortedFileIDs(d.Files) {
+		if err := walkFile(state, d.Files[id], b); err != nil {
+			return nil, errors.Wrapf(err, "rendering file %s", id)
+		}
+		if _, ok := described[id]; ok {
+			doc.DocumentDescribes = append(doc.DocumentDescribes, id)
+			doc.Relationships = append(doc.Relationships, spdxJSONRelationship{d.ID, RelationshipDescribes, id})
+		}
+	}
+
+	for _, id := range sortedPackageIDs(d.Packages) {
+		pkg := d.Packages[id]
+		state.maxDepth = pkg.maxDepth()
+		if err := pkg.walk(state, b); err != nil {
+			return nil, errors.Wrapf(err, "rendering package %s", id)
+		}
+		if _, ok := described[id]; ok {
+			doc.DocumentDescribes = append(doc.DocumentDescribes, id)
+			doc.Relationships = append(doc.Relationships, spdxJSONRelationship{d.ID, RelationshipDescribes, id})
+		}
+	}
+	for _, id := range d.externalDescribedIDs() {
+		doc.DocumentDescribes = append(doc.DocumentDescribes, id)
+		doc.Relationships = append(doc.Relationships, spdxJSONRelationship{d.ID, RelationshipDescribes, id})
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling SPDX JSON document")
+	}
+	return data, nil
+}
+
+// spdxJSONBuilder adds the visited elements to the flat lists of the
+// SPDX JSON document
+type spdxJSONBuilder struct {
+	doc      *spdxJSONDocument
+	packages map[string]*spdxJSONPackage
+	files    map[string]struct{}
+}
+
+func (b *spdxJSONBuilder) visitPackage(p *Package, view *packageView) error {
+	jp := spdxJSONPackageFrom(view)
+	b.packages[p.ID] = jp
+	b.doc.Packages = append(b.doc.Packages, jp)
+	return nil
+}
+
+func (b *spdxJSONBuilder) visitFile(f *File) error {
+	b.files[f.ID] = struct{}{}
+	b.doc.Files = append(b.doc.Files, spdxJSONFileFrom(f))
+	snippets, err := f.snippetViews()
+	if err != nil {
+		return errors.Wrap(err, "rendering file snippets")
+	}
+	for _, s := range snippets {
+		b.doc.Snippets = append(b.doc.Snippets, spdxJSONSnippetFrom(s))
+	}
+	return nil
+}
+
+func (b *spdxJSONBuilder) visitRelationship(element, relType, related string) error {
+	b.doc.Relationships = append(b.doc.Relationships, spdxJSONRelationship{element, relType, related})
+
+	// Files contained in a package are listed in its hasFiles too
+	if jp, ok := b.packages[element]; ok && relType == RelationshipContains {
+		if _, ok := b.files[related]; ok {
+			jp.HasFiles = append(jp.HasFiles, related)
+		}
+	}
+	return nil
+}
+
+func spdxJSONPackageFrom(view *packageView) *spdxJSONPackage {
+	jp := &spdxJSONPackage{
+		Name:                 view.Name,
+		ID:                   view.ID,
+		Version:              view.Version,
+		FileName:             view.ArchiveFileName,
+		Supplier:             spdxParty(view.Supplier.Person, view.Supplier.Organization),
+		Originator:           spdxParty(view.Originator.Person, view.Originator.Organization),
+		DownloadLocation:     valueOr(view.DownloadLocation, NONE),
+		FilesAnalyzed:        &view.FilesAnalyzed,
+		Checksums:            spdxJSONChecksums(view.Checksum),
+		LicenseConcluded:     valueOr(view.LicenseConcluded, NOASSERTION),
+		LicenseInfoFromFiles: view.LicenseInfoFromFiles,
+		LicenseDeclared:      valueOr(view.LicenseDeclared, NOASSERTION),
+		LicenseComments:      view.LicenseComments,
+		CopyrightText:        valueOr(view.CopyrightText, NOASSERTION),
+		Summary:              view.Summary,
+		Description:          view.Description,
+		Comment:              view.Comment,
+		SourceInfo:           view.SourceInfo,
+		HomePage:             view.HomePage,
+		AttributionText:      view.AttributionText,
+	}
+	if view.VerificationCode != "" {
+		jp.VerificationCode = &spdxJSONVerificationCode{Value: view.VerificationCode}
+	}
+	for _, ref := range view.ExternalRefs {
+		jp.ExternalRefs = append(jp.ExternalRefs, spdxJSONExternalRef(ref))
+	}
+	return jp
+}
+
+func spdxJSONFileFrom(f *File) *spdxJSONFile {
+	jf := &spdxJSONFile{
+		Name:             f.Name,
+		ID:               f.ID,
+		FileTypes:        f.FileType,
+		Checksums:        spdxJSONChecksums(f.Checksum),
+		LicenseConcluded: valueOr(f.LicenseConcluded, NOASSERTION),
+		CopyrightText:    valueOr(f.CopyrightText, NOASSERTION),
+		Comment:          f.renderComment(),
+		AttributionText:  f.AttributionText,
+		Notice:           f.Notice,
+	}
+	jf.LicenseInfoInFile = f.LicenseInfoInFile
+	if len(jf.LicenseInfoInFile) == 0 {
+		jf.LicenseInfoInFile = []string{NOASSERTION}
+	}
+	return jf
+}
+
+func spdxJSONSnippetFrom(s *Snippet) *spdxJSONSnippet {
+	js := &spdxJSONSnippet{
+		ID:              s.ID,
+		SnippetFromFile: s.SnippetFromFile,
+		Ranges: []spdxJSONRange{{
+			Start: spdxJSONPointer{Reference: s.SnippetFromFile, Offset: s.ByteRange.Start},
+			End:   spdxJSONPointer{Reference: s.SnippetFromFile, Offset: s.ByteRange.End},
+		}},
+		LicenseConcluded:     valueOr(s.LicenseConcluded, NOASSERTION),
+		LicenseInfoInSnippet: s.LicenseInfoInSnippet,
+		CopyrightText:        valueOr(s.CopyrightText, NOASSERTION),
+		Comment:              s.Comment,
+	}
+	if !s.LineRange.IsZero() {
+		js.Ranges = append(js.Ranges, spdxJSONRange{
+			Start: spdxJSONPointer{Reference: s.SnippetFromFile, LineNumber: s.LineRange.Start},
+			End:   spdxJSONPointer{Reference: s.SnippetFromFile, LineNumber: s.LineRange.End},
+		})
+	}
+	return js
+}
+
+// spdxParty formats a supplier or originator as written in both SPDX
+// formats. NOASSERTION in either field marks the party as unknown.
+func spdxParty(person, organization string) string {
+	if person == NOASSERTION || organization == NOASSERTION {
+		return NOASSERTION
+	}
+	if person != "" {
+		return "Person: " + person
+	}
+	if organization != "" {
+		return "Organization: " + organization
+	}
+	return ""
+}
+
+// spdxJSONChecksums returns the checksums in the rendering order
+func spdxJSONChecksums(checksums map[string]string) []spdxJSONChecksum {
+	list := []spdxJSONChecksum{}
+	for _, algorithm := range sortedChecksumAlgorithms(checksums) {
+		list = append(list, spdxJSONChecksum{Algorithm: algorithm, Value: checksums[algorithm]})
+	}
+	return list
+}
+
+// valueOr returns value or def if value is empty
+func valueOr(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// ParseJSON reads an SPDX document in the JSON format. The trees of
+// packages are rebuilt from the relationships in the document.
+func ParseJSON(r io.Reader) (*Document, error) {
+	doc := &spdxJSONDocument{}
+	if err := json.NewDecoder(r).Decode(doc); err != nil {
+		return nil, errors.Wrap(err, "decoding SPDX JSON document")
+	}
+
+	d := &Document{
+		Version:     doc.SPDXVersion,
+		DataLicense: doc.DataLicense,
+		ID:          doc.ID,
+		Name:        doc.Name,
+		Namespace:   doc.Namespace,
+	}
+	if doc.CreationInfo.Created != "" {
+		created, err := time.Parse(time.RFC3339, doc.CreationInfo.Created)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing document creation date")
+		}
+		d.Created = created
+	}
+	d.CreatorComment = doc.CreationInfo.Comment
+	for _, creator := range doc.CreationInfo.Creators {
+		switch {
+		case strings.HasPrefix(creator, "Person: "):
+			d.Creator.Person = strings.TrimPrefix(creator, "Person: ")
+		case strings.HasPrefix(creator, "Tool: "):
+			d.Creator.Tool = append(d.Creator.Tool, strings.TrimPrefix(creator, "Tool: "))
+		}
+	}
+	for _, ref := range doc.ExternalDocumentRefs {
+		d.ExternalDocumentRefs = append(d.ExternalDocumentRefs, ExternalDocumentRef{
+			ID:       ref.ID,
+			URI:      ref.Document,
+			Checksum: map[string]string{ref.Checksum.Algorithm: strings.ToLower(ref.Checksum.Value)},
+		})
+	}
+
+	packages := map[string]*Package{}
+	files := map[string]*File{}
+	for _, jf := range doc.Files {
+		files[jf.ID] = fileFromSPDXJSON(jf)
+	}
+	for _, js := range doc.Snippets {
+		f, ok := files[js.SnippetFromFile]
+		if !ok {
+			return nil, errors.Errorf("snippet %s is from unknown file %s", js.ID, js.SnippetFromFile)
+		}
+		f.Snippets = append(f.Snippets, snippetFromSPDXJSON(js))
+	}
+	relationships := doc.Relationships
+	for _, jp := range doc.Packages {
+		packages[jp.ID] = packageFromSPDXJSON(jp)
+		// Files may be listed in the package instead of as relationships
+		for _, id := range jp.HasFiles {
+			relationships = append(relationships, spdxJSONRelationship{jp.ID, RelationshipContains, id})
+		}
+	}
+	for _, id := range doc.DocumentDescribes {
+		relationships = append(relationships, spdxJSONRelationship{doc.ID, RelationshipDescribes, id})
+	}
+
+	if err := d.linkParsedElements(packages, files, relationships); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// linkParsedElements rebuilds the trees of packages and files read from
+// a document from their relationships
+func (d *Document) linkParsedElements(
+	packages map[string]*Package, files map[string]*File, relationships []spdxJSONRelationship,
+) error {
+	seen := map[spdxJSONRelationship]struct{}{}
+	contained := map[string]struct{}{}
+	for _, rel := range relationships {
+		if _, ok := seen[rel]; ok {
+			continue
+		}
+		seen[rel] = struct{}{}
+		if err := d.addParsedRelationship(packages, files, rel); err != nil {
+			return errors.Wrapf(
+				err, "adding %s relationship from %s to %s", rel.Type, rel.Element, rel.Related,
+			)
+		}
+		if _, ok := scopeFromRelationship(rel.Type); ok {
+			contained[rel.Element] = struct{}{}
+		} else if rel.Element != d.ID {
+			contained[rel.Related] = struct{}{}
+		}
+	}
+
+	// Documents that do not say what they describe get all the
+	// top level packages
+	if len(d.Packages) == 0 && len(d.Files) == 0 {
+		for id, pkg := range packages {
+			if _, ok := contained[id]; !ok {
+				if err := d.AddPackage(pkg); err != nil {
+					return errors.Wrap(err, "adding package to document")
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addParsedRelationship links the elements of a parsed relationship
+func (d *Document) addParsedRelationship(
+	packages map[string]*Package, files map[string]*File, rel spdxJSONRelationship,
+) error {
+	if rel.Element == d.ID && rel.Type == RelationshipDescribes && d.addExternalDescribed(rel.Related) {
+		return nil
+	}
+	targetPackage, targetFile := packages[rel.Related], files[rel.Related]
+	if targetPackage == nil && targetFile == nil {
+		logrus.Warnf("Skipping relationship to unknown element %s", rel.Related)
+		return nil
+	}
+
+	if rel.Element == d.ID {
+		if rel.Type != RelationshipDescribes {
+			return nil
+		}
+		if targetFile != nil {
+			return d.AddFile(targetFile)
+		}
+		if d.Packages == nil {
+			d.Packages = map[string]*Package{}
+		}
+		d.Packages[targetPackage.ID] = targetPackage
+		return nil
+	}
+
+	// Scoped dependencies point from the dependency to its dependent
+	if scope, ok := scopeFromRelationship(rel.Type); ok && targetPackage != nil {
+		if dep, ok := packages[rel.Element]; ok {
+			dep.Scope = scope
+			return targetPackage.AddDependency(dep)
+		}
+	}
+
+	r := &Relationship{Type: rel.Type, Package: targetPackage, File: targetFile}
+	if f, ok := files[rel.Element]; ok {
+		return f.AddRelationship(r)
+	}
+	pkg, ok := packages[rel.Element]
+	if !ok {
+		logrus.Warnf("Skipping relationship from unknown element %s", rel.Element)
+		return nil
+	}
+	switch {
+	case rel.Type == RelationshipContains && targetFile != nil:
+		return pkg.AddFile(targetFile)
+	case rel.Type == RelationshipContains:
+		return pkg.AddPackage(targetPackage)
+	case rel.Type == RelationshipDependsOn && targetPackage != nil:
+		return pkg.AddDependency(targetPackage)
+	}
+	return pkg.AddRelationship(r)
+}
+
+func packageFromSPDXJSON(jp *spdxJSONPackage) *Package {
+	p := NewPackage()
+	p.Name = jp.Name
+	p.ID = jp.ID
+	p.Version = jp.Version
+	p.FileName = jp.FileName
+	p.ArchiveFileName = jp.FileName
+	p.Supplier.Person, p.Supplier.Organization = partyFromSPDXJSON(jp.Supplier)
+	p.Originator.Person, p.Originator.Organization = partyFromSPDXJSON(jp.Originator)
+	if jp.DownloadLocation != NONE {
+		p.DownloadLocation = jp.DownloadLocation
+	}
+	// Files are analyzed unless stated otherwise
+	p.FilesAnalyzed = jp.FilesAnalyzed == nil || *jp.FilesAnalyzed
+	if jp.VerificationCode != nil {
+		p.VerificationCode = jp.VerificationCode.Value
+	}
+	p.Checksum = checksumsFromSPDXJSON(jp.Checksums)
+	p.LicenseConcluded = normalizeLicenseSentinel(jp.LicenseConcluded)
+	for _, l := range jp.LicenseInfoFromFiles {
+		if l != NONE && l != NOASSERTION {
+			p.LicenseInfoFromFiles = append(p.LicenseInfoFromFiles, l)
+		}
+	}
+	p.LicenseDeclared = normalizeLicenseSentinel(jp.LicenseDeclared)
+	p.LicenseComments = jp.LicenseComments
+	if jp.CopyrightText != NOASSERTION {
+		p.CopyrightText = jp.CopyrightText
+	}
+	p.Summary = jp.Summary
+	p.SourceInfo = jp.SourceInfo
+	p.HomePage = jp.HomePage
+	p.Description = jp.Description
+	p.Comment = jp.Comment
+	p.AttributionText = jp.AttributionText
+	for _, ref := range jp.ExternalRefs {
+		p.ExternalRefs = append(p.ExternalRefs, ExternalRef(ref))
+	}
+	return p
+}
+
+func fileFromSPDXJSON(jf *spdxJSONFile) *File {
+	f := NewFile()
+	f.Name = jf.Name
+	f.ID = jf.ID
+	f.FileType = jf.FileTypes
+	f.Checksum = checksumsFromSPDXJSON(jf.Checksums)
+	f.LicenseConcluded = normalizeLicenseSentinel(jf.LicenseConcluded)
+	for _, l := range jf.LicenseInfoInFile {
+		if l != NOASSERTION {
+			f.LicenseInfoInFile = append(f.LicenseInfoInFile, l)
+		}
+	}
+	if jf.CopyrightText != NOASSERTION {
+		f.CopyrightText = jf.CopyrightText
+	}
+	f.setParsedComment(jf.Comment)
+	f.AttributionText = jf.AttributionText
+	f.Notice = jf.Notice
+	return f
+}
+
+func snippetFromSPDXJSON(js *spdxJSONSnippet) *Snippet {
+	s := &Snippet{
+		ID:              js.ID,
+		SnippetFromFile: js.SnippetFromFile,
+		Comment:         js.Comment,
+	}
+	for _, r := range js.Ranges {
+		if r.Start.LineNumber != 0 {
+			s.LineRange = SnippetRange{Start: r.Start.LineNumber, End: r.End.LineNumber}
+		} else {
+			s.ByteRange = SnippetRange{Start: r.Start.Offset, End: r.End.Offset}
+		}
+	}
+	s.LicenseConcluded = normalizeLicenseSentinel(js.LicenseConcluded)
+	for _, l := range js.LicenseInfoInSnippet {
+		if l != NOASSERTION {
+			s.LicenseInfoInSnippet = append(s.LicenseInfoInSnippet, l)
+		}
+	}
+	if js.CopyrightText != NOASSERTION {
+		s.CopyrightText = js.CopyrightText
+	}
+	return s
+}
+
+// partyFromSPDXJSON returns the person and organization in a supplier
+// or originator field
+func partyFromSPDXJSON(party string) (person, organization string) {
+	switch {
+	case strings.HasPrefix(party, "Person: "):
+		return strings.TrimPrefix(party, "Person: "), ""
+	case strings.HasPrefix(party, "Organization: "):
+		return "", strings.TrimPrefix(party, "Organization: ")
+	case party == NOASSERTION:
+		return "", NOASSERTION
+	}
+	return "", ""
+}
+
+func checksumsFromSPDXJSON(list []spdxJSONChecksum) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	checksums := map[string]string{}
+	for _, c := range list {
+		checksums[c.Algorithm] = c.Value
+	}
+	return normalizeChecksums(checksums)
+}
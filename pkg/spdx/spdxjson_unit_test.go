@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSPDXJSONRoundTrip(t *testing.T) {
+	root := testPackageWithFiles(t, "MIT", "Apache-2.0")
+	root.Version = "1.0"
+	root.LicenseDeclared = "MIT"
+	root.Supplier.Organization = "Kubernetes"
+	root.AddPackageURL("pkg:generic/test-package@1.0")
+	sub := NewPackage()
+	sub.Name = "sub"
+	sub.SetLicenseConcluded(NONE)
+	dep := NewPackage()
+	dep.Name = "dep"
+	dep.Scope = ScopeBuild
+	require.Nil(t, root.AddPackage(sub))
+	require.Nil(t, sub.AddDependency(dep))
+
+	doc := NewDocument()
+	doc.Name = "round-trip"
+	doc.Namespace = "https://example.com/round-trip"
+	doc.Created = time.Date(2021, 7, 2, 10, 30, 0, 0, time.UTC)
+	require.Nil(t, doc.AddPackage(root))
+
+	data, err := doc.RenderJSON()
+	require.Nil(t, err)
+	require.Contains(t, string(data), `"created": "2021-07-02T10:30:00Z"`)
+
+	parsed, err := ParseJSON(bytes.NewReader(data))
+	require.Nil(t, err)
+	require.Equal(t, doc.Name, parsed.Name)
+	require.Equal(t, doc.Created, parsed.Created)
+	require.Equal(t, doc.Creator.Person, parsed.Creator.Person)
+	require.Equal(t, doc.Creator.Tool, parsed.Creator.Tool)
+
+	// The package tree is rebuilt from the relationships
+	require.Len(t, parsed.Packages, 1)
+	parsedRoot := parsed.Packages[root.ID]
+	require.NotNil(t, parsedRoot)
+	require.Equal(t, "1.0", parsedRoot.Version)
+	require.Equal(t, "Kubernetes", parsedRoot.Supplier.Organization)
+	require.Equal(t, root.ExternalRefs, parsedRoot.ExternalRefs)
+	require.Len(t, parsedRoot.Files, 2)
+	for id, f := range root.Files {
+		require.Equal(t, f.Checksum, parsedRoot.Files[id].Checksum)
+		require.Equal(t, f.LicenseInfoInFile, parsedRoot.Files[id].LicenseInfoInFile)
+	}
+	parsedSub := parsedRoot.Packages[sub.ID]
+	require.NotNil(t, parsedSub)
+	require.Equal(t, NONE, parsedSub.LicenseConcluded)
+	require.Empty(t, parsedSub.LicenseDeclared)
+	require.NotNil(t, parsedSub.Dependencies[dep.ID])
+	require.Equal(t, ScopeBuild, parsedSub.Dependencies[dep.ID].Scope)
+
+	// Rendering the parsed document produces the same output
+	again, err := parsed.RenderJSON()
+	require.Nil(t, err)
+	require.JSONEq(t, string(data), string(again))
+}
+
+func TestSPDXJSONFileRelationships(t *testing.T) {
+	pkg := testPackageWithFiles(t, "MIT", "Apache-2.0")
+	ids := sortedFileIDs(pkg.Files)
+	src := NewFile()
+	src.ID = "SPDXRef-File-source"
+	src.Name = "main.go"
+	require.Nil(t, pkg.Files[ids[0]].MarkGeneratedFrom(src))
+
+	doc := NewDocument()
+	doc.Name = "file-relationships"
+	require.Nil(t, doc.AddPackage(pkg))
+	data, err := doc.RenderJSON()
+	require.Nil(t, err)
+
+	parsed := &spdxJSONDocument{}
+	require.Nil(t, json.Unmarshal(data, parsed))
+	contains := []spdxJSONRelationship{}
+	for _, r := range parsed.Relationships {
+		if r.Type == RelationshipContains {
+			contains = append(contains, r)
+		}
+	}
+	require.Equal(t, []spdxJSONRelationship{
+		{pkg.ID, RelationshipContains, ids[0]},
+		{pkg.ID, RelationshipContains, ids[1]},
+	}, contains)
+	require.Contains(t, parsed.Relationships, spdxJSONRelationship{ids[0], RelationshipGeneratedFrom, src.ID})
+	require.Equal(t, ids, parsed.Packages[0].HasFiles)
+
+	// The tag-value document lists the same relationships, in the same order
+	tagValue, err := doc.Render()
+	require.Nil(t, err)
+	rendered := []string{}
+	for _, line := range strings.Split(tagValue, "\n") {
+		if strings.HasPrefix(line, "Relationship: ") {
+			rendered = append(rendered, line)
+		}
+	}
+	expected := []string{}
+	for _, r := range parsed.Relationships {
+		expected = append(expected, fmt.Sprintf("Relationship: %s %s %s", r.Element, r.Type, r.Related))
+	}
+	require.Equal(t, expected, rendered)
+}
+
+func TestParseJSONMinimal(t *testing.T) {
+	// Documents without relationships describe their top level packages
+	doc, err := ParseJSON(strings.NewReader(`{
+		"spdxVersion": "SPDX-2.3",
+		"SPDXID": "SPDXRef-DOCUMENT",
+		"name": "minimal",
+		"packages": [{
+			"name": "pkg",
+			"SPDXID": "SPDXRef-Package-pkg",
+			"downloadLocation": "NOASSERTION",
+			"filesAnalyzed": false,
+			"licenseConcluded": "NOASSERTION",
+			"copyrightText": "NOASSERTION"
+		}]
+	}`))
+	require.Nil(t, err)
+	require.Len(t, doc.Packages, 1)
+	pkg := doc.Packages["SPDXRef-Package-pkg"]
+	require.False(t, pkg.FilesAnalyzed)
+	require.Empty(t, pkg.LicenseConcluded)
+	require.Empty(t, pkg.CopyrightText)
+
+	_, err = ParseJSON(strings.NewReader("not json"))
+	require.NotNil(t, err)
+}
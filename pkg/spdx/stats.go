@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+// Stats are the numbers of elements found in a tree of packages
+type Stats struct {
+	PackageCount   int   // Number of unique packages, including the root
+	FileCount      int   // Number of files in all the packages
+	TotalBytes     int64 // Sum of the sizes of all files, when known
+	MaxDepth       int   // Number of levels in the tree, a package alone has a depth of 1
+	UniqueLicenses int   // Number of distinct license tags in packages and files
+}
+
+// Stats walks the package tree and returns counts of its elements.
+// Packages reachable through more than one path are counted once,
+// at the level of the shortest path to them.
+func (p *Package) Stats() Stats {
+	stats := Stats{}
+	licenses := map[string]struct{}{}
+	addLicense := func(tag string) {
+		if tag != "" && tag != NONE && tag != NOASSERTION {
+			licenses[tag] = struct{}{}
+		}
+	}
+
+	seen := map[string]struct{}{p.ID: {}}
+	level := []*Package{p}
+	for len(level) > 0 {
+		stats.MaxDepth++
+		next := []*Package{}
+		for _, pkg := range level {
+			pkg.RLock()
+			stats.PackageCount++
+			stats.FileCount += len(pkg.Files)
+			addLicense(pkg.LicenseConcluded)
+			addLicense(pkg.LicenseDeclared)
+			for _, f := range pkg.Files {
+				stats.TotalBytes += f.Size
+				addLicense(f.LicenseConcluded)
+				for _, l := range f.LicenseInfoInFile {
+					addLicense(l)
+				}
+			}
+			for _, list := range []map[string]*Package{pkg.Packages, pkg.Dependencies} {
+				for _, id := range sortedPackageIDs(list) {
+					if _, ok := seen[id]; ok {
+						continue
+					}
+					seen[id] = struct{}{}
+					next = append(next, list[id])
+				}
+			}
+			pkg.RUnlock()
+		}
+		level = next
+	}
+	stats.UniqueLicenses = len(licenses)
+	return stats
+}
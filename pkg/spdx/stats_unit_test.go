@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	// root (2 files) -> a (1 file) -> c
+	//                -> b          -> c
+	root := testPackageWithFiles(t, "MIT", "Apache-2.0")
+	a := testPackageWithFiles(t, "MIT")
+	a.ID = "SPDXRef-Package-a"
+	a.Name = "a"
+	b := NewPackage()
+	b.Name = "b"
+	b.LicenseDeclared = "BSD-3-Clause"
+	c := NewPackage()
+	c.Name = "c"
+	c.LicenseConcluded = NOASSERTION
+
+	require.Nil(t, root.AddPackage(a))
+	require.Nil(t, root.AddDependency(b))
+	require.Nil(t, a.AddDependency(c))
+	require.Nil(t, b.AddDependency(c))
+	// A cycle back to the root must not be counted
+	require.Nil(t, c.AddDependency(root))
+
+	require.Equal(t, Stats{
+		PackageCount:   4,
+		FileCount:      3,
+		MaxDepth:       3,
+		UniqueLicenses: 3,
+	}, root.Stats())
+
+	require.Equal(t, Stats{PackageCount: 1, MaxDepth: 1}, NewPackage().Stats())
+}
@@ -0,0 +1,554 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxTagValueLine is the longest line accepted when parsing tag-value
+// documents, longer lines are reported as errors
+const maxTagValueLine = 1024 * 1024
+
+// Parse reads a package fragment in the SPDX tag-value format, such as
+// the output of Package.Render, and returns the first package in it.
+// The trees of packages and files are rebuilt from the relationships.
+func Parse(r io.Reader) (*Package, error) {
+	p, err := parseTagValue(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.order) == 0 {
+		return nil, errors.New("no packages found in SPDX tag-value input")
+	}
+	return p.order[0], nil
+}
+
+// ParseTagValue reads an SPDX document in the tag-value format. The
+// trees of packages are rebuilt from the relationships in the document.
+func ParseTagValue(r io.Reader) (*Document, error) {
+	p, err := parseTagValue(r)
+	if err != nil {
+		return nil, err
+	}
+	return p.doc, nil
+}
+
+// ParseStream reads an SPDX tag-value document without building the
+// trees of the document. onPackage is called with each package once it
+// is complete, when the next package starts or the input ends.
+// onRelationship is called with each relationship as soon as its line
+// is read. Its element argument is the SPDX ID of the element on the
+// left of the relationship, since the Relationship only holds the peer.
+//
+// Callbacks follow the order of the document, so the relationships
+// rendered in the body of a package, like the CONTAINS edges of its
+// files, are delivered before the package itself. Relationships may
+// also refer to packages delivered later or never. Packages hold the
+// files listed after them that they contain, files outside of packages
+// are skipped. The peers of relationships are new packages or files
+// holding only their ID, unless they are files of the package being
+// read. Processing stops at the first error returned by a callback.
+func ParseStream(
+	r io.Reader, onPackage func(*Package) error, onRelationship func(element string, r Relationship) error,
+) error {
+	p := newTagValueParser()
+	p.stream = &tagValueStream{
+		onPackage:      onPackage,
+		onRelationship: onRelationship,
+		ids:            map[string]bool{},
+	}
+	if err := p.scan(r); err != nil {
+		return err
+	}
+	if err := p.flushPackage(); err != nil {
+		return errors.Wrapf(err, "line %d", p.line)
+	}
+	return nil
+}
+
+// tagValueStream holds the callbacks of ParseStream and the IDs of the
+// elements already passed to them, true for files
+type tagValueStream struct {
+	onPackage      func(*Package) error
+	onRelationship func(element string, r Relationship) error
+	ids            map[string]bool
+}
+
+// flushPackage passes the current package, with the files and snippets
+// read after it, to the stream callback and drops the elements read
+func (p *tagValueParser) flushPackage() error {
+	pkg := p.pkg
+	if pkg == nil {
+		return nil
+	}
+	if pkg.ID == "" {
+		return errors.Errorf("package %s has no SPDXID", pkg.Name)
+	}
+	for _, s := range p.snippets {
+		if f, ok := pkg.Files[s.SnippetFromFile]; ok {
+			f.Snippets = append(f.Snippets, s)
+		}
+	}
+	for id := range p.packages {
+		p.stream.ids[id] = false
+	}
+	for id := range p.files {
+		p.stream.ids[id] = true
+	}
+	p.pkg, p.file, p.snippet, p.snippets = nil, nil, nil, nil
+	p.packages = map[string]*Package{}
+	p.files = map[string]*File{}
+	if err := p.stream.onPackage(pkg); err != nil {
+		return errors.Wrapf(err, "processing package %s", pkg.ID)
+	}
+	return nil
+}
+
+// streamRelationship passes a relationship to the stream callback. The
+// files contained in the current package are added to it.
+func (p *tagValueParser) streamRelationship(element, relType, related string) error {
+	r := Relationship{Type: relType}
+	if f, ok := p.files[related]; ok {
+		r.File = f
+		if p.pkg != nil && element == p.pkg.ID && relType == RelationshipContains {
+			if err := p.pkg.AddFile(f); err != nil {
+				return errors.Wrapf(err, "adding file %s to package", related)
+			}
+		}
+	} else if pkg, ok := p.packages[related]; ok {
+		r.Package = pkg
+	} else if p.stream.ids[related] {
+		r.File = NewFile()
+		r.File.ID = related
+	} else {
+		r.Package = NewPackage()
+		r.Package.ID = related
+	}
+	if err := p.stream.onRelationship(element, r); err != nil {
+		return errors.Wrapf(err, "processing %s relationship from %s to %s", relType, element, related)
+	}
+	return nil
+}
+
+// tagValueParser keeps the elements read from a tag-value document.
+// Tags apply to the last element started by a PackageName or FileName
+// tag, or to the document before any of them.
+type tagValueParser struct {
+	doc           *Document
+	packages      map[string]*Package
+	files         map[string]*File
+	relationships []spdxJSONRelationship
+	order         []*Package      // Packages in the order they were read
+	pkg           *Package        // Current package
+	file          *File           // Current file, nil if the current element is a package
+	snippet       *Snippet        // Current snippet, nil if the current element is not one
+	snippets      []*Snippet      // Snippets in the order they were read
+	stream        *tagValueStream // Callbacks of ParseStream, nil when building the trees
+	line          int
+}
+
+func parseTagValue(r io.Reader) (*tagValueParser, error) {
+	p := newTagValueParser()
+	if err := p.scan(r); err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range p.order {
+		if pkg.ID == "" {
+			return nil, errors.Errorf("package %s has no SPDXID", pkg.Name)
+		}
+	}
+	for _, s := range p.snippets {
+		f, ok := p.files[s.SnippetFromFile]
+		if !ok {
+			return nil, errors.Errorf("snippet %s is from unknown file %q", s.ID, s.SnippetFromFile)
+		}
+		f.Snippets = append(f.Snippets, s)
+	}
+	if err := p.doc.linkParsedElements(p.packages, p.files, p.relationships); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func newTagValueParser() *tagValueParser {
+	return &tagValueParser{
+		doc:      &Document{},
+		packages: map[string]*Package{},
+		files:    map[string]*File{},
+	}
+}
+
+// scan reads the tags of a tag-value document into the parser
+func (p *tagValueParser) scan(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxTagValueLine)
+	for scanner.Scan() {
+		p.line++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i <= 0 {
+			return errors.Errorf("line %d: expected a tag and a value separated by a colon", p.line)
+		}
+		tag, value := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		if strings.HasPrefix(value, "<text>") {
+			text, err := p.readText(scanner, tag, strings.TrimPrefix(value, "<text>"))
+			if err != nil {
+				return err
+			}
+			value = text
+		}
+		if err := p.parseTag(tag, value); err != nil {
+			return errors.Wrapf(err, "line %d", p.line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrapf(err, "reading SPDX tag-value input after line %d", p.line)
+	}
+	return nil
+}
+
+// readText returns the value of a multiline <text> block, reading lines
+// from the scanner until the closing tag. The newline added before the
+// closing tag when rendering is removed.
+func (p *tagValueParser) readText(scanner *bufio.Scanner, tag, first string) (string, error) {
+	var sb strings.Builder
+	text := first
+	for {
+		if i := strings.Index(text, "</text>"); i >= 0 {
+			sb.WriteString(text[:i])
+			break
+		}
+		sb.WriteString(text)
+		if !scanner.Scan() {
+			return "", errors.Errorf("line %d: unterminated <text> value of %s", p.line, tag)
+		}
+		p.line++
+		if sb.Len()+len(scanner.Text()) > maxTagValueLine {
+			return "", errors.Errorf("line %d: value of %s is too long", p.line, tag)
+		}
+		sb.WriteString("\n")
+		text = strings.TrimRight(scanner.Text(), "\r")
+	}
+	value := strings.TrimSuffix(sb.String(), "\n")
+	return strings.ReplaceAll(value, "&lt;/text&gt;", "</text>"), nil
+}
+
+// parseTag sets the value of a tag in the current element
+func (p *tagValueParser) parseTag(tag, value string) error {
+	switch tag {
+	case "PackageName":
+		if p.stream != nil {
+			if err := p.flushPackage(); err != nil {
+				return err
+			}
+		}
+		p.pkg = NewPackage()
+		p.pkg.Name = value
+		p.file = nil
+		p.snippet = nil
+		if p.stream == nil {
+			p.order = append(p.order, p.pkg)
+		}
+		return nil
+	case "FileName":
+		p.file = NewFile()
+		p.file.Name = value
+		p.snippet = nil
+		return nil
+	case "SnippetSPDXID":
+		if value == "" {
+			return errors.New("empty SnippetSPDXID")
+		}
+		p.snippet = &Snippet{ID: value}
+		p.snippets = append(p.snippets, p.snippet)
+		return nil
+	case "SPDXID":
+		return p.setID(value)
+	case "Relationship":
+		fields := strings.Fields(value)
+		if len(fields) != 3 {
+			return errors.Errorf("invalid relationship %q", value)
+		}
+		if p.stream != nil {
+			return p.streamRelationship(fields[0], fields[1], fields[2])
+		}
+		p.relationships = append(p.relationships, spdxJSONRelationship{fields[0], fields[1], fields[2]})
+		return nil
+	}
+
+	switch {
+	case tag == "LicenseInfoInSnippet" || strings.HasPrefix(tag, "Snippet"):
+		if p.snippet == nil {
+			return errors.Errorf("%s tag found outside of a snippet", tag)
+		}
+		return p.parseSnippetTag(tag, value)
+	case tag == "FilesAnalyzed" || tag == "ExternalRef" || strings.HasPrefix(tag, "Package"):
+		if p.pkg == nil || p.file != nil {
+			return errors.Errorf("%s tag found outside of a package", tag)
+		}
+		return p.parsePackageTag(tag, value)
+	case tag == "LicenseConcluded" || tag == "LicenseInfoInFile" || strings.HasPrefix(tag, "File"):
+		if p.file == nil {
+			return errors.Errorf("%s tag found outside of a file", tag)
+		}
+		return p.parseFileTag(tag, value)
+	case p.pkg == nil && p.file == nil:
+		return p.parseDocumentTag(tag, value)
+	}
+	// Tags not supported yet are skipped
+	return nil
+}
+
+// setID sets the ID of the current element
+func (p *tagValueParser) setID(id string) error {
+	if id == "" {
+		return errors.New("empty SPDXID")
+	}
+	if _, ok := p.packages[id]; ok {
+		return errors.Errorf("duplicate SPDXID %s", id)
+	}
+	if _, ok := p.files[id]; ok {
+		return errors.Errorf("duplicate SPDXID %s", id)
+	}
+	if p.stream != nil {
+		if _, ok := p.stream.ids[id]; ok {
+			return errors.Errorf("duplicate SPDXID %s", id)
+		}
+	}
+	switch {
+	case p.file != nil:
+		if p.file.ID != "" {
+			return errors.Errorf("file %s already has SPDXID %s", p.file.Name, p.file.ID)
+		}
+		p.file.ID = id
+		p.files[id] = p.file
+	case p.pkg != nil:
+		if p.pkg.ID != "" {
+			return errors.Errorf("package %s already has SPDXID %s", p.pkg.Name, p.pkg.ID)
+		}
+		p.pkg.ID = id
+		p.packages[id] = p.pkg
+	default:
+		p.doc.ID = id
+	}
+	return nil
+}
+
+func (p *tagValueParser) parseDocumentTag(tag, value string) error {
+	d := p.doc
+	switch tag {
+	case "SPDXVersion":
+		d.Version = value
+	case "DataLicense":
+		d.DataLicense = value
+	case "DocumentName":
+		d.Name = value
+	case "DocumentNamespace":
+		d.Namespace = value
+	case "ExternalDocumentRef":
+		fields := strings.Fields(value)
+		if len(fields) != 4 {
+			return errors.Errorf("invalid external document reference %q", value)
+		}
+		algorithm, sum, err := parseTagValueChecksum(fields[2] + fields[3])
+		if err != nil {
+			return errors.Wrap(err, "parsing external document checksum")
+		}
+		d.ExternalDocumentRefs = append(d.ExternalDocumentRefs, ExternalDocumentRef{
+			ID:       fields[0],
+			URI:      fields[1],
+			Checksum: map[string]string{algorithm: sum},
+		})
+	case "Creator":
+		switch {
+		case strings.HasPrefix(value, "Person: "):
+			d.Creator.Person = strings.TrimPrefix(value, "Person: ")
+		case strings.HasPrefix(value, "Tool: "):
+			d.Creator.Tool = append(d.Creator.Tool, strings.TrimPrefix(value, "Tool: "))
+		}
+	case "Created":
+		created, err := time.Parse(spdxTimeFormat, value)
+		if err != nil {
+			return errors.Wrap(err, "parsing document creation date")
+		}
+		d.Created = created
+	case "CreatorComment":
+		d.CreatorComment = value
+	}
+	return nil
+}
+
+func (p *tagValueParser) parsePackageTag(tag, value string) error {
+	pkg := p.pkg
+	switch tag {
+	case "PackageVersion":
+		pkg.Version = value
+	case "PackageFileName":
+		pkg.FileName = value
+		pkg.ArchiveFileName = value
+	case "PackageSupplier":
+		pkg.Supplier.Person, pkg.Supplier.Organization = partyFromSPDXJSON(value)
+	case "PackageOriginator":
+		pkg.Originator.Person, pkg.Originator.Organization = partyFromSPDXJSON(value)
+	case "PackageDownloadLocation":
+		if value != NONE {
+			pkg.DownloadLocation = value
+		}
+	case "FilesAnalyzed":
+		switch value {
+		case "true":
+			pkg.FilesAnalyzed = true
+		case "false":
+			pkg.FilesAnalyzed = false
+		default:
+			return errors.Errorf("invalid FilesAnalyzed value %q", value)
+		}
+	case "PackageVerificationCode":
+		// The code may be followed by the list of excluded files
+		if fields := strings.Fields(value); len(fields) > 0 {
+			pkg.VerificationCode = fields[0]
+		}
+	case "PackageChecksum":
+		algorithm, sum, err := parseTagValueChecksum(value)
+		if err != nil {
+			return err
+		}
+		if pkg.Checksum == nil {
+			pkg.Checksum = map[string]string{}
+		}
+		pkg.Checksum[algorithm] = sum
+	case "PackageLicenseConcluded":
+		pkg.LicenseConcluded = normalizeLicenseSentinel(value)
+	case "PackageLicenseInfoFromFiles":
+		if value != NONE && value != NOASSERTION {
+			pkg.LicenseInfoFromFiles = append(pkg.LicenseInfoFromFiles, value)
+		}
+	case "PackageLicenseDeclared":
+		pkg.LicenseDeclared = normalizeLicenseSentinel(value)
+	case "PackageLicenseComments":
+		pkg.LicenseComments = value
+	case "PackageCopyrightText":
+		if value != NOASSERTION {
+			pkg.CopyrightText = value
+		}
+	case "PackageSourceInfo":
+		pkg.SourceInfo = value
+	case "PackageHomePage":
+		pkg.HomePage = value
+	case "PackageSummary":
+		pkg.Summary = value
+	case "PackageDescription":
+		pkg.Description = value
+	case "PackageComment":
+		pkg.Comment = value
+	case "PackageAttributionText":
+		pkg.AttributionText = append(pkg.AttributionText, value)
+	case "ExternalRef":
+		fields := strings.Fields(value)
+		if len(fields) != 3 {
+			return errors.Errorf("invalid external reference %q", value)
+		}
+		pkg.ExternalRefs = append(pkg.ExternalRefs, ExternalRef{
+			Category: fields[0], Type: fields[1], Locator: fields[2],
+		})
+	}
+	return nil
+}
+
+func (p *tagValueParser) parseFileTag(tag, value string) error {
+	f := p.file
+	switch tag {
+	case "FileType":
+		f.FileType = append(f.FileType, value)
+	case "FileChecksum":
+		algorithm, sum, err := parseTagValueChecksum(value)
+		if err != nil {
+			return err
+		}
+		if f.Checksum == nil {
+			f.Checksum = map[string]string{}
+		}
+		f.Checksum[algorithm] = sum
+	case "LicenseConcluded":
+		f.LicenseConcluded = normalizeLicenseSentinel(value)
+	case "LicenseInfoInFile":
+		if value != NOASSERTION {
+			f.LicenseInfoInFile = append(f.LicenseInfoInFile, value)
+		}
+	case "FileCopyrightText":
+		if value != NOASSERTION {
+			f.CopyrightText = value
+		}
+	case "FileComment":
+		f.setParsedComment(value)
+	case "FileNotice":
+		f.Notice = value
+	case "FileAttributionText":
+		f.AttributionText = append(f.AttributionText, value)
+	}
+	return nil
+}
+
+func (p *tagValueParser) parseSnippetTag(tag, value string) error {
+	s := p.snippet
+	switch tag {
+	case "SnippetFromFileSPDXID":
+		s.SnippetFromFile = value
+	case "SnippetByteRange", "SnippetLineRange":
+		r, err := parseSnippetRange(value)
+		if err != nil {
+			return err
+		}
+		if tag == "SnippetByteRange" {
+			s.ByteRange = r
+		} else {
+			s.LineRange = r
+		}
+	case "SnippetLicenseConcluded":
+		s.LicenseConcluded = normalizeLicenseSentinel(value)
+	case "LicenseInfoInSnippet":
+		if value != NOASSERTION {
+			s.LicenseInfoInSnippet = append(s.LicenseInfoInSnippet, value)
+		}
+	case "SnippetCopyrightText":
+		if value != NOASSERTION {
+			s.CopyrightText = value
+		}
+	case "SnippetComment":
+		s.Comment = value
+	}
+	return nil
+}
+
+// parseTagValueChecksum splits a checksum value such as "SHA1: <hex>"
+func parseTagValueChecksum(value string) (algorithm, sum string, err error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return "", "", errors.Errorf("invalid checksum %q", value)
+	}
+	return strings.TrimSpace(parts[0]), strings.ToLower(strings.TrimSpace(parts[1])), nil
+}
@@ -0,0 +1,47 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// FuzzParse checks that the tag-value parser returns either a package
+// or an error, without panicking, for arbitrary input. The seed corpus
+// is in testdata/fuzz/FuzzParse.
+func FuzzParse(f *testing.F) {
+	golden, err := os.ReadFile("testdata/document-spacing.spdx")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(golden)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		pkg, err := Parse(bytes.NewReader(data))
+		if (pkg == nil) == (err == nil) {
+			t.Fatalf("Parse returned package %v and error %v", pkg, err)
+		}
+		if pkg != nil {
+			// Parsed packages can be rendered, possibly with an error
+			_, _ = pkg.Render()
+		}
+	})
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	root := testPackageWithFiles(t, "MIT", "Apache-2.0")
+	root.Version = "1.0"
+	root.LicenseDeclared = "MIT"
+	root.CopyrightText = "Copyright The Kubernetes Authors\n</text> is escaped"
+	root.Supplier.Organization = "Kubernetes"
+	root.AddPackageURL("pkg:generic/test-package@1.0")
+	sub := NewPackage()
+	sub.ID = "SPDXRef-Package-sub"
+	sub.Name = "sub"
+	dep := NewPackage()
+	dep.ID = "SPDXRef-Package-dep"
+	dep.Name = "dep"
+	dep.Scope = ScopeBuild
+	require.Nil(t, root.AddPackage(sub))
+	require.Nil(t, sub.AddDependency(dep))
+
+	rendered, err := root.Render()
+	require.Nil(t, err)
+	parsed, err := Parse(strings.NewReader(rendered))
+	require.Nil(t, err)
+	require.Equal(t, root.ID, parsed.ID)
+	require.Equal(t, root.CopyrightText, parsed.CopyrightText)
+	require.Equal(t, root.ExternalRefs, parsed.ExternalRefs)
+	require.Len(t, parsed.Files, 2)
+	require.NotNil(t, parsed.Packages[sub.ID])
+	require.Equal(t, ScopeBuild, parsed.Packages[sub.ID].Dependencies[dep.ID].Scope)
+
+	again, err := parsed.Render()
+	require.Nil(t, err)
+	require.Equal(t, rendered, again)
+}
+
+func TestParseStream(t *testing.T) {
+	root := testPackageWithFiles(t, "MIT", "Apache-2.0")
+	sub := NewPackage()
+	sub.ID = "SPDXRef-Package-sub"
+	sub.Name = "sub"
+	dep := NewPackage()
+	dep.ID = "SPDXRef-Package-dep"
+	dep.Name = "dep"
+	dep.Version = "1.0"
+	dep.Scope = ScopeBuild
+	require.Nil(t, root.AddPackage(sub))
+	require.Nil(t, sub.AddDependency(dep))
+	doc := NewDocument()
+	doc.Name = "stream"
+	require.Nil(t, doc.AddPackage(root))
+	rendered, err := doc.Render()
+	require.Nil(t, err)
+
+	events := []string{}
+	packages := map[string]*Package{}
+	require.Nil(t, ParseStream(
+		strings.NewReader(rendered),
+		func(pkg *Package) error {
+			events = append(events, "package "+pkg.ID)
+			packages[pkg.ID] = pkg
+			return nil
+		},
+		func(element string, r Relationship) error {
+			events = append(events, element+" "+r.Type+" "+r.peerID())
+			return nil
+		},
+	))
+	file0, file1 := sortedFileIDs(root.Files)[0], sortedFileIDs(root.Files)[1]
+	require.Equal(t, []string{
+		"SPDXRef-Package-test-package CONTAINS " + file0,
+		"SPDXRef-Package-test-package CONTAINS " + file1,
+		"package SPDXRef-Package-test-package",
+		"package SPDXRef-Package-sub",
+		"SPDXRef-Package-dep BUILD_DEPENDENCY_OF SPDXRef-Package-sub",
+		"SPDXRef-Package-test-package CONTAINS SPDXRef-Package-sub",
+		"SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-test-package",
+		"package SPDXRef-Package-dep",
+	}, events)
+
+	// Packages hold their files but not the other packages
+	require.Len(t, packages[root.ID].Files, 2)
+	require.Equal(t, root.Files[file0].Checksum, packages[root.ID].Files[file0].Checksum)
+	require.Empty(t, packages[root.ID].Packages)
+	require.Equal(t, "1.0", packages[dep.ID].Version)
+
+	// Errors of the callbacks stop the parser
+	calls := 0
+	err = ParseStream(
+		strings.NewReader(rendered),
+		func(pkg *Package) error {
+			calls++
+			return errors.New("stop")
+		},
+		func(string, Relationship) error { return nil },
+	)
+	require.NotNil(t, err)
+	require.Equal(t, 1, calls)
+}
+
+func TestParseTagValueDocument(t *testing.T) {
+	f, err := os.Open("testdata/document-spacing.spdx")
+	require.Nil(t, err)
+	defer f.Close()
+	doc, err := ParseTagValue(f)
+	require.Nil(t, err)
+	require.Equal(t, "spacing", doc.Name)
+	require.Equal(t, "2021-03-03T10:30:00Z", doc.Created.Format(spdxTimeFormat))
+	require.Len(t, doc.Files, 1)
+	require.Len(t, doc.Packages, 1)
+
+	expected, err := os.ReadFile("testdata/document-spacing.spdx")
+	require.Nil(t, err)
+	out, err := doc.Render()
+	require.Nil(t, err)
+	require.Equal(t, string(expected), out)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"PackageName test\n",
+		"PackageName: test\nPackageCopyrightText: <text>unterminated\n",
+		"PackageName: test\nSPDXID: SPDXRef-a\nFilesAnalyzed: maybe\n",
+		"PackageName: test\nPackageChecksum: SHA1\n",
+		"PackageName: test\n",
+		"PackageName: a\nSPDXID: SPDXRef-a\nPackageName: b\nSPDXID: SPDXRef-a\n",
+		"FileName: a\nPackageVersion: 1.0\n",
+		"PackageName: a\nSPDXID: SPDXRef-a\nRelationship: SPDXRef-a CONTAINS\n",
+		"PackageName: " + strings.Repeat("a", maxTagValueLine+1) + "\n",
+	} {
+		_, err := Parse(strings.NewReader(input))
+		require.NotNil(t, err, input)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are the helper functions available in the SPDX templates
+var templateFuncs = template.FuncMap{
+	"escapeText":         escapeText,
+	"textField":          textField,
+	"party":              spdxParty,
+	"checksumAlgorithms": sortedChecksumAlgorithms,
+}
+
+// escapeText escapes a string so that it can be enclosed in a <text>
+// block without terminating it early
+func escapeText(s string) string {
+	return strings.ReplaceAll(s, "</text>", "&lt;/text&gt;")
+}
+
+// textField renders an optional tag with its value in a <text> block,
+// including the trailing newline. If value is empty it returns an empty
+// string, so templates can call it with a trimming action ({{ ... -}})
+// to avoid leaving blank lines behind.
+func textField(tag, value string) string {
+	if value == "" {
+		return ""
+	}
+	return tag + ": <text>" + escapeText(value) + "\n</text>\n"
+}
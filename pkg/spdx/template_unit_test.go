@@ -0,0 +1,32 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextField(t *testing.T) {
+	require.Equal(t, "", textField("PackageComment", ""))
+	require.Equal(t, "PackageComment: <text>A comment\n</text>\n", textField("PackageComment", "A comment"))
+	require.Equal(
+		t, "PackageComment: <text>Ends in &lt;/text&gt; early\n</text>\n",
+		textField("PackageComment", "Ends in </text> early"),
+	)
+}
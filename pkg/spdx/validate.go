@@ -0,0 +1,285 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Validate checks the package and all the packages it contains or
+// depends on for data that would produce an invalid SPDX document
+func (p *Package) Validate() error {
+	seen := map[string]struct{}{}
+	var validate func(pkg *Package) error
+	validate = func(pkg *Package) error {
+		if _, ok := seen[pkg.ID]; ok {
+			return nil
+		}
+		seen[pkg.ID] = struct{}{}
+
+		pkg.RLock()
+		defer pkg.RUnlock()
+		if err := pkg.validate(); err != nil {
+			return errors.Wrapf(err, "validating package %s", pkg.ID)
+		}
+		for _, list := range []map[string]*Package{pkg.Packages, pkg.Dependencies} {
+			for _, id := range sortedPackageIDs(list) {
+				if err := validate(list[id]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return validate(p)
+}
+
+// Validate checks the packages of the document and the relationships
+// between the elements it renders. Every relationship peer must be an
+// element of the document or of one of its external documents, the
+// dangling edges found are all listed in the error.
+func (d *Document) Validate() error {
+	if err := d.checkExternalDocumentRefs(); err != nil {
+		return err
+	}
+	for _, id := range sortedPackageIDs(d.Packages) {
+		if err := d.Packages[id].Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Collect the elements and relationships the same way they are
+	// rendered, peer files are not rendered with their relationships
+	state := newRenderState()
+	c := &elementCollector{ids: map[string]struct{}{d.ID: {}}}
+	for _, id := range sortedFileIDs(d.Files) {
+		if err := walkFile(state, d.Files[id], c); err != nil {
+			return errors.Wrapf(err, "walking file %s", id)
+		}
+	}
+	for _, id := range sortedPackageIDs(d.Packages) {
+		pkg := d.Packages[id]
+		state.maxDepth = pkg.maxDepth()
+		if err := pkg.walk(state, c); err != nil {
+			return errors.Wrapf(err, "walking package %s", id)
+		}
+	}
+
+	external := map[string]struct{}{}
+	for _, ref := range d.ExternalDocumentRefs {
+		external[ref.ID] = struct{}{}
+	}
+	dangling := []string{}
+	for _, rel := range c.relationships {
+		if _, ok := c.ids[rel.Related]; ok || rel.Related == NONE || rel.Related == NOASSERTION {
+			continue
+		}
+		if parts := strings.SplitN(rel.Related, ":", 2); len(parts) == 2 {
+			if _, ok := external[parts[0]]; ok {
+				continue
+			}
+		}
+		dangling = append(dangling, rel.Element+" "+rel.Type+" "+rel.Related)
+	}
+	if len(dangling) > 0 {
+		return errors.Errorf(
+			"relationships to elements not in the document: %s", strings.Join(dangling, ", "),
+		)
+	}
+	return nil
+}
+
+// elementCollector records the IDs of the elements and the
+// relationships of the visited package trees
+type elementCollector struct {
+	ids           map[string]struct{}
+	relationships []spdxJSONRelationship
+}
+
+func (c *elementCollector) visitPackage(p *Package, view *packageView) error {
+	c.ids[p.ID] = struct{}{}
+	return nil
+}
+
+func (c *elementCollector) visitFile(f *File) error {
+	c.ids[f.ID] = struct{}{}
+	for _, s := range f.Snippets {
+		c.ids[s.ID] = struct{}{}
+	}
+	return nil
+}
+
+func (c *elementCollector) visitRelationship(element, relType, related string) error {
+	c.relationships = append(c.relationships, spdxJSONRelationship{element, relType, related})
+	return nil
+}
+
+// DefaultStrictFields are the package fields ValidateStrict requires by
+// default. Supplier and Originator are satisfied by a person or an
+// organization.
+var DefaultStrictFields = []string{"LicenseConcluded", "LicenseDeclared", "CopyrightText", "Supplier"}
+
+// ValidateStrict checks that the package and all the packages it
+// contains or depends on set a concrete value in the StrictFields of the
+// package options. Unset fields, rendered as NOASSERTION, and fields
+// set to NONE or NOASSERTION are reported, one error per field, so the
+// list can be shown in full. It returns nil if all the fields are set.
+func (p *Package) ValidateStrict() []error {
+	fields := DefaultStrictFields
+	if p.Options() != nil && len(p.Options().StrictFields) > 0 {
+		fields = p.Options().StrictFields
+	}
+	var errs []error
+	seen := map[string]struct{}{}
+	var validate func(pkg *Package)
+	validate = func(pkg *Package) {
+		if _, ok := seen[pkg.ID]; ok {
+			return
+		}
+		seen[pkg.ID] = struct{}{}
+
+		pkg.RLock()
+		defer pkg.RUnlock()
+		for _, field := range fields {
+			value, ok := pkg.strictFieldValue(field)
+			if !ok {
+				errs = append(errs, errors.Errorf("package %s: unknown strict field %s", pkg.ID, field))
+				continue
+			}
+			if value == "" || value == NONE || value == NOASSERTION {
+				errs = append(errs, errors.Errorf("package %s: %s has no concrete value", pkg.ID, field))
+			}
+		}
+		for _, list := range []map[string]*Package{pkg.Packages, pkg.Dependencies} {
+			for _, id := range sortedPackageIDs(list) {
+				validate(list[id])
+			}
+		}
+	}
+	validate(p)
+	return errs
+}
+
+// strictFieldValue returns the value of one of the fields checked by
+// ValidateStrict, or false if the field is not supported
+func (p *Package) strictFieldValue(field string) (string, bool) {
+	switch field {
+	case "Name":
+		return p.Name, true
+	case "Version":
+		return p.Version, true
+	case "DownloadLocation":
+		return p.DownloadLocation, true
+	case "HomePage":
+		return p.HomePage, true
+	case "LicenseConcluded":
+		return p.LicenseConcluded, true
+	case "LicenseDeclared":
+		return p.LicenseDeclared, true
+	case "CopyrightText":
+		return p.CopyrightText, true
+	case "Supplier":
+		return spdxParty(p.Supplier.Person, p.Supplier.Organization), true
+	case "Originator":
+		return spdxParty(p.Originator.Person, p.Originator.Organization), true
+	}
+	return "", false
+}
+
+// validateHomePage checks the home page of a package is an http(s) URL
+// or one of the SPDX sentinels
+func validateHomePage(homePage string) error {
+	if homePage == "" || homePage == NONE || homePage == NOASSERTION {
+		return nil
+	}
+	u, err := url.Parse(homePage)
+	if err != nil {
+		return errors.Wrapf(err, "parsing home page %q", homePage)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("home page %q is not an http or https URL", homePage)
+	}
+	return nil
+}
+
+// SetFilesAnalyzed sets whether the files of the package were analyzed.
+// It returns an error, leaving the package unchanged, if the value
+// contradicts the files of the package: analyzed packages must have
+// files and packages with files must have them analyzed.
+func (p *Package) SetFilesAnalyzed(analyzed bool) error {
+	p.Lock()
+	defer p.Unlock()
+	if err := p.checkFilesAnalyzed(analyzed); err != nil {
+		return err
+	}
+	p.FilesAnalyzed = analyzed
+	return nil
+}
+
+// checkFilesAnalyzed checks the FilesAnalyzed value against the files
+// of the package. The caller must hold the package lock.
+func (p *Package) checkFilesAnalyzed(analyzed bool) error {
+	hasFiles := len(p.Files) > 0 || p.FileProvider != nil
+	if analyzed && !hasFiles {
+		return errors.New("files were analyzed but package has no files")
+	}
+	if !analyzed && hasFiles {
+		if p.FileProvider != nil {
+			return errors.New("package has a file provider but FilesAnalyzed is false")
+		}
+		return errors.Errorf(
+			"package lists %d files but FilesAnalyzed is false", len(p.Files),
+		)
+	}
+	return nil
+}
+
+// validate checks the data of the package itself. The caller must
+// hold the package lock.
+func (p *Package) validate() error {
+	if err := p.checkFilesAnalyzed(p.FilesAnalyzed); err != nil {
+		return err
+	}
+	names := map[string]*File{}
+	for _, id := range sortedFileIDs(p.Files) {
+		f := p.Files[id]
+		if previous, ok := names[f.Name]; ok {
+			if err := p.duplicateFile(f.Name, previous, f); err != nil {
+				return err
+			}
+		}
+		names[f.Name] = f
+	}
+	if err := validateHomePage(p.HomePage); err != nil {
+		return err
+	}
+	for _, expr := range []string{p.LicenseConcluded, p.LicenseDeclared} {
+		if err := ValidateLicenseExpression(expr); err != nil {
+			return err
+		}
+		if normalized, changed := NormalizeLicense(expr); changed {
+			p.logger().Warnf(
+				"Package %s uses deprecated license identifiers in %q, consider using %q",
+				p.ID, expr, normalized,
+			)
+		}
+	}
+	return nil
+}
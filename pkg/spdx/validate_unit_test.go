@@ -0,0 +1,185 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFilesAnalyzed(t *testing.T) {
+	pkg := testPackageWithFiles(t, "MIT")
+	require.Nil(t, pkg.Validate())
+
+	// Files present but not analyzed
+	pkg.FilesAnalyzed = false
+	require.NotNil(t, pkg.Validate())
+	_, err := pkg.Render()
+	require.NotNil(t, err)
+
+	// Files analyzed but none present
+	empty := NewPackage()
+	empty.Name = "empty"
+	empty.FilesAnalyzed = true
+	require.NotNil(t, empty.Validate())
+	empty.FilesAnalyzed = false
+	require.Nil(t, empty.Validate())
+
+	// Errors in nested packages are reported
+	require.Nil(t, empty.AddPackage(pkg))
+	require.NotNil(t, empty.Validate())
+}
+
+func TestValidateHomePage(t *testing.T) {
+	pkg := NewPackage()
+	pkg.ID = "SPDXRef-Package-homepage"
+	pkg.Name = "homepage"
+	for _, homePage := range []string{"", NONE, NOASSERTION, "https://kubernetes.io", "http://example.com/hello"} {
+		pkg.HomePage = homePage
+		require.Nil(t, pkg.Validate(), homePage)
+	}
+	out, err := pkg.Render()
+	require.Nil(t, err)
+	require.Contains(t, out, "PackageHomePage: http://example.com/hello\n")
+
+	for _, homePage := range []string{"kubernetes", "ftp://example.com", "https://", "http://exa mple.com"} {
+		pkg.HomePage = homePage
+		require.NotNil(t, pkg.Validate(), homePage)
+	}
+}
+
+func TestValidateStrict(t *testing.T) {
+	pkg := NewPackage()
+	pkg.ID = "SPDXRef-Package-strict"
+	pkg.Name = "strict"
+	pkg.LicenseDeclared = "Apache-2.0"
+	pkg.CopyrightText = "Copyright 2021 The Kubernetes Authors"
+	pkg.Supplier.Organization = "Kubernetes"
+	require.Nil(t, pkg.Validate())
+	errs := pkg.ValidateStrict()
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), "LicenseConcluded")
+
+	pkg.SetLicenseConcluded("Apache-2.0")
+	require.Empty(t, pkg.ValidateStrict())
+
+	// The sentinels are not concrete values, dependencies are checked
+	dep := NewPackage()
+	dep.ID = "SPDXRef-Package-dep"
+	dep.Name = "dep"
+	dep.SetLicenseConcluded(NONE)
+	dep.Supplier.Organization = NOASSERTION
+	require.Nil(t, pkg.AddDependency(dep))
+	pkg.Options().StrictFields = []string{"LicenseConcluded", "Supplier", "Checksum"}
+	errs = pkg.ValidateStrict()
+	require.Len(t, errs, 4)
+	require.Contains(t, errs[0].Error(), "unknown strict field Checksum")
+	require.Contains(t, errs[1].Error(), "package SPDXRef-Package-dep: LicenseConcluded")
+	require.Contains(t, errs[2].Error(), "package SPDXRef-Package-dep: Supplier")
+}
+
+func TestSetFilesAnalyzed(t *testing.T) {
+	empty := NewPackage()
+	empty.Name = "empty"
+	require.NotNil(t, empty.SetFilesAnalyzed(true))
+	require.False(t, empty.FilesAnalyzed)
+	require.Nil(t, empty.SetFilesAnalyzed(false))
+
+	pkg := testPackageWithFiles(t, "MIT")
+	require.NotNil(t, pkg.SetFilesAnalyzed(false))
+	require.True(t, pkg.FilesAnalyzed)
+	require.Nil(t, pkg.SetFilesAnalyzed(true))
+}
+
+func TestValidateDocumentRelationships(t *testing.T) {
+	pkg := NewPackage()
+	pkg.ID = "SPDXRef-Package-app"
+	pkg.Name = "app"
+	lib := NewPackage()
+	lib.ID = "SPDXRef-Package-lib"
+	lib.Name = "lib"
+	require.Nil(t, pkg.MarkGeneratedFrom(lib))
+	doc := NewDocument()
+	doc.Name = "app"
+	require.Nil(t, doc.AddPackage(pkg))
+	require.Nil(t, doc.Validate())
+
+	// Peer files are not rendered, they must be in the document
+	missing := NewFile()
+	missing.ID = "SPDXRef-File-missing"
+	missing.Name = "missing.go"
+	require.Nil(t, pkg.AddRelationship(&Relationship{Type: RelationshipGeneratedFrom, File: missing}))
+	err := doc.Validate()
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "SPDXRef-Package-app GENERATED_FROM SPDXRef-File-missing")
+
+	require.Nil(t, doc.AddFile(missing))
+	require.Nil(t, doc.Validate())
+}
+
+func TestDuplicateFileNames(t *testing.T) {
+	newFile := func(sha1 string) *File {
+		f := NewFile()
+		f.Name = "main.go"
+		f.Checksum = map[string]string{"SHA1": sha1}
+		return f
+	}
+
+	// Duplicates are only logged by default
+	pkg := testPackageWithFiles(t)
+	logger := &testLogger{}
+	pkg.Options().Logger = logger
+	require.Nil(t, pkg.AddFile(newFile("a")))
+	require.Nil(t, pkg.AddFile(newFile("b")))
+	require.Len(t, pkg.Files, 1)
+	require.Contains(
+		t, logger.messages,
+		"Package "+pkg.ID+" has more than one file named main.go with different content",
+	)
+
+	// Strict packages reject them when adding files...
+	pkg = testPackageWithFiles(t)
+	pkg.Options().StrictFileNames = true
+	require.Nil(t, pkg.AddFile(newFile("a")))
+	require.Nil(t, pkg.AddFile(newFile("a")))
+	err := pkg.AddFile(newFile("b"))
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "more than one file named main.go")
+	err = pkg.AddFiles([]*File{newFile("c"), newFile("d")})
+	require.NotNil(t, err)
+
+	// ... and when validating files added with their own IDs
+	f := newFile("b")
+	f.ID = "SPDXRef-File-main-b"
+	pkg.Files[f.ID] = f
+	err = pkg.Validate()
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "more than one file named main.go")
+}
+
+func TestValidateDeprecatedLicenseWarning(t *testing.T) {
+	pkg := NewPackage()
+	pkg.ID = "SPDXRef-Package-app"
+	pkg.Name = "app"
+	pkg.LicenseDeclared = "GPL-2.0-with-classpath-exception"
+	logger := &testLogger{}
+	pkg.Options().Logger = logger
+	require.Nil(t, pkg.Validate())
+	require.Contains(t, logger.messages, `Package SPDXRef-Package-app uses deprecated license identifiers in `+
+		`"GPL-2.0-with-classpath-exception", consider using "GPL-2.0-only WITH Classpath-exception-2.0"`)
+}
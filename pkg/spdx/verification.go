@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// fileHashes keeps the sha1 checksums of the files of a package in a
+// sorted list, updated as files are added and removed, so computing the
+// verification code does not require collecting and sorting them on
+// every render. Files added since the list was last used are sorted
+// in when it is needed, so adding many files remains cheap.
+type fileHashes struct {
+	byID   map[string]string // Checksum of each file when it was added
+	sorted []string          // Checksums of all files
+	dirty  bool              // Checksums were appended to sorted out of order
+
+	// Renders read the list holding the package read lock, mu ensures
+	// only one of them sorts it
+	mu sync.Mutex
+}
+
+// add records the checksum of the file with id, replacing the previous
+// checksum of a file with the same id
+func (h *fileHashes) add(id, sha string) {
+	h.remove(id)
+	if h.byID == nil {
+		h.byID = map[string]string{}
+	}
+	h.byID[id] = sha
+	if n := len(h.sorted); n > 0 && h.sorted[n-1] > sha {
+		h.dirty = true
+	}
+	h.sorted = append(h.sorted, sha)
+}
+
+// remove forgets the checksum of the file with id
+func (h *fileHashes) remove(id string) {
+	sha, ok := h.byID[id]
+	if !ok {
+		return
+	}
+	delete(h.byID, id)
+	h.sort()
+	i := sort.SearchStrings(h.sorted, sha)
+	h.sorted = append(h.sorted[:i], h.sorted[i+1:]...)
+}
+
+// matches returns true if sha is the checksum recorded for the file
+// with id
+func (h *fileHashes) matches(id, sha string) bool {
+	recorded, ok := h.byID[id]
+	return ok && sha != "" && recorded == sha
+}
+
+// sort sorts the checksums appended out of order
+func (h *fileHashes) sort() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.dirty {
+		sort.Strings(h.sorted)
+		h.dirty = false
+	}
+}
+
+// verificationCode returns the sha1 of the sorted and concatenated
+// checksums
+func (h *fileHashes) verificationCode() (string, error) {
+	if len(h.sorted) == 0 {
+		return "", errors.New("unable to get package verification code, package has no files")
+	}
+	h.sort()
+	s := sha1.New()
+	for _, sha := range h.sorted {
+		if _, err := s.Write([]byte(sha)); err != nil {
+			return "", errors.Wrap(err, "getting sha1 verification of files")
+		}
+	}
+	return fmt.Sprintf("%x", s.Sum(nil)), nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncrementalVerificationCode(t *testing.T) {
+	files := []*File{}
+	for i := 0; i < 200; i++ {
+		f := NewFile()
+		f.Name = fmt.Sprintf("file%03d.txt", i)
+		// Some files share their content
+		f.Checksum = map[string]string{"SHA1": fmt.Sprintf("%040x", i%150)}
+		files = append(files, f)
+	}
+
+	for seed := int64(0); seed < 20; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		pkg := NewPackage()
+		pkg.ID = "SPDXRef-Package-incremental"
+		pkg.Name = "incremental"
+		pkg.FilesAnalyzed = true
+
+		// Add the files in a random order, one by one or in batches
+		order := r.Perm(len(files))
+		for len(order) > 0 {
+			n := 1 + r.Intn(10)
+			if n > len(order) {
+				n = len(order)
+			}
+			batch := []*File{}
+			for _, i := range order[:n] {
+				batch = append(batch, &File{Name: files[i].Name, Checksum: files[i].Checksum})
+			}
+			require.Nil(t, pkg.AddFiles(batch))
+			order = order[n:]
+
+			// Remove some of the files already added
+			if r.Intn(3) == 0 {
+				ids := sortedFileIDs(pkg.Files)
+				require.Nil(t, pkg.RemoveFile(ids[r.Intn(len(ids))]))
+			}
+
+			expected, err := VerificationCode(pkg.Files, nil)
+			require.Nil(t, err)
+			code, err := pkg.hashes.verificationCode()
+			require.Nil(t, err)
+			require.Equal(t, expected, code, "seed %d", seed)
+			view, err := pkg.view(time.Time{})
+			require.Nil(t, err)
+			require.Equal(t, expected, view.VerificationCode)
+		}
+	}
+
+	pkg := testPackageWithFiles(t, "MIT", "Apache-2.0")
+	require.NotNil(t, pkg.RemoveFile("SPDXRef-File-missing"))
+
+	// Files modified after being added are not missed
+	for _, f := range pkg.Files {
+		f.Checksum["SHA1"] = fmt.Sprintf("%040x", 99)
+		break
+	}
+	expected, err := VerificationCode(pkg.Files, nil)
+	require.Nil(t, err)
+	view, err := pkg.view(time.Time{})
+	require.Nil(t, err)
+	require.Equal(t, expected, view.VerificationCode)
+
+	// Concurrent renders can sort checksums added out of order
+	pkg = testPackageWithFiles(t)
+	for i := 3; i > 0; i-- {
+		require.Nil(t, pkg.AddFile(&File{Name: files[i].Name, Checksum: files[i].Checksum}))
+	}
+	require.True(t, pkg.hashes.dirty)
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := pkg.Render()
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.Nil(t, err)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"path/filepath"
+	"regexp"
+)
+
+// defaultVersionPatterns extract the version from source file names
+// like foo-1.2.3.tar.gz, foo_v1.2.zip or foo-1.0.0-rc.1.tgz
+var defaultVersionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`[-_]v?(\d+(?:\.\d+)+(?:-[0-9A-Za-z]+(?:\.\d+)*)?)(?:\.[A-Za-z][A-Za-z0-9]*)*$`),
+}
+
+// SetVersionFromSource sets the package version from the name of its
+// source file. The first capture group of the first matching pattern
+// in the VersionPatterns option is used, or of the default patterns if
+// none are set. If no pattern matches, Version is not modified and
+// false is returned.
+func (p *Package) SetVersionFromSource() bool {
+	name := p.ArchiveFileName
+	if name == "" {
+		name = p.FileName
+	}
+	if name == "" {
+		name = p.SourceFile
+	}
+	if name == "" {
+		return false
+	}
+	name = filepath.Base(name)
+
+	patterns := defaultVersionPatterns
+	if p.Options() != nil && p.Options().VersionPatterns != nil {
+		patterns = p.Options().VersionPatterns
+	}
+	for _, re := range patterns {
+		if m := re.FindStringSubmatch(name); len(m) > 1 && m[1] != "" {
+			p.Version = m[1]
+			return true
+		}
+	}
+	return false
+}
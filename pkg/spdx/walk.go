@@ -0,0 +1,187 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdx
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// treeVisitor receives the elements of the package trees traversed by
+// walk, in the order they appear in a rendered document. The tag-value
+// and JSON renderers are both built on it, so they list the same
+// elements and relationships.
+type treeVisitor interface {
+	visitPackage(p *Package, view *packageView) error
+	visitFile(f *File) error
+	visitRelationship(element, relType, related string) error
+}
+
+// walk visits the package and the elements reachable from it, skipping
+// those already visited in the state. Each file, subpackage, dependency
+// and relationship peer is visited before the relationship linking it
+// to the package.
+func (p *Package) walk(state *renderState, v treeVisitor) error {
+	if _, ok := state.rendered[p.ID]; ok {
+		return nil
+	}
+	state.rendered[p.ID] = struct{}{}
+
+	if len(state.path) > state.maxDepth {
+		return errors.Errorf(
+			"package tree exceeds the maximum depth of %d at %s (path: %s)",
+			state.maxDepth, p.ID, strings.Join(append(state.path, p.ID), " -> "),
+		)
+	}
+	state.path = append(state.path, p.ID)
+	defer func() { state.path = state.path[:len(state.path)-1] }()
+
+	p.RLock()
+	defer p.RUnlock()
+
+	if err := p.walkSelf(state, v); err != nil {
+		return err
+	}
+
+	// Visit the contained sub packages
+	for _, id := range sortedPackageIDs(p.Packages) {
+		pkg := p.Packages[id]
+		if err := pkg.walk(state, v); err != nil {
+			return errors.Wrap(err, "rendering pkg "+pkg.Name)
+		}
+		if err := v.visitRelationship(p.ID, RelationshipContains, pkg.ID); err != nil {
+			return err
+		}
+	}
+
+	// Visit the contained dependencies
+	for _, id := range sortedPackageIDs(p.Dependencies) {
+		pkg := p.Dependencies[id]
+		if err := pkg.walk(state, v); err != nil {
+			return errors.Wrap(err, "rendering pkg "+pkg.Name)
+		}
+		element, relType, related := dependencyRelationship(p, pkg)
+		if err := v.visitRelationship(element, relType, related); err != nil {
+			return err
+		}
+	}
+
+	// Visit any other relationships, including the peer packages not
+	// yet visited
+	for _, r := range p.Relationships {
+		if r.Package != nil {
+			if err := r.Package.walk(state, v); err != nil {
+				return errors.Wrap(err, "rendering pkg "+r.Package.Name)
+			}
+		}
+		related, err := r.peer(p.ID)
+		if err != nil {
+			return errors.Wrap(err, "rendering relationship")
+		}
+		if err := v.visitRelationship(p.ID, r.Type, related); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkSelf visits the package and the files it contains, but none of
+// its other relationships. The caller must hold the package lock.
+func (p *Package) walkSelf(state *renderState, v treeVisitor) error {
+	view, err := p.view(state.created)
+	if err != nil {
+		return err
+	}
+	if err := v.visitPackage(p, view); err != nil {
+		return err
+	}
+	return p.forEachFile(func(f *File) error {
+		if err := walkFile(state, f, v); err != nil {
+			return errors.Wrap(err, "rendering file "+f.Name)
+		}
+		return v.visitRelationship(p.ID, RelationshipContains, f.ID)
+	})
+}
+
+// walkFile visits a file followed by its relationships, unless it was
+// already visited in the state
+func walkFile(state *renderState, f *File, v treeVisitor) error {
+	if f.ID != "" {
+		if _, ok := state.rendered[f.ID]; ok {
+			return nil
+		}
+		state.rendered[f.ID] = struct{}{}
+	}
+	if err := v.visitFile(f); err != nil {
+		return err
+	}
+	for _, r := range f.Relationships {
+		related, err := r.peer(f.ID)
+		if err != nil {
+			return errors.Wrap(err, "rendering file relationship")
+		}
+		if err := v.visitRelationship(f.ID, r.Type, related); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagValueWriter writes the visited elements to the state output in
+// the SPDX tag-value format
+type tagValueWriter struct {
+	state *renderState
+}
+
+func (w *tagValueWriter) visitPackage(p *Package, view *packageView) error {
+	text := packageTemplate
+	if p.Options() != nil && p.Options().SpecTagOrder {
+		text = packageSpecOrderTemplate
+	}
+	tmpl, err := template.New("package").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return errors.Wrap(err, "parsing package template")
+	}
+
+	// Run the template to verify the output.
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, view); err != nil {
+		return errors.Wrap(err, "executing spdx package template")
+	}
+	w.state.write(buf.String())
+	return w.state.err
+}
+
+func (w *tagValueWriter) visitFile(f *File) error {
+	fileFragment, err := f.render()
+	if err != nil {
+		return err
+	}
+	w.state.write(fileFragment)
+	return w.state.err
+}
+
+func (w *tagValueWriter) visitRelationship(element, relType, related string) error {
+	w.state.write(w.state.relationship(
+		fmt.Sprintf("Relationship: %s %s %s\n\n", element, relType, related),
+	))
+	return w.state.err
+}